@@ -18,10 +18,12 @@ package main
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/probe"
 )
@@ -38,7 +40,15 @@ USAGE:
    mc {{.Name}} set PERMISSION TARGET [TARGET ...]
    mc {{.Name}} get TARGET [TARGET ...]
 
-   PERMISSION = private | readonly | public | authorized {{if .Description}}
+   PERMISSION = private | readonly | public | authorized
+   TARGET = BUCKET | OBJECT | PREFIX... | FILE | FOLDER...
+
+   Objects and files may be addressed individually, a trailing "..." applies the permission to
+   everything below the prefix or folder. On the filesystem permissions map to Unix permission
+   bits: private (0600), authorized (0640), readonly (0644) and public (0664), others are never
+   allowed to write. Folders are also searchable wherever they are readable and executable files
+   stay executable wherever they are readable. Setting permissions on a folder applies them to
+   the folder and everything below it. {{if .Description}}
 
 DESCRIPTION:
    {{.Description}}{{end}}
@@ -56,6 +66,23 @@ EXAMPLES:
 
    4. Get bucket permissions.
       $ mc {{.Name}} get https://s3.amazonaws.com/shared
+
+   5. Set a single object to "readonly" on Amazon S3 cloud storage.
+      $ mc {{.Name}} set readonly https://s3.amazonaws.com/shared/flyer.pdf
+
+   6. Set every object under a prefix to "private" on Minio cloud storage.
+      $ mc {{.Name}} set private https://play.minio.io:9000/photos/2015/...
+
+   7. Get permissions of every file and folder in a local folder.
+      $ mc {{.Name}} get /var/www/downloads/...
+
+   8. Set a local folder and its contents to "readonly".
+      $ mc {{.Name}} set readonly /var/www/downloads
+
+EXIT STATUS:
+   0 - Permissions of all targets were set.
+   1 - Fatal error, permissions of nothing or only part of the targets were set.
+   2 - Permissions of one or more objects below a PREFIX... or FOLDER... failed to set.
 `,
 }
 
@@ -65,10 +92,24 @@ type AccessMessage struct {
 	Status    string      `json:"status"`
 	Bucket    string      `json:"bucket"`
 	Perms     bucketPerms `json:"permission"`
+	Objects   int         `json:"objects,omitempty"`
+	Failed    int         `json:"failed,omitempty"`
 }
 
 // String colorized access message
 func (s AccessMessage) String() string {
+	if s.Operation == "set" && s.Failed > 0 {
+		return console.Colorize("AccessFailed", "Set access permission ‘"+string(s.Perms)+"’ updated for "+strconv.Itoa(s.Objects)+" objects in ‘"+s.Bucket+"’, failed for "+strconv.Itoa(s.Failed)+" objects")
+	}
+	if s.Operation == "set" && s.Objects > 0 {
+		if s.Status == "dry-run" {
+			return console.Colorize("Access", "Access permission ‘"+string(s.Perms)+"’ would be set for "+strconv.Itoa(s.Objects)+" objects in ‘"+s.Bucket+"’ (dry run)")
+		}
+		return console.Colorize("Access", "Set access permission ‘"+string(s.Perms)+"’ updated successfully for "+strconv.Itoa(s.Objects)+" objects in ‘"+s.Bucket+"’")
+	}
+	if s.Operation == "set" && s.Status == "dry-run" {
+		return console.Colorize("Access", "Access permission ‘"+string(s.Perms)+"’ would be set for ‘"+s.Bucket+"’ (dry run)")
+	}
 	if s.Operation == "set" {
 		return console.Colorize("Access", "Set access permission ‘"+string(s.Perms)+"’ updated successfully for ‘"+s.Bucket+"’")
 	}
@@ -118,11 +159,13 @@ func checkAccessSyntax(ctx *cli.Context) {
 
 func setAccessPalette(style string) {
 	console.SetCustomPalette(map[string]*color.Color{
-		"Access": color.New(color.FgGreen, color.Bold),
+		"Access":       color.New(color.FgGreen, color.Bold),
+		"AccessFailed": color.New(color.FgRed, color.Bold),
 	})
 	if style == "light" {
 		console.SetCustomPalette(map[string]*color.Color{
-			"Access": color.New(color.FgWhite, color.Bold),
+			"Access":       color.New(color.FgWhite, color.Bold),
+			"AccessFailed": color.New(color.FgWhite, color.Bold),
 		})
 	}
 	if style == "nocolor" {
@@ -138,24 +181,62 @@ func mainAccess(ctx *cli.Context) {
 
 	config := mustGetMcConfig()
 
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
 	switch ctx.Args().Get(0) {
 	case "set":
 		perms := bucketPerms(ctx.Args().Tail().Get(0))
+		var failedObjects int
 		for _, arg := range ctx.Args().Tail().Tail() {
 			targetURL := getAliasURL(arg, config.Aliases)
+			if isURLRecursive(targetURL) {
+				objects, failed, err := doSetAccessRecursive(targetURL, perms)
+				fatalIf(err.Trace(targetURL, string(perms)), "Unable to set access permission ‘"+string(perms)+"’ for ‘"+targetURL+"’.")
+				accessMessage := AccessMessage{
+					Operation: "set",
+					Status:    status,
+					Bucket:    stripRecursiveURL(targetURL),
+					Perms:     perms,
+					Objects:   objects,
+					Failed:    failed,
+				}
+				if failed > 0 {
+					accessMessage.Status = "partial"
+				}
+				Prints("%s\n", accessMessage)
+				failedObjects += failed
+				continue
+			}
 
 			fatalIf(doSetAccess(targetURL, perms).Trace(targetURL, string(perms)), "Unable to set access permission ‘"+string(perms)+"’ for ‘"+targetURL+"’.")
 
 			Prints("%s\n", AccessMessage{
 				Operation: "set",
-				Status:    "success",
+				Status:    status,
 				Bucket:    targetURL,
 				Perms:     perms,
 			})
 		}
+		if failedObjects > 0 {
+			console.Exit(exitStatusPartial)
+		}
 	case "get":
 		for _, arg := range ctx.Args().Tail() {
 			targetURL := getAliasURL(arg, config.Aliases)
+			if isURLRecursive(targetURL) {
+				err := doGetAccessRecursive(targetURL, func(objectURL string, perms bucketPerms) {
+					Prints("%s\n", AccessMessage{
+						Operation: "get",
+						Status:    "success",
+						Bucket:    objectURL,
+						Perms:     perms,
+					})
+				})
+				fatalIf(err.Trace(targetURL), "Unable to get access permissions for ‘"+targetURL+"’.")
+				continue
+			}
 			perms, err := doGetAccess(targetURL)
 			fatalIf(err.Trace(targetURL), "Unable to get access permission for ‘"+targetURL+"’.")
 
@@ -169,12 +250,31 @@ func mainAccess(ctx *cli.Context) {
 	}
 }
 
+// isBucketURL - cloud storage URLs without an object component address a bucket,
+// on the filesystem every folder is a bucket
+func isBucketURL(targetURL string) bool {
+	if client.NewURL(targetURL).Type != client.Object {
+		_, content, err := url2Stat(targetURL)
+		return err == nil && content.Type.IsDir()
+	}
+	_, bucket, prefix := url2BucketAndPrefix(targetURL)
+	return bucket != "" && prefix == ""
+}
+
 func doSetAccess(targetURL string, targetPERMS bucketPerms) *probe.Error {
 	clnt, err := url2Client(targetURL)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
-	if err = clnt.SetBucketACL(targetPERMS.String()); err != nil {
+	if globalDryRunFlag {
+		return nil
+	}
+	if isBucketURL(targetURL) {
+		err = clnt.SetBucketACL(targetPERMS.String())
+	} else {
+		err = clnt.SetObjectACL(targetPERMS.String())
+	}
+	if err != nil {
 		return err.Trace(targetURL, targetPERMS.String())
 	}
 	return nil
@@ -185,9 +285,94 @@ func doGetAccess(targetURL string) (perms bucketPerms, err *probe.Error) {
 	if err != nil {
 		return "", err.Trace(targetURL)
 	}
-	acl, err := clnt.GetBucketACL()
+	var acl string
+	if isBucketURL(targetURL) {
+		acl, err = clnt.GetBucketACL()
+	} else {
+		acl, err = clnt.GetObjectACL()
+	}
 	if err != nil {
 		return "", err.Trace(targetURL)
 	}
 	return aclToPerms(acl), nil
 }
+
+// listRecursive - send the URL of every object or file below a recursive URL, folders
+// are included only when asked for
+func listRecursive(targetURL string, includeFolders bool, objectFunc func(objectURL string) *probe.Error) *probe.Error {
+	targetURL = stripRecursiveURL(targetURL)
+	clnt, err := url2Client(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	targetURLParse := client.NewURL(targetURL)
+	targetURLDelimited := targetURLParse.String()[:strings.LastIndex(targetURLParse.String(),
+		string(targetURLParse.Separator))+1]
+	for content := range clnt.List(true) {
+		if content.Err != nil {
+			return content.Err.Trace(targetURL)
+		}
+		isFolder := content.Content.Type.IsDir() && includeFolders
+		if !content.Content.Type.IsRegular() && !isFolder {
+			continue
+		}
+		if err := objectFunc(targetURLDelimited + content.Content.Name); err != nil {
+			return err.Trace(targetURL)
+		}
+	}
+	return nil
+}
+
+// doSetAccessRecursive - set permissions on every object below targetURL, objects which fail are counted and skipped, progress is shown
+// with a scan bar or as one message per object in quiet and json modes
+func doSetAccessRecursive(targetURL string, targetPERMS bucketPerms) (objects, failed int, err *probe.Error) {
+	var scanBar scanBarFunc
+	if !globalQuietFlag && !globalJSONFlag {
+		scanBar = scanBarFactory()
+	}
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	err = listRecursive(targetURL, false, func(objectURL string) *probe.Error {
+		if err := doSetAccess(objectURL, targetPERMS); err != nil {
+			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
+			if scanBar != nil {
+				console.Eraseline()
+			}
+			errorIf(err.Trace(objectURL), "Unable to set access permission ‘"+string(targetPERMS)+"’ for ‘"+objectURL+"’.")
+			failed++
+			return nil
+		}
+		objects++
+		if scanBar != nil {
+			scanBar(objectURL)
+			return nil
+		}
+		Prints("%s\n", AccessMessage{
+			Operation: "set",
+			Status:    status,
+			Bucket:    objectURL,
+			Perms:     targetPERMS,
+		})
+		return nil
+	})
+	if scanBar != nil {
+		console.Eraseline()
+	}
+	return objects, failed, err
+}
+
+// doGetAccessRecursive - get permissions of every object below targetURL, on the
+// filesystem folders carry their own permissions and are reported as well
+func doGetAccessRecursive(targetURL string, permsFunc func(objectURL string, perms bucketPerms)) *probe.Error {
+	includeFolders := client.NewURL(stripRecursiveURL(targetURL)).Type == client.Filesystem
+	return listRecursive(targetURL, includeFolders, func(objectURL string) *probe.Error {
+		perms, err := doGetAccess(objectURL)
+		if err != nil {
+			return err.Trace(objectURL)
+		}
+		permsFunc(objectURL, perms)
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+)
+
+// Run cp, mirror and rm jobs from a batch file.
+var batchCmd = cli.Command{
+	Name:   "batch",
+	Usage:  "Run cp, mirror and rm jobs from a batch file.",
+	Action: mainBatch,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} run FILE
+
+   FILE = batch file in YAML or JSON, ‘-’ reads it from stdin.
+
+   Each job runs as a session of its own. Sessions of failed jobs are kept, so that the failed
+   objects can be transferred again by ‘mc session retry SESSION-ID’. Jobs share the number of
+   transfers running at once. Progress bars are not shown for batches. A batch with failed jobs
+   exits with status 2, the other jobs run to completion.
+
+   parallel: 2                  # jobs running at once [DEFAULT=1]
+   workers: 8                   # transfers running at once across all jobs [DEFAULT=number of CPUs-1]
+   jobs:
+     - name: photos             # [DEFAULT=job-N]
+       command: mirror          # cp | mirror | rm
+       sources: [backup/photos]
+       targets: [s3/archive/photos]
+       exclude: ["*.tmp"]       # patterns with ‘/’ match the path below the source, others the file name
+     - command: cp
+       sources:
+         - backup/logs...
+       targets: [s3/archive/logs]
+       include: ["*.gz"]
+       storage-class: STANDARD_IA
+       tags: "team=ops"         # cp only
+     - command: rm
+       targets: [s3/scratch/uploads...]
+
+EXAMPLES:
+   1. Run the nightly backup jobs.
+      $ mc {{.Name}} run nightly.yaml
+
+   2. Print what the jobs would transfer, along with a consolidated JSON summary.
+      $ mc --dry-run --json {{.Name}} run nightly.yaml
+
+`,
+}
+
+func checkBatchSyntax(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 2 || args.First() != "run" {
+		cli.ShowCommandHelpAndExit(ctx, "batch", 1) // last argument is exit code
+	}
+}
+
+func setBatchPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Batch":       color.New(color.FgGreen, color.Bold),
+		"BatchFailed": color.New(color.FgRed, color.Bold),
+		"Copy":        color.New(color.FgGreen, color.Bold),
+		"Mirror":      color.New(color.FgGreen, color.Bold),
+		"Remove":      color.New(color.FgGreen, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Batch":       color.New(color.FgWhite, color.Bold),
+			"BatchFailed": color.New(color.FgWhite, color.Bold),
+			"Copy":        color.New(color.FgWhite, color.Bold),
+			"Mirror":      color.New(color.FgWhite, color.Bold),
+			"Remove":      color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	/// Add more styles here
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainBatch(ctx *cli.Context) {
+	checkBatchSyntax(ctx)
+
+	setBatchPalette(ctx.GlobalString("colors"))
+
+	fileName := ctx.Args().Get(1)
+	bf, err := loadBatchFile(fileName)
+	fatalIf(err.Trace(fileName), "Unable to load batch file ‘"+fileName+"’.")
+
+	batch := runBatch(bf)
+	Prints("%s\n", batch)
+	if batch.FailedJobs > 0 {
+		console.Exit(exitStatusPartial)
+	}
+}
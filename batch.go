@@ -0,0 +1,328 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+	"gopkg.in/yaml.v2"
+)
+
+// batchJob is a cp, mirror or rm operation of a batch file.
+type batchJob struct {
+	Name         string   `yaml:"name"`
+	Command      string   `yaml:"command"`
+	Sources      []string `yaml:"sources"`
+	Targets      []string `yaml:"targets"`
+	Include      []string `yaml:"include"`
+	Exclude      []string `yaml:"exclude"`
+	StorageClass string   `yaml:"storage-class"`
+	Tags         string   `yaml:"tags"`
+}
+
+// batchFile lists the jobs of a batch. Parallel limits the number of jobs running
+// at once, workers the number of transfers running at once across all jobs.
+type batchFile struct {
+	Parallel int        `yaml:"parallel"`
+	Workers  int        `yaml:"workers"`
+	Jobs     []batchJob `yaml:"jobs"`
+}
+
+// loadBatchFile reads and validates a batch file, ‘-’ reads from stdin.
+func loadBatchFile(fileName string) (*batchFile, *probe.Error) {
+	var data []byte
+	var e error
+	if fileName == "-" {
+		data, e = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, e = ioutil.ReadFile(fileName)
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return parseBatchFile(data)
+}
+
+// parseBatchFile parses batch files in YAML, or JSON which is a subset of YAML.
+func parseBatchFile(data []byte) (*batchFile, *probe.Error) {
+	bf := &batchFile{}
+	if e := yaml.UnmarshalStrict(data, bf); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if err := bf.validate(); err != nil {
+		return nil, err.Trace()
+	}
+	return bf, nil
+}
+
+// validate checks the jobs of a batch, jobs without a name are named by their position.
+func (bf *batchFile) validate() *probe.Error {
+	if len(bf.Jobs) == 0 {
+		return probe.NewError(errors.New("No jobs found in batch file."))
+	}
+	if bf.Parallel < 0 || bf.Workers < 0 {
+		return probe.NewError(errors.New("‘parallel’ and ‘workers’ cannot be negative."))
+	}
+	if bf.Parallel == 0 {
+		bf.Parallel = 1
+	}
+	if bf.Workers == 0 {
+		bf.Workers = int(math.Max(float64(runtime.NumCPU())-1, 1))
+	}
+	names := make(map[string]bool)
+	for i := range bf.Jobs {
+		job := &bf.Jobs[i]
+		if job.Name == "" {
+			job.Name = "job-" + strconv.Itoa(i+1)
+		}
+		if names[job.Name] {
+			return probe.NewError(errors.New("Duplicate job name ‘" + job.Name + "’."))
+		}
+		names[job.Name] = true
+		if err := job.validate(); err != nil {
+			return err.Trace(job.Name)
+		}
+	}
+	return nil
+}
+
+func (job batchJob) validate() *probe.Error {
+	invalid := func(msg string) *probe.Error {
+		return probe.NewError(errors.New("Job ‘" + job.Name + "’: " + msg))
+	}
+	switch job.Command {
+	case "cp":
+		if len(job.Sources) == 0 || len(job.Targets) != 1 {
+			return invalid("‘cp’ expects one or more sources and a single target.")
+		}
+	case "mirror":
+		if len(job.Sources) != 1 || len(job.Targets) == 0 {
+			return invalid("‘mirror’ expects a single source and one or more targets.")
+		}
+	case "rm":
+		if len(job.Sources) != 0 || len(job.Targets) == 0 {
+			return invalid("‘rm’ expects one or more targets and no sources.")
+		}
+	default:
+		return invalid("unknown command ‘" + job.Command + "’, expected one of ‘cp’, ‘mirror’, ‘rm’.")
+	}
+	if _, err := args2URLs(job.args()); err != nil {
+		return invalid(err.ToGoError().Error())
+	}
+	for _, pattern := range append(append([]string{}, job.Include...), job.Exclude...) {
+		if _, e := path.Match(pattern, ""); e != nil {
+			return invalid("invalid pattern ‘" + pattern + "’.")
+		}
+	}
+	if job.StorageClass != "" {
+		if job.Command == "rm" {
+			return invalid("‘storage-class’ is not supported by ‘rm’.")
+		}
+		if _, err := parseStorageClass(job.StorageClass); err != nil {
+			return invalid(err.ToGoError().Error())
+		}
+	}
+	if job.Tags != "" {
+		if job.Command != "cp" {
+			return invalid("‘tags’ are only supported by ‘cp’.")
+		}
+		if _, err := parseTags(job.Tags); err != nil {
+			return invalid(err.ToGoError().Error())
+		}
+	}
+	return nil
+}
+
+// args provides the command line arguments of a job.
+func (job batchJob) args() []string {
+	return append(append([]string{}, job.Sources...), job.Targets...)
+}
+
+// matchFilters reports whether name passes the include and exclude patterns. Patterns
+// with a slash are matched against name, all others against its base name.
+func matchFilters(name string, include, exclude []string) bool {
+	name = filepath.ToSlash(name)
+	matches := func(pattern string) bool {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		matched, _ := path.Match(pattern, subject)
+		return matched
+	}
+	included := len(include) == 0
+	for _, pattern := range include {
+		if matches(pattern) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range exclude {
+		if matches(pattern) {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchJobMessage container for the outcome of a batch job, jobs which could not run carry their error.
+type BatchJobMessage struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+	RunReportMessage
+}
+
+// String colorized batch job message
+func (b BatchJobMessage) String() string {
+	if b.Status == "failed" {
+		return console.Colorize("BatchFailed", fmt.Sprintf("%-20s %-6s %s", b.Name, b.Command, b.Error))
+	}
+	message := fmt.Sprintf("%-20s %-6s %d/%d object(s), %s in %s", b.Name, b.Command, b.DoneObjects, b.TotalObjects,
+		humanize.IBytes(uint64(b.DoneBytes)), timeDurationToHumanizedTime(b.elapsed()).String())
+	if b.Status != "success" {
+		return console.Colorize("BatchFailed", message+fmt.Sprintf(", %d failed. To retry ‘mc session retry %s’", b.FailedObjects, b.SessionID))
+	}
+	return console.Colorize("Batch", message)
+}
+
+// JSON jsonified batch job message
+func (b BatchJobMessage) JSON() string {
+	batchJobMessageBytes, e := json.Marshal(b)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(batchJobMessageBytes)
+}
+
+// BatchMessage container for the consolidated summary of a batch
+type BatchMessage struct {
+	Status     string            `json:"status"`
+	TotalJobs  int               `json:"total-jobs"`
+	FailedJobs int               `json:"failed-jobs"`
+	Jobs       []BatchJobMessage `json:"jobs"`
+}
+
+// String colorized batch message
+func (b BatchMessage) String() string {
+	var message string
+	for _, job := range b.Jobs {
+		message += job.String() + "\n"
+	}
+	summary := fmt.Sprintf("%d job(s), %d failed.", b.TotalJobs, b.FailedJobs)
+	if b.FailedJobs > 0 {
+		return message + console.Colorize("BatchFailed", summary)
+	}
+	return message + console.Colorize("Batch", summary)
+}
+
+// JSON jsonified batch message
+func (b BatchMessage) JSON() string {
+	batchMessageBytes, e := json.Marshal(b)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(batchMessageBytes)
+}
+
+// runBatch runs the jobs of a batch, up to bf.Parallel at once sharing bf.Workers transfers.
+func runBatch(bf *batchFile) BatchMessage {
+	queue := make(chan bool, bf.Workers)
+	jobQueue := make(chan bool, bf.Parallel)
+	jobs := make([]BatchJobMessage, len(bf.Jobs))
+
+	wg := new(sync.WaitGroup)
+	for i, job := range bf.Jobs {
+		jobQueue <- true
+		wg.Add(1)
+		go func(i int, job batchJob) {
+			defer wg.Done()
+			defer func() {
+				<-jobQueue
+			}()
+			jobs[i] = runBatchJob(job, queue)
+		}(i, job)
+	}
+	wg.Wait()
+
+	batch := BatchMessage{Status: "success", TotalJobs: len(jobs), Jobs: jobs}
+	for _, job := range jobs {
+		if job.Status != "success" {
+			batch.Status = "partial"
+			batch.FailedJobs++
+		}
+	}
+	return batch
+}
+
+// runBatchJob runs a job, jobs which fail to run are reported along with their error.
+func runBatchJob(job batchJob, queue chan bool) BatchJobMessage {
+	report, err := executeBatchJob(job, queue)
+	if err != nil {
+		errorIf(err.Trace(job.Name), "Unable to run job ‘"+job.Name+"’.")
+		return BatchJobMessage{
+			Name:             job.Name,
+			Error:            err.ToGoError().Error(),
+			RunReportMessage: RunReportMessage{Command: job.Command, Status: "failed"},
+		}
+	}
+	return BatchJobMessage{Name: job.Name, RunReportMessage: report}
+}
+
+// executeBatchJob runs a job as session of its own, sessions with failures are kept for a retry.
+func executeBatchJob(job batchJob, queue chan bool) (RunReportMessage, *probe.Error) {
+	session := newSessionV2()
+	session.queue = queue
+	session.batch = true
+	session.Header.CommandType = job.Command
+	session.Header.Include = job.Include
+	session.Header.Exclude = job.Exclude
+	session.Header.Tags = job.Tags
+	// Arguments are validated by validate.
+	session.Header.StorageClass, _ = parseStorageClass(job.StorageClass)
+	session.Header.CommandArgs, _ = args2URLs(job.args())
+	var e error
+	if session.Header.RootPath, e = os.Getwd(); e != nil {
+		session.Delete()
+		return RunReportMessage{}, probe.NewError(e)
+	}
+
+	report, err := sessionExecute(session)
+	if err != nil {
+		// Sessions which failed to prepare are removed already, others are kept for a resume.
+		session.Close()
+		return RunReportMessage{}, err.Trace(session.SessionID)
+	}
+	if err = session.Finish(); err != nil {
+		return RunReportMessage{}, err.Trace(session.SessionID)
+	}
+	return report, nil
+}
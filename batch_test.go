@@ -0,0 +1,175 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestBatchFile(c *C) {
+	bf, perr := parseBatchFile([]byte(`
+jobs:
+  - command: cp
+    sources: [a...]
+    targets: [b]
+  - name: cleanup
+    command: rm
+    targets: [c...]
+`))
+	c.Assert(perr, IsNil)
+	c.Assert(bf.Parallel, Equals, 1)
+	c.Assert(bf.Workers > 0, Equals, true)
+	c.Assert(bf.Jobs[0].Name, Equals, "job-1")
+	c.Assert(bf.Jobs[1].Name, Equals, "cleanup")
+
+	// JSON is accepted as well
+	bf, perr = parseBatchFile([]byte(`{"workers": 3, "jobs": [{"command": "mirror", "sources": ["a"], "targets": ["b", "c"]}]}`))
+	c.Assert(perr, IsNil)
+	c.Assert(bf.Workers, Equals, 3)
+
+	// scalars are taken as written, numbers included
+	bf, perr = parseBatchFile([]byte("jobs:\n  - command: cp\n    sources: [2015, 0x10]\n    targets: [1e3]"))
+	c.Assert(perr, IsNil)
+	c.Assert(bf.Jobs[0].Sources, DeepEquals, []string{"2015", "0x10"})
+	c.Assert(bf.Jobs[0].Targets, DeepEquals, []string{"1e3"})
+
+	for _, invalid := range []string{
+		"jobs: []",
+		"jobs:\n  - command: mv\n    sources: [a]\n    targets: [b]",
+		"jobs:\n  - command: cp\n    sources: [a]\n    targets: [b, c]",
+		"jobs:\n  - command: rm\n    sources: [a]\n    targets: [b]",
+		"jobs:\n  - command: mirror\n    sources: [a]\n    targets: [b]\n    tags: a=b",
+		"jobs:\n  - command: cp\n    sources: [a]\n    targets: [b]\n    exclude: ['[']",
+		"jobs:\n  - command: cp\n    sources: [a]\n    targets: [b]\n    unknown: 1",
+		"jobs:\n  - {name: a}",
+	} {
+		_, perr := parseBatchFile([]byte(invalid))
+		c.Assert(perr, NotNil, Commentf("%s", invalid))
+	}
+
+	c.Assert(matchFilters("logs/app.log", []string{"*.log"}, nil), Equals, true)
+	c.Assert(matchFilters("logs/app.log", []string{"*.gz"}, nil), Equals, false)
+	c.Assert(matchFilters("logs/app.log", nil, []string{"logs/*"}), Equals, false)
+	c.Assert(matchFilters("app.log", nil, []string{"logs/*"}), Equals, true)
+}
+
+func (s *TestSuite) TestBatchRun(c *C) {
+	source, cpTarget, mirrorTarget, scratch := c.MkDir(), c.MkDir(), c.MkDir(), c.MkDir()
+	for _, name := range []string{"a.txt", "b.tmp", filepath.Join("sub", "c.txt")} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(source, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+	for _, name := range []string{"upload.part", "keep.txt"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(scratch, name), []byte(name), 0600), IsNil)
+	}
+	s3v4Server.MakeBucket("batch-bucket", "private")
+
+	batchFile := filepath.Join(c.MkDir(), "jobs.yaml")
+	c.Assert(ioutil.WriteFile(batchFile, []byte(`
+parallel: 2
+workers: 2
+jobs:
+  - name: copy
+    command: cp
+    sources: [`+source+`/...]
+    targets: [`+cpTarget+`]
+    exclude: ["*.tmp"]
+  - name: mirror
+    command: mirror
+    sources: [`+source+`]
+    targets: [`+mirrorTarget+`, `+s3v4Server.URL+`/batch-bucket]
+    include: ["sub/*"]
+  - name: cleanup
+    command: rm
+    targets: [`+scratch+`/...]
+    include: ["*.part"]
+`), 0600), IsNil)
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "batch", "run", batchFile})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	_, e := os.Stat(filepath.Join(cpTarget, "a.txt"))
+	c.Assert(e, IsNil)
+	_, e = os.Stat(filepath.Join(cpTarget, "b.tmp"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, e = os.Stat(filepath.Join(mirrorTarget, "sub", "c.txt"))
+	c.Assert(e, IsNil)
+	_, e = os.Stat(filepath.Join(mirrorTarget, "a.txt"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, ok := s3v4Server.GetObject("batch-bucket", "sub/c.txt")
+	c.Assert(ok, Equals, true)
+	_, e = os.Stat(filepath.Join(scratch, "upload.part"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	_, e = os.Stat(filepath.Join(scratch, "keep.txt"))
+	c.Assert(e, IsNil)
+
+	// failed jobs keep their session, so that they can be retried on their own
+	badJobs := []byte(`
+jobs:
+  - name: good
+    command: cp
+    sources: [` + source + `/a.txt]
+    targets: [` + cpTarget + `/a-copy.txt]
+  - name: bad
+    command: cp
+    sources: [` + source + `/a.txt]
+    targets: [` + s3v4Server.URL + `/batch-missing-bucket/a.txt]
+`)
+	c.Assert(ioutil.WriteFile(batchFile, badJobs, 0600), IsNil)
+	sids := make(map[string]bool)
+	for _, sid := range getSessionIDs() {
+		sids[sid] = true
+	}
+	defer func() {
+		console.ExitStatus = 0
+		console.IsError = false
+	}()
+	err = app.Run([]string{os.Args[0], "batch", "run", batchFile})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(console.ExitStatus, Equals, exitStatusPartial)
+	for _, sid := range getSessionIDs() {
+		if !sids[sid] {
+			session, perr := loadSessionV2(sid)
+			c.Assert(perr, IsNil)
+			c.Assert(session.Delete(), IsNil)
+		}
+	}
+
+	bf, perr := parseBatchFile(badJobs)
+	c.Assert(perr, IsNil)
+	batch := runBatch(bf)
+	c.Assert(batch.Status, Equals, "partial")
+	c.Assert(batch.FailedJobs, Equals, 1)
+	c.Assert(batch.Jobs[0].Status, Equals, "success")
+	c.Assert(batch.Jobs[0].DoneObjects, Equals, 1)
+	c.Assert(batch.Jobs[0].SessionID, Equals, "")
+	c.Assert(batch.Jobs[1].Status, Equals, "partial")
+	c.Assert(batch.Jobs[1].FailedObjects, Equals, 1)
+	c.Assert(isSession(batch.Jobs[1].SessionID), Equals, true)
+
+	session, perr := loadSessionV2(batch.Jobs[1].SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(session.Delete(), IsNil)
+}
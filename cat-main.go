@@ -35,12 +35,16 @@ var catCmd = cli.Command{
 	Name:   "cat",
 	Usage:  "Display contents of a file.",
 	Action: mainCat,
+	Flags:  []cli.Flag{versionIDFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} SOURCE [SOURCE...]
+   mc {{.Name}} [FLAGS] SOURCE [SOURCE...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Concantenate an object from Amazon S3 cloud storage to mplayer standard input.
       $ mc {{.Name}} https://s3.amazonaws.com/ferenginar/klingon_opera_aktuh_maylotah.ogg | mplayer -
@@ -60,6 +64,9 @@ EXAMPLES:
 
    6. Concantenate an object with space characters from Amazon S3 cloud storage.
       $ mc {{.Name}} 's3/miniocloud/Readme First.txt' | head -1
+
+   7. Display a previous version of an object from Amazon S3 cloud storage.
+      $ mc {{.Name}} --version-id 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nrjfkd s3/andoria/config.json
 `,
 }
 
@@ -74,6 +81,11 @@ func checkCatSyntax(ctx *cli.Context) {
 			fatalIf(probe.NewError(errors.New("")), fmt.Sprintf("Unknown flag ‘%s’ passed.", arg))
 		}
 	}
+	if versionID := ctx.String("version-id"); versionID != "" {
+		URLs, err := args2URLs(ctx.Args())
+		fatalIf(err.Trace(ctx.Args()...), "One or more unknown URL types passed.")
+		checkVersionIDSyntax(URLs, versionID)
+	}
 }
 
 // catURL displays contents of a URL to stdout.
@@ -151,6 +163,9 @@ func mainCat(ctx *cli.Context) {
 	}
 	// Convert arguments to URLs: expand alias, fix format...
 	for _, arg := range args {
+		if versionID := ctx.String("version-id"); versionID != "" {
+			arg = versionURL(arg, versionID)
+		}
 		fatalIf(catURL(arg).Trace(arg), "Unable to read from ‘"+arg+"’.")
 	}
 }
@@ -39,9 +39,9 @@ func (s *TestSuite) TestCat(c *C) {
 	dataLen := len(data)
 
 	var perr *probe.Error
-	perr = putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
-	perr = putTarget(objectPathServer, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = putTarget(objectPathServer, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
 	var sourceURLs []string
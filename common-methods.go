@@ -58,9 +58,9 @@ func getSource(sourceURL string) (reader io.ReadCloser, length int64, err *probe
 	return sourceClnt.GetObject(0, 0)
 }
 
-// putTarget writes to URL from reader. If length=0, read until EOF.
-func putTarget(targetURL string, length int64, reader io.Reader) *probe.Error {
-	targetClnt, err := url2Client(targetURL)
+// putTarget writes to URL from reader. If length=0, read until EOF. Objects on cloud storage are stored with storageClass if set.
+func putTarget(targetURL string, storageClass string, length int64, reader io.Reader) *probe.Error {
+	targetClnt, err := url2PutClient(targetURL, storageClass)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
@@ -71,8 +71,8 @@ func putTarget(targetURL string, length int64, reader io.Reader) *probe.Error {
 	return nil
 }
 
-// putTargets writes to URL from reader. If length=0, read until EOF.
-func putTargets(targetURLs []string, length int64, reader io.Reader) *probe.Error {
+// putTargets writes to URLs from reader. If length=0, read until EOF. Objects on cloud storage are stored with storageClass if set.
+func putTargets(targetURLs []string, storageClass string, length int64, reader io.Reader) *probe.Error {
 	var tgtReaders []*io.PipeReader
 	var tgtWriters []*io.PipeWriter
 	var tgtClients []client.Client
@@ -80,7 +80,7 @@ func putTargets(targetURLs []string, length int64, reader io.Reader) *probe.Erro
 	defer close(errCh)
 
 	for _, targetURL := range targetURLs {
-		tgtClient, err := url2Client(targetURL)
+		tgtClient, err := url2PutClient(targetURL, storageClass)
 		if err != nil {
 			return err.Trace(targetURL)
 		}
@@ -145,8 +145,19 @@ func putTargets(targetURLs []string, length int64, reader io.Reader) *probe.Erro
 	return nil // success.
 }
 
+// clientOptions - optional behavior of cloud storage clients, ignored by filesystem clients
+type clientOptions struct {
+	listTags     bool   // listings include object tags
+	storageClass string // storage class of uploaded objects
+}
+
 // getNewClient gives a new client interface
 func getNewClient(urlStr string, auth hostConfig) (client.Client, *probe.Error) {
+	return getNewClientWithOptions(urlStr, auth, clientOptions{})
+}
+
+// getNewClientWithOptions gives a new client interface configured with options
+func getNewClientWithOptions(urlStr string, auth hostConfig, options clientOptions) (client.Client, *probe.Error) {
 	url := client.NewURL(urlStr)
 	switch url.Type {
 	case client.Object: // Minio and S3 compatible cloud storage
@@ -168,6 +179,23 @@ func getNewClient(urlStr string, auth hostConfig) (client.Client, *probe.Error)
 		s3Config.AppComments = []string{os.Args[0], runtime.GOOS, runtime.GOARCH}
 		s3Config.HostURL = urlStr
 		s3Config.Debug = globalDebugFlag
+		s3Config.ListTags = options.listTags
+		s3Config.StorageClass = options.storageClass
+		s3Config.SessionToken = auth.SessionToken
+		if auth.refresh != nil {
+			// Temporary credentials are renewed before they expire.
+			s3Config.Refresh = func() (client.Credentials, *probe.Error) {
+				creds, err := auth.refresh()
+				if err != nil {
+					return client.Credentials{}, err.Trace(urlStr)
+				}
+				return client.Credentials{
+					AccessKeyID:     creds.AccessKeyID,
+					SecretAccessKey: creds.SecretAccessKey,
+					SessionToken:    creds.SessionToken,
+				}, nil
+			}
+		}
 
 		var s3Client client.Client
 		var err *probe.Error
@@ -202,6 +230,32 @@ func url2Client(url string) (client.Client, *probe.Error) {
 	return client, nil
 }
 
+// url2ListClient - client for listing, JSON listings of cloud storage carry object tags
+func url2ListClient(url string) (client.Client, *probe.Error) {
+	urlconfig, err := getHostConfig(url)
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	client, err := getNewClientWithOptions(url, urlconfig, clientOptions{listTags: globalJSONFlag})
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	return client, nil
+}
+
+// url2PutClient - client for uploads, objects on cloud storage are stored with storageClass if set
+func url2PutClient(url string, storageClass string) (client.Client, *probe.Error) {
+	urlconfig, err := getHostConfig(url)
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	client, err := getNewClientWithOptions(url, urlconfig, clientOptions{storageClass: storageClass})
+	if err != nil {
+		return nil, err.Trace(url)
+	}
+	return client, nil
+}
+
 // bucketExists returns error
 func bucketExists(URL string) *probe.Error {
 	_, _, err := url2Stat(URL)
@@ -40,9 +40,9 @@ func (s *TestSuite) TestCommonMethods(c *C) {
 	objectPathServer := server.URL + "/bucket/object1"
 	data := "hello"
 	dataLen := len(data)
-	perr := putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr := putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
-	perr = putTarget(objectPathServer, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = putTarget(objectPathServer, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
 	c.Assert(isTargetURLDir(objectPathServer), Equals, false)
@@ -202,6 +202,9 @@ func writeConfig(config quick.Config) *probe.Error {
 	if err := config.Save(configPath); err != nil {
 		return err.Trace()
 	}
+	// Drop cached configs, concurrent loads by the jobs of a batch may have left several.
+	for cache.Get() != nil {
+	}
 	return nil
 }
 
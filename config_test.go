@@ -17,8 +17,15 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3test"
 	"github.com/minio/mc/pkg/console"
 	. "gopkg.in/check.v1"
 )
@@ -88,3 +95,234 @@ func (s *TestSuite) TestConfigHostContext(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestConfigHostCredentials(c *C) {
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_CONFIG_FILE"} {
+		defer os.Setenv(env, os.Getenv(env))
+		os.Unsetenv(env)
+	}
+	awsDir := c.MkDir()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(awsDir, "credentials"))
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(awsDir, "config"))
+	defer func() {
+		credentialsCache = make(map[string]credentials)
+		globalProfileFlag = ""
+	}()
+
+	err := app.Run([]string{os.Args[0], "config", "host", "add", "creds.example.com"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	defer app.Run([]string{os.Args[0], "config", "host", "remove", "creds.example.com"})
+
+	// no source, placeholders are kept.
+	hostCfg, perr := getHostConfig("https://creds.example.com")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, globalAccessKeyID)
+	c.Assert(hostCfg.API, Equals, "S3v4")
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "ENVACCESSKEY")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "ENVSECRETKEY")
+	os.Setenv("AWS_SESSION_TOKEN", "ENVSESSIONTOKEN")
+	hostCfg, perr = getHostConfig("https://creds.example.com")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, "ENVACCESSKEY")
+	c.Assert(hostCfg.SecretAccessKey, Equals, "ENVSECRETKEY")
+	c.Assert(hostCfg.SessionToken, Equals, "ENVSESSIONTOKEN")
+	// anonymous hosts never get credentials.
+	hostCfg, perr = getHostConfig("https://play.minio.io:9000")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, "")
+
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	os.Unsetenv("AWS_SESSION_TOKEN")
+	credentialsCache = make(map[string]credentials)
+	c.Assert(ioutil.WriteFile(filepath.Join(awsDir, "credentials"), []byte(`[default]
+aws_access_key_id = DEFAULTACCESSKEY
+aws_secret_access_key = DEFAULTSECRETKEY
+
+# continuous integration
+[ci]
+aws_access_key_id = CIACCESSKEY
+aws_secret_access_key = CISECRETKEY
+`), 0600), IsNil)
+	hostCfg, perr = getHostConfig("https://creds.example.com")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, "DEFAULTACCESSKEY")
+	globalProfileFlag = "ci"
+	hostCfg, perr = getHostConfig("https://creds.example.com")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, "CIACCESSKEY")
+	c.Assert(hostCfg.SecretAccessKey, Equals, "CISECRETKEY")
+	source, perr := getCredentialsSource()
+	c.Assert(perr, IsNil)
+	c.Assert(source, Equals, credentialsSourceShared)
+
+	globalProfileFlag = "process"
+	c.Assert(ioutil.WriteFile(filepath.Join(awsDir, "config"), []byte(`[profile process]
+credential_process = echo '{"Version": 1, "AccessKeyId": "PROCESSACCESSKEY", "SecretAccessKey": "PROCESSSECRETKEY"}'
+`), 0600), IsNil)
+	source, perr = getCredentialsSource()
+	c.Assert(perr, IsNil)
+	c.Assert(source, Equals, credentialsSourceProcess)
+	hostCfg, perr = getHostConfig("https://creds.example.com")
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.AccessKeyID, Equals, "PROCESSACCESSKEY")
+	c.Assert(hostCfg.SecretAccessKey, Equals, "PROCESSSECRETKEY")
+
+	err = app.Run([]string{os.Args[0], "config", "host", "list"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+}
+
+func (s *TestSuite) TestConfigHostSecrets(c *C) {
+	// Migration converts all hosts, use a config of our own.
+	setMcConfigDir(c.MkDir())
+	defer setMcConfigDir(customConfigDir)
+	config, perr := newConfig()
+	c.Assert(perr, IsNil)
+	config.Data().(*configV5).Hosts["plain.example.com"] = hostConfig{
+		AccessKeyID:     "PLAINACCESSKEYIDXXXX",
+		SecretAccessKey: "PLAINSECRETACCESSKEYXXXXXXXXXXXXXXXXXXXX",
+		API:             "S3v4",
+		SessionToken:    "PLAINSESSIONTOKEN",
+	}
+	c.Assert(writeConfig(config), IsNil)
+
+	defer os.Setenv("MC_SECRETS_PASSPHRASE", os.Getenv("MC_SECRETS_PASSPHRASE"))
+	os.Setenv("MC_SECRETS_PASSPHRASE", "correct horse battery staple")
+	resetSecrets := func() {
+		secretStoreKey = nil
+		secretsCache = make(map[string]string)
+	}
+	defer resetSecrets()
+
+	err := app.Run([]string{os.Args[0], "config", "host", "add", "store.example.com", "STOREACCESSKEYIDXXXX", "STORESECRETACCESSKEYXXXXXXXXXXXXXXXXXXXX", "--secret-store", "--session-token", "STORESESSIONTOKEN"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "config", "host", "add", "command.example.com", "COMMANDACCESSKEYIDXX", "--secret-command", "echo COMMANDSECRET"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "config", "host", "migrate-secrets"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	// no secret is left in plaintext.
+	for _, path := range []string{mustGetMcConfigPath(), filepath.Join(mustGetMcConfigDir(), globalSecretsFile)} {
+		data, e := ioutil.ReadFile(path)
+		c.Assert(e, IsNil)
+		c.Assert(strings.Contains(string(data), "STORESECRET"), Equals, false)
+		c.Assert(strings.Contains(string(data), "PLAINSECRET"), Equals, false)
+		c.Assert(strings.Contains(string(data), "SESSIONTOKEN"), Equals, false)
+	}
+
+	resetSecrets()
+	for host, secret := range map[string]string{
+		"store.example.com":   "STORESECRETACCESSKEYXXXXXXXXXXXXXXXXXXXX",
+		"plain.example.com":   "PLAINSECRETACCESSKEYXXXXXXXXXXXXXXXXXXXX",
+		"command.example.com": "COMMANDSECRET",
+	} {
+		hostCfg, perr := getHostConfig("https://" + host)
+		c.Assert(perr, IsNil)
+		c.Assert(hostCfg.SecretAccessKey, Equals, secret)
+	}
+	for host, sessionToken := range map[string]string{
+		"store.example.com": "STORESESSIONTOKEN",
+		"plain.example.com": "PLAINSESSIONTOKEN",
+	} {
+		hostCfg, perr := getHostConfig("https://" + host)
+		c.Assert(perr, IsNil)
+		c.Assert(hostCfg.SessionToken, Equals, sessionToken)
+	}
+
+	resetSecrets()
+	os.Setenv("MC_SECRETS_PASSPHRASE", "wrong passphrase")
+	_, perr = getHostConfig("https://store.example.com")
+	c.Assert(perr, NotNil)
+
+	// without passphrase, stdin which is not a terminal is left to the command.
+	resetSecrets()
+	os.Unsetenv("MC_SECRETS_PASSPHRASE")
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	reader, writer, e := os.Pipe()
+	c.Assert(e, IsNil)
+	os.Stdin = reader
+	fmt.Fprintln(writer, "correct horse battery staple")
+	writer.Close()
+	_, perr = getHostConfig("https://store.example.com")
+	c.Assert(perr, NotNil)
+	data, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "correct horse battery staple\n")
+
+	// removed hosts take their secrets along.
+	err = app.Run([]string{os.Args[0], "config", "host", "remove", "store.example.com"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	store, perr := loadSecretStore()
+	c.Assert(perr, IsNil)
+	_, ok := store.Secrets["store.example.com"]
+	c.Assert(ok, Equals, false)
+	_, ok = store.Secrets[sessionTokenSecretName("store.example.com")]
+	c.Assert(ok, Equals, false)
+	_, ok = store.Secrets["plain.example.com"]
+	c.Assert(ok, Equals, true)
+}
+
+func (s *TestSuite) TestConfigHostTemporaryCredentials(c *C) {
+	server := s3test.NewServer("TEMPACCESSKEY", "TEMPSECRETKEY")
+	server.SessionToken = "TEMPSESSIONTOKEN"
+	defer server.Close()
+	server.MakeBucket("temp-bucket", "private")
+	host := client.NewURL(server.URL).Host
+
+	// every run of the command is counted.
+	root := c.MkDir()
+	countFile := filepath.Join(root, "count")
+	expiration := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	command := fmt.Sprintf(`echo run >> %s; echo '{"Version": 1, "AccessKeyId": "TEMPACCESSKEY", "SecretAccessKey": "TEMPSECRETKEY", "SessionToken": "TEMPSESSIONTOKEN", "Expiration": "%s"}'`, countFile, expiration)
+	defer func() { refreshCache = make(map[string]credentials) }()
+	err := app.Run([]string{os.Args[0], "config", "host", "add", host, "--refresh-command", command})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	defer app.Run([]string{os.Args[0], "config", "host", "remove", host})
+	runs := func() int {
+		data, e := ioutil.ReadFile(countFile)
+		c.Assert(e, IsNil)
+		return strings.Count(string(data), "run")
+	}
+
+	// requests carry the session token.
+	console.IsError = false
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file"), []byte("temporary"), 0600), IsNil)
+	err = app.Run([]string{os.Args[0], "cp", filepath.Join(root, "file"), server.URL + "/temp-bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsError, Equals, false)
+	data, ok := server.GetObject("temp-bucket", "file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(data), Equals, "temporary")
+
+	hostCfg, perr := getHostConfig(server.URL)
+	c.Assert(perr, IsNil)
+	c.Assert(hostCfg.SessionToken, Equals, "TEMPSESSIONTOKEN")
+	c.Assert(hostCfg.refresh, NotNil)
+	c.Assert(runs(), Equals, 1)
+
+	// credentials about to expire are renewed.
+	creds := refreshCache[command]
+	creds.Expiration = time.Now().UTC().Add(time.Minute)
+	refreshCache[command] = creds
+	_, perr = hostCfg.refresh()
+	c.Assert(perr, IsNil)
+	c.Assert(runs(), Equals, 2)
+	_, perr = hostCfg.refresh()
+	c.Assert(perr, IsNil)
+	c.Assert(runs(), Equals, 2)
+
+	err = app.Run([]string{os.Args[0], "config", "host", "list"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+}
@@ -21,10 +21,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net"
 	"os"
-	"runtime"
+	"path/filepath"
 	"sync"
 
 	"github.com/fatih/color"
@@ -38,12 +37,17 @@ var cpCmd = cli.Command{
 	Name:   "cp",
 	Usage:  "Copy files and folders from many sources to a single destination.",
 	Action: mainCopy,
+	Flags:  []cli.Flag{versionIDFlag, tagsFlag, fromFileFlag, reportFlag, storageClassFlag, sessionExpiryFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} SOURCE [SOURCE...] TARGET
+   mc {{.Name}} [FLAGS] SOURCE [SOURCE...] TARGET
+   mc {{.Name}} [FLAGS] --from-file LIST TARGET
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Copy list of objects from local file system to Amazon S3 cloud storage.
       $ mc {{.Name}} Music/*.ogg https://s3.amazonaws.com/jukebox/
@@ -62,6 +66,29 @@ EXAMPLES:
 
    6. Copy local folder with space characters to Amazon S3 cloud storage.
       $ mc {{.Name}} 'workdir/documents/May 2014...' s3/miniocloud
+
+   7. Restore a previous version of an object on Amazon S3 cloud storage.
+      $ mc {{.Name}} --version-id 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nrjfkd s3/andoria/config.json s3/andoria/config.json
+
+   8. Copy a local folder recursively to Amazon S3 cloud storage and tag every object.
+      $ mc {{.Name}} --tags "team=storage&retention=30d" backup/2015/... s3/archive/2015
+
+   9. Copy a local folder recursively to Amazon S3 cloud storage as infrequently accessed objects. Storage classes are ignored by filesystem targets.
+      $ mc {{.Name}} --storage-class STANDARD_IA backup/2015/... s3/archive/2015
+
+   10. Copy objects listed in a file, one URL per line, to Amazon S3 cloud storage. A tab separated second column overrides the target of a line.
+      $ mc {{.Name}} --from-file list.txt s3/archive/2015
+
+   11. Copy objects listed on standard input to Amazon S3 cloud storage.
+      $ find backup/2015 -name "*.log" | mc {{.Name}} --from-file - s3/archive/logs
+
+   12. Copy a local folder recursively to Amazon S3 cloud storage and write the JSON summary of the run to a file.
+      $ mc {{.Name}} --report report.json backup/2015/... s3/archive/2015
+
+EXIT STATUS:
+   0 - All objects were copied.
+   1 - Fatal error, nothing or only part of the objects were copied.
+   2 - One or more objects failed to copy, retry them with ‘mc session retry SESSION-ID’.
 `,
 }
 
@@ -85,8 +112,8 @@ func (c CopyMessage) JSON() string {
 	return string(copyMessageBytes)
 }
 
-// doCopy - Copy a singe file from source to destination
-func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
+// doCopy - Copy a singe file from source to destination, the target is tagged if tags are given and stored with storageClass if set
+func doCopy(cpURLs copyURLs, tags map[string]string, storageClass string, progressReader interface{}, cpQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
 	defer wg.Done() // Notify that this copy routine is done.
 	defer func() {
 		<-cpQueue
@@ -98,14 +125,16 @@ func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg
 		return
 	}
 
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).SetCaption(cpURLs.SourceContent.Name + ": ")
+	// Sessions without progress bar account their transfers.
+	bar, showProgress := progressReader.(*barSend)
+	if showProgress {
+		bar.SetCaption(cpURLs.SourceContent.Name + ": ")
 	}
 
 	reader, length, err := getSource(cpURLs.SourceContent.Name)
 	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorGet(length)
+		if showProgress {
+			bar.ErrorGet(length)
 		}
 		cpURLs.Error = err.Trace()
 		statusCh <- cpURLs
@@ -113,7 +142,7 @@ func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg
 	}
 
 	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
+	if !showProgress {
 		Prints("%s\n", CopyMessage{
 			Source: cpURLs.SourceContent.Name,
 			Target: cpURLs.TargetContent.Name,
@@ -122,18 +151,25 @@ func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg
 		newReader = progressReader.(*accounter).NewProxyReader(reader)
 	} else {
 		// set up progress
-		newReader = progressReader.(*barSend).NewProxyReader(reader)
+		newReader = bar.NewProxyReader(reader)
 	}
 	defer newReader.Close()
 
-	if err := putTarget(cpURLs.TargetContent.Name, length, newReader); err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorPut(length)
+	if err := putTarget(cpURLs.TargetContent.Name, storageClass, length, newReader); err != nil {
+		if showProgress {
+			bar.ErrorPut(length)
 		}
 		cpURLs.Error = err.Trace()
 		statusCh <- cpURLs
 		return
 	}
+	if len(tags) > 0 {
+		if err := setTags(cpURLs.TargetContent.Name, tags); err != nil {
+			cpURLs.Error = err.Trace(cpURLs.TargetContent.Name)
+			statusCh <- cpURLs
+			return
+		}
+	}
 
 	cpURLs.Error = nil // just for safety
 	statusCh <- cpURLs
@@ -141,13 +177,13 @@ func doCopy(cpURLs copyURLs, progressReader interface{}, cpQueue <-chan bool, wg
 
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(cURLs copyURLs, progressReader interface{}) {
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).Progress(cURLs.SourceContent.Size)
+	if bar, ok := progressReader.(*barSend); ok {
+		bar.Progress(cURLs.SourceContent.Size)
 	}
 }
 
 // doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
-func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
+func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) *probe.Error {
 	// Separate source and target. 'cp' can take only one target,
 	// but any number of sources, even the recursive URLs mixed in-between.
 	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
@@ -159,12 +195,24 @@ func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
 	// Create a session data file to store the processed URLs.
 	dataFP := session.NewDataWriter()
 
+	showProgress := session.showProgress()
 	var scanBar scanBarFunc
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
+	if showProgress { // set up progress bar
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareCopyURLs(sourceURLs, targetURL)
+	var URLsCh <-chan copyURLs
+	if session.Header.FromFile != "" {
+		list, err := openCopyList(session.Header.FromFile)
+		if err != nil {
+			session.Delete()
+			return err.Trace(session.Header.FromFile)
+		}
+		defer list.Close()
+		URLsCh = prepareCopyURLsFromList(list, targetURL)
+	} else {
+		URLsCh = prepareCopyURLs(sourceURLs, targetURL)
+	}
 	done := false
 
 	for done == false {
@@ -176,20 +224,23 @@ func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
 			}
 			if cpURLs.Error != nil {
 				// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-				if !globalQuietFlag && !globalJSONFlag {
+				if showProgress {
 					console.Eraseline()
 				}
 				errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
 				break
 			}
+			if session.Header.IsFiltered(cpURLs.SourceContent.Name) {
+				break
+			}
 
 			jsonData, err := json.Marshal(cpURLs)
 			if err != nil {
 				session.Delete()
-				fatalIf(probe.NewError(err), "Unable to prepare URL for copying. Error in JSON marshaling.")
+				return probe.NewError(err)
 			}
 			fmt.Fprintln(dataFP, string(jsonData))
-			if !globalQuietFlag && !globalJSONFlag {
+			if showProgress {
 				scanBar(cpURLs.SourceContent.Name)
 			}
 
@@ -197,7 +248,7 @@ func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
 			totalObjects++
 		case <-trapCh:
 			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-			if !globalQuietFlag && !globalJSONFlag {
+			if showProgress {
 				console.Eraseline()
 			}
 			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
@@ -206,18 +257,64 @@ func doPrepareCopyURLs(session *sessionV2, trapCh <-chan bool) {
 	}
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
-	session.Save()
+	return session.Save().Trace(session.SessionID)
 }
 
-func doCopySession(session *sessionV2) {
+// doCopyDryRun - print the planned copy operations along with totals, nothing is copied.
+func doCopyDryRun(session *sessionV2) *probe.Error {
+	if session.showProgress() {
+		console.Eraseline()
+	}
+	scanner := bufio.NewScanner(session.NewDataReader())
+	isCopied, err := session.NewSkipFilter()
+	if err != nil {
+		return err.Trace(session.SessionID)
+	}
+	var totalBytes int64
+	var totalObjects int
+	for index := 0; scanner.Scan(); index++ {
+		var cpURLs copyURLs
+		json.Unmarshal([]byte(scanner.Text()), &cpURLs)
+		if isCopied(index, cpURLs.SourceContent.Name) {
+			continue
+		}
+		Prints("%s\n", CopyMessage{
+			Source: cpURLs.SourceContent.Name,
+			Target: cpURLs.TargetContent.Name,
+			Length: cpURLs.SourceContent.Size,
+		})
+		totalBytes += cpURLs.SourceContent.Size
+		totalObjects++
+	}
+	Prints("%s\n", DryRunMessage{
+		Operation:    "cp",
+		TotalObjects: totalObjects,
+		TotalBytes:   totalBytes,
+	})
+	return nil
+}
+
+// doCopySession copies the objects of a session, errors which stop the whole session are returned.
+func doCopySession(session *sessionV2) (RunReportMessage, *probe.Error) {
 	trapCh := signalTrap(os.Interrupt, os.Kill)
 
 	if !session.HasData() {
-		doPrepareCopyURLs(session, trapCh)
+		if err := doPrepareCopyURLs(session, trapCh); err != nil {
+			return RunReportMessage{}, err.Trace(session.SessionID)
+		}
+	}
+
+	// Only print the copy plan, do not transfer anything.
+	if globalDryRunFlag {
+		if err := doCopyDryRun(session); err != nil {
+			return RunReportMessage{}, err.Trace(session.SessionID)
+		}
+		return newRunReport(session).Finish(), nil
 	}
 
+	showProgress := session.showProgress()
 	var progressReader interface{}
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
+	if showProgress { // set up progress bar
 		progressReader = newProgressBar(session.Header.TotalBytes)
 	} else {
 		progressReader = newAccounter(session.Header.TotalBytes)
@@ -227,12 +324,21 @@ func doCopySession(session *sessionV2) {
 	scanner := bufio.NewScanner(session.NewDataReader())
 	// isCopied returns true if an object has been already copied
 	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
+	isCopied, err := session.NewSkipFilter()
+	if err != nil {
+		return RunReportMessage{}, err.Trace(session.SessionID)
+	}
+	report := newRunReport(session)
+
+	// Tags are validated before the session is saved.
+	var tags map[string]string
+	if session.Header.Tags != "" {
+		tags, _ = parseTags(session.Header.Tags)
+	}
 
 	wg := new(sync.WaitGroup)
-	// Limit number of copy routines based on available CPU resources.
-	cpQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
-	defer close(cpQueue)
+	// Limit number of copy routines, sessions run by a batch share the limit.
+	cpQueue := session.TransferQueue()
 
 	// Status channel for receiveing copy return status.
 	statusCh := make(chan copyURLs)
@@ -245,28 +351,34 @@ func doCopySession(session *sessionV2) {
 			select {
 			case cpURLs, ok := <-statusCh: // Receive status.
 				if !ok { // We are done here. Top level function has returned.
-					if !globalQuietFlag && !globalJSONFlag {
+					if showProgress {
 						progressReader.(*barSend).Finish()
 					} else {
-						console.Println(console.Colorize("Copy", progressReader.(*accounter).Finish()))
+						progressReader.(*accounter).Finish()
 					}
 					return
 				}
 				if cpURLs.Error == nil {
-					session.Header.LastCopied = cpURLs.SourceContent.Name
+					report.Done(cpURLs.SourceContent.Size)
+					errorIf(session.SetObjectStatus(cpURLs.index, cpURLs.SourceContent.Name, cpURLs.SourceContent.Size, nil).Trace(), "Unable to record status of ‘"+cpURLs.SourceContent.Name+"’.")
 					session.Save()
 				} else {
 					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-					if !globalQuietFlag && !globalJSONFlag {
+					if showProgress {
 						console.Eraseline()
 					}
 					errorIf(cpURLs.Error.Trace(), fmt.Sprintf("Failed to copy ‘%s’.", cpURLs.SourceContent.Name))
+					report.Failed()
+					errorIf(session.SetObjectStatus(cpURLs.index, cpURLs.SourceContent.Name, cpURLs.SourceContent.Size, cpURLs.Error).Trace(), "Unable to record status of ‘"+cpURLs.SourceContent.Name+"’.")
 					// all the cases which are handled where session should be saved are contained in the following
 					// switch case, we shouldn't be saving sessions for all errors since some errors might need to be
 					// reported to user properly.
 					//
 					// All other critical cases should be handled properly gracefully
 					// handle more errors and save the session.
+					if session.batch { // other sessions of the batch keep running.
+						break
+					}
 					switch cpURLs.Error.ToGoError().(type) {
 					case *net.OpError:
 						gracefulSessionSave(session)
@@ -275,7 +387,7 @@ func doCopySession(session *sessionV2) {
 					}
 				}
 			case <-trapCh: // Receive interrupt notification.
-				if !globalQuietFlag && !globalJSONFlag {
+				if showProgress {
 					console.Eraseline()
 				}
 				gracefulSessionSave(session)
@@ -290,11 +402,13 @@ func doCopySession(session *sessionV2) {
 		copyWg := new(sync.WaitGroup)
 		defer close(statusCh)
 
-		for scanner.Scan() {
+		for index := 0; scanner.Scan(); index++ {
 			var cpURLs copyURLs
 			json.Unmarshal([]byte(scanner.Text()), &cpURLs)
-			if isCopied(cpURLs.SourceContent.Name) {
+			cpURLs.index = index
+			if isCopied(index, cpURLs.SourceContent.Name) {
 				doCopyFake(cpURLs, progressReader)
+				report.Skipped()
 			} else {
 				// Wait for other copy routines to
 				// complete. We only have limited CPU
@@ -303,12 +417,13 @@ func doCopySession(session *sessionV2) {
 				// Account for each copy routines we start.
 				copyWg.Add(1)
 				// Do copying in background concurrently.
-				go doCopy(cpURLs, progressReader, cpQueue, copyWg, statusCh)
+				go doCopy(cpURLs, tags, session.Header.StorageClass, progressReader, cpQueue, copyWg, statusCh)
 			}
 		}
 		copyWg.Wait()
 	}()
 	wg.Wait()
+	return report.Finish(), nil
 }
 
 func setCopyPalette(style string) {
@@ -333,6 +448,8 @@ func mainCopy(ctx *cli.Context) {
 	checkCopySyntax(ctx)
 
 	setCopyPalette(ctx.GlobalString("colors"))
+	setReportPalette(ctx.GlobalString("colors"))
+	errorIf(expireSessions(ctx.String("session-expiry")).Trace(), "Unable to expire abandoned sessions.")
 
 	session := newSessionV2()
 
@@ -351,7 +468,27 @@ func mainCopy(ctx *cli.Context) {
 		session.Delete()
 		fatalIf(err.Trace(), "One or more unknown URL types passed.")
 	}
-
-	doCopySession(session)
-	session.Delete()
+	// Version selection is persisted along with the source URL, so that it survives a resume.
+	if versionID := ctx.String("version-id"); versionID != "" {
+		session.Header.CommandArgs[0] = versionURL(session.Header.CommandArgs[0], versionID)
+	}
+	// Sources are read from a list, command line only carries the target.
+	if fromFile := ctx.String("from-file"); fromFile != "" {
+		session.Header.FromFile = fromFile
+		if fromFile != "-" {
+			session.Header.FromFile, e = filepath.Abs(fromFile)
+			if e != nil {
+				session.Delete()
+				fatalIf(probe.NewError(e), "Unable to read list of sources ‘"+fromFile+"’.")
+			}
+		}
+	}
+	session.Header.Tags = ctx.String("tags")
+	// Storage class is validated by checkCopySyntax.
+	session.Header.StorageClass, _ = parseStorageClass(ctx.String("storage-class"))
+
+	report, err := doCopySession(session)
+	fatalIf(err.Trace(session.SessionID), "Unable to copy session ‘"+session.SessionID+"’.")
+	fatalIf(session.Finish().Trace(session.SessionID), "Unable to finish session ‘"+session.SessionID+"’.")
+	printRunReport(report, ctx.String("report"))
 }
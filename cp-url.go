@@ -17,7 +17,11 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -30,6 +34,8 @@ type copyURLs struct {
 	SourceContent *client.Content
 	TargetContent *client.Content
 	Error         *probe.Error `json:"-"`
+	// index of the line in the session data file, it identifies the object in the status log.
+	index int
 }
 
 type copyURLsType uint8
@@ -58,6 +64,12 @@ const (
 //   C: copy(*, d...)
 //
 func checkCopySyntax(ctx *cli.Context) {
+	checkReportSyntax(ctx)
+	checkSessionExpirySyntax(ctx)
+	if fromFile := ctx.String("from-file"); fromFile != "" {
+		checkCopyFromFileSyntax(ctx, fromFile)
+		return
+	}
 	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
 		cli.ShowCommandHelpAndExit(ctx, "cp", 1) // last argument is exit code.
 	}
@@ -68,6 +80,14 @@ func checkCopySyntax(ctx *cli.Context) {
 	srcURLs := URLs[:len(URLs)-1]
 	tgtURL := URLs[len(URLs)-1]
 
+	versionID := ctx.String("version-id")
+	checkVersionIDSyntax(srcURLs, versionID)
+	checkTagsSyntax(ctx.String("tags"), tgtURL)
+	checkStorageClassSyntax(ctx.String("storage-class"), tgtURL)
+	if versionID != "" {
+		srcURLs[0] = versionURL(srcURLs[0], versionID)
+	}
+
 	/****** Generic rules *******/
 	// Recursive URLs are not allowed in target.
 	if isURLRecursive(tgtURL) {
@@ -102,6 +122,38 @@ func checkCopySyntax(ctx *cli.Context) {
 	}
 }
 
+// checkCopyFromFileSyntax verifies the target of a copy whose sources are read from a list.
+func checkCopyFromFileSyntax(ctx *cli.Context, fromFile string) {
+	if len(ctx.Args()) != 1 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "cp", 1) // last argument is exit code.
+	}
+	if ctx.String("version-id") != "" {
+		fatalIf(errInvalidArgument().Trace(), "Version selection is not supported with ‘--from-file’.")
+	}
+	if fromFile != "-" {
+		if _, e := os.Stat(fromFile); e != nil {
+			fatalIf(probe.NewError(e), "Unable to read list of sources ‘"+fromFile+"’.")
+		}
+	}
+	// extract URLs.
+	URLs, err := args2URLs(ctx.Args())
+	fatalIf(err.Trace(ctx.Args()...), fmt.Sprintf("One or more unknown URL types passed."))
+
+	tgtURL := URLs[0]
+	checkTagsSyntax(ctx.String("tags"), tgtURL)
+	checkStorageClassSyntax(ctx.String("storage-class"), tgtURL)
+	if isURLRecursive(tgtURL) {
+		fatalIf(errDummy().Trace(), fmt.Sprintf("Recursive option is not supported for target ‘%s’ argument.", tgtURL))
+	}
+	_, tgtContent, err := url2Stat(tgtURL)
+	// Target exist?.
+	if err == nil {
+		if !tgtContent.Type.IsDir() {
+			fatalIf(errInvalidArgument().Trace(), "Target ‘"+tgtURL+"’ is not a folder.")
+		}
+	}
+}
+
 // checkCopySyntaxTypeA verifies if the source and target are valid file arguments.
 func checkCopySyntaxTypeA(srcURLs []string, tgtURL string) {
 	if len(srcURLs) != 1 {
@@ -379,3 +431,76 @@ func prepareCopyURLs(sourceURLs []string, targetURL string) <-chan copyURLs {
 
 	return copyURLsCh
 }
+
+// openCopyList opens a list of source URLs for ‘cp --from-file’, ‘-’ reads from stdin.
+func openCopyList(listPath string) (io.ReadCloser, *probe.Error) {
+	if listPath == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	listFile, e := os.Open(listPath)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return listFile, nil
+}
+
+// parseCopyListLine splits a line of a copy list into source URL and an
+// optional target override, separated by a tab. Empty lines and lines
+// starting with ‘#’ are ignored.
+func parseCopyListLine(line string) (sourceURL, targetURL string, ok bool) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	fields := strings.SplitN(line, "\t", 2)
+	sourceURL = strings.TrimSpace(fields[0])
+	if len(fields) == 2 {
+		targetURL = strings.TrimSpace(fields[1])
+	}
+	return sourceURL, targetURL, sourceURL != ""
+}
+
+// prepareCopyURLsFromList - prepares target and source URLs for copying from
+// a list of source URLs, one per line. The list is read as it is consumed, so
+// arbitrarily long lists are never held in memory. Sources without a target
+// override are copied into targetURL, overrides are copied like ‘cp SOURCE TARGET’.
+func prepareCopyURLsFromList(list io.Reader, targetURL string) <-chan copyURLs {
+	copyURLsCh := make(chan copyURLs)
+	go func(list io.Reader, targetURL string, copyURLsCh chan copyURLs) {
+		defer close(copyURLsCh)
+
+		config, err := getMcConfig()
+		if err != nil {
+			copyURLsCh <- copyURLs{Error: err.Trace()}
+			return
+		}
+		scanner := bufio.NewScanner(list)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			sourceURL, overrideURL, ok := parseCopyListLine(scanner.Text())
+			if !ok {
+				continue
+			}
+			sourceURL = getAliasURL(sourceURL, config.Aliases)
+			if overrideURL == "" {
+				// Target is the folder given on command line, possibilities are only Type B and C.
+				for cURLs := range prepareCopyURLsTypeD([]string{sourceURL}, targetURL) {
+					copyURLsCh <- cURLs
+				}
+				continue
+			}
+			overrideURL = getAliasURL(overrideURL, config.Aliases)
+			if isURLRecursive(overrideURL) {
+				copyURLsCh <- copyURLs{Error: errInvalidTarget(overrideURL).Trace(sourceURL)}
+				continue
+			}
+			for cURLs := range prepareCopyURLs([]string{sourceURL}, overrideURL) {
+				copyURLsCh <- cURLs
+			}
+		}
+		if e := scanner.Err(); e != nil {
+			copyURLsCh <- copyURLs{Error: probe.NewError(e)}
+		}
+	}(list, targetURL, copyURLsCh)
+	return copyURLsCh
+}
@@ -17,8 +17,14 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/minio/mc/pkg/client/s3test"
 	"github.com/minio/mc/pkg/console"
 	. "gopkg.in/check.v1"
 )
@@ -70,3 +76,224 @@ func (s *TestSuite) TestMirrorContext(c *C) {
 	// reset back
 	console.IsError = false
 }
+
+func (s *TestSuite) TestCopyToServer(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "cp-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"file0", "file1", filepath.Join("dir", "file2")} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600), IsNil)
+	}
+
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		s3Server.MakeBucket("cp-bucket", "private")
+		err := app.Run([]string{os.Args[0], "cp", root + "/...", s3Server.URL + "/cp-bucket"})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsError, Equals, false)
+		c.Assert(console.IsExited, Equals, false)
+
+		for _, name := range []string{"file0", "file1", "dir/file2"} {
+			data, ok := s3Server.GetObject("cp-bucket", name)
+			c.Assert(ok, Equals, true)
+			c.Assert(string(data), Equals, filepath.FromSlash(name))
+		}
+
+		// copy back from the server
+		target, e := ioutil.TempDir(os.TempDir(), "cp-")
+		c.Assert(e, IsNil)
+		err = app.Run([]string{os.Args[0], "cp", s3Server.URL + "/cp-bucket/dir/file2", target})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsError, Equals, false)
+		data, e := ioutil.ReadFile(filepath.Join(target, "file2"))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, filepath.Join("dir", "file2"))
+		os.RemoveAll(target)
+	}
+}
+
+func (s *TestSuite) TestMirrorToServer(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "mirror-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	for _, name := range []string{"file0", "file1"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600), IsNil)
+	}
+
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		s3Server.MakeBucket("mirror-bucket", "private")
+		err := app.Run([]string{os.Args[0], "mirror", root + "/...", s3Server.URL + "/mirror-bucket"})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsError, Equals, false)
+		c.Assert(console.IsExited, Equals, false)
+
+		for _, name := range []string{"file0", "file1"} {
+			data, ok := s3Server.GetObject("mirror-bucket", name)
+			c.Assert(ok, Equals, true)
+			c.Assert(string(data), Equals, name)
+		}
+	}
+}
+
+func (s *TestSuite) TestDryRun(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "dry-run-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "file0"), []byte("file0"), 0600), IsNil)
+
+	// global flags are set directly, make sure later tests see the defaults
+	globalDryRunFlag = true
+	defer func() {
+		globalDryRunFlag = false
+		globalJSONFlag = false
+	}()
+
+	s3v4Server.MakeBucket("dry-run-bucket", "private")
+	err := app.Run([]string{os.Args[0], "cp", root + "/...", s3v4Server.URL + "/dry-run-bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsError, Equals, false)
+	_, ok := s3v4Server.GetObject("dry-run-bucket", "file0")
+	c.Assert(ok, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "mirror", root + "/...", s3v4Server.URL + "/dry-run-bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsError, Equals, false)
+	_, ok = s3v4Server.GetObject("dry-run-bucket", "file0")
+	c.Assert(ok, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "mb", s3v4Server.URL + "/dry-run-new-bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	_, ok = s3v4Server.BucketACL("dry-run-new-bucket")
+	c.Assert(ok, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "access", "set", "public", s3v4Server.URL + "/dry-run-bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	acl, _ := s3v4Server.BucketACL("dry-run-bucket")
+	c.Assert(acl, Equals, "private")
+
+	// nothing is left behind in the target folder either
+	target, e := ioutil.TempDir(os.TempDir(), "dry-run-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(target)
+	globalJSONFlag = true
+	err = app.Run([]string{os.Args[0], "cp", root + "/...", target})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsError, Equals, false)
+	_, e = os.Stat(filepath.Join(target, "file0"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+
+	// a dry run has nothing to report.
+	globalJSONFlag = false
+	report := filepath.Join(target, "report.json")
+	err = app.Run([]string{os.Args[0], "cp", root + "/...", target, "--report", report})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+	_, e = os.Stat(report)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
+
+func (s *TestSuite) TestCopyFromFile(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	source, target := c.MkDir(), c.MkDir()
+	for _, name := range []string{"object1", "object2", filepath.Join("folder", "object3")} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(source, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+
+	source, target = filepath.ToSlash(source), filepath.ToSlash(target)
+	list := filepath.Join(c.MkDir(), "list.txt")
+	c.Assert(ioutil.WriteFile(list, []byte("# objects to copy\n"+
+		source+"/object1\n"+
+		"\n"+
+		source+"/object2\t"+target+"/renamed/object2\n"+
+		source+"/folder...\n"), 0600), IsNil)
+
+	err := app.Run([]string{os.Args[0], "cp", "--from-file", list, target})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	for name, expected := range map[string]string{
+		"object1":                           "object1",
+		filepath.Join("renamed", "object2"): "object2",
+		filepath.Join("folder", "object3"):  filepath.Join("folder", "object3"),
+	} {
+		data, e := ioutil.ReadFile(filepath.Join(target, name))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, expected)
+	}
+	_, e := os.Stat(filepath.Join(target, "object2"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+
+	// ‘-’ reads the list from stdin.
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	reader, writer, e := os.Pipe()
+	c.Assert(e, IsNil)
+	os.Stdin = reader
+	go func() {
+		defer writer.Close()
+		fmt.Fprintln(writer, source+"/object2")
+	}()
+	target = c.MkDir()
+	err = app.Run([]string{os.Args[0], "cp", "--from-file", "-", target})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	data, e := ioutil.ReadFile(filepath.Join(target, "object2"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "object2")
+}
+
+func (s *TestSuite) TestRunReport(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	source, target := c.MkDir(), c.MkDir()
+	for _, name := range []string{"object1", "object2"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600), IsNil)
+	}
+	// object2 can not be written over a folder.
+	c.Assert(os.MkdirAll(filepath.Join(target, "object2", "object"), 0700), IsNil)
+
+	reportFile := filepath.Join(c.MkDir(), "report.json")
+	defer func() {
+		console.ExitStatus = 0
+		console.IsError = false
+	}()
+	err := app.Run([]string{os.Args[0], "cp", "--report", reportFile, source + "/...", target})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(console.ExitStatus, Equals, exitStatusPartial)
+
+	data, e := ioutil.ReadFile(reportFile)
+	c.Assert(e, IsNil)
+	var report RunReportMessage
+	c.Assert(json.Unmarshal(data, &report), IsNil)
+	c.Assert(report.Command, Equals, "cp")
+	c.Assert(report.Status, Equals, "partial")
+	c.Assert(report.TotalObjects, Equals, 2)
+	c.Assert(report.DoneObjects, Equals, 1)
+	c.Assert(report.DoneBytes, Equals, int64(len("object1")))
+	c.Assert(report.FailedObjects, Equals, 1)
+	c.Assert(isSession(report.SessionID), Equals, true)
+	// keys are kebab-case, the duration is in seconds.
+	c.Assert(strings.Contains(string(data), `"failed-objects":1`), Equals, true)
+	c.Assert(report.Duration >= 0 && report.Duration < 60, Equals, true)
+
+	// retrying the failed object skips the copied one.
+	console.ExitStatus = 0
+	c.Assert(os.RemoveAll(filepath.Join(target, "object2")), IsNil)
+	err = app.Run([]string{os.Args[0], "session", "retry", report.SessionID, "--failed-only", "--report", reportFile})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(console.ExitStatus, Equals, 0)
+
+	data, e = ioutil.ReadFile(reportFile)
+	c.Assert(e, IsNil)
+	report = RunReportMessage{}
+	c.Assert(json.Unmarshal(data, &report), IsNil)
+	c.Assert(report.Status, Equals, "success")
+	c.Assert(report.DoneObjects, Equals, 1)
+	c.Assert(report.SkippedObjects, Equals, 1)
+	c.Assert(report.FailedObjects, Equals, 0)
+	c.Assert(report.SessionID, Equals, "")
+}
@@ -0,0 +1,317 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Hosts whose keys are left as placeholders in config.json resolve their
+// credentials from, in order:
+//
+//  1. environment variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+//  2. shared credentials file ~/.aws/credentials (AWS_SHARED_CREDENTIALS_FILE)
+//  3. credential_process of the profile in the shared credentials file or
+//     in the shared config file ~/.aws/config (AWS_CONFIG_FILE)
+//
+// The profile is set via ‘--profile’, AWS_PROFILE or is ‘default’. Temporary
+// credentials carry a session token, AWS_SESSION_TOKEN or aws_session_token,
+// those of a command are renewed by running it again before they expire.
+var credentialsResolutionOrder = []string{
+	"config.json",
+	"environment (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)",
+	"shared credentials file (~/.aws/credentials)",
+	"credential_process",
+}
+
+// Sources of credentials.
+const (
+	credentialsSourceConfig  = "config"
+	credentialsSourceEnv     = "env"
+	credentialsSourceShared  = "shared-credentials"
+	credentialsSourceProcess = "credential-process"
+	credentialsSourceRefresh = "refresh-command"
+)
+
+// Temporary credentials are renewed when they expire within the window.
+const credentialsRefreshWindow = 5 * time.Minute
+
+type credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time // Zero for credentials which do not expire.
+	Source          string
+}
+
+// expiresSoon returns true if credentials have to be renewed before they are used.
+func (c credentials) expiresSoon() bool {
+	return !c.Expiration.IsZero() && time.Now().UTC().Add(credentialsRefreshWindow).After(c.Expiration)
+}
+
+// processCredentials is the output of a credential_process or refresh command.
+type processCredentials struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"` // RFC 3339, empty if the credentials do not expire.
+}
+
+// Resolved credentials per profile and per refresh command, commands are run
+// once per process unless their credentials expire.
+var (
+	credentialsCache      = make(map[string]credentials)
+	refreshCache          = make(map[string]credentials)
+	credentialsCacheMutex = &sync.Mutex{}
+)
+
+// isPlaceholderHostConfig returns true if the keys of a host were never filled in.
+func isPlaceholderHostConfig(hostCfg hostConfig) bool {
+	return hostCfg.AccessKeyID == globalAccessKeyID && hostCfg.SecretAccessKey == globalSecretAccessKey
+}
+
+// getCredentialsProfile returns the profile set via command line, AWS_PROFILE or ‘default’.
+func getCredentialsProfile() string {
+	if globalProfileFlag != "" {
+		return globalProfileFlag
+	}
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// getAWSFilePath returns the path of a shared AWS file, overridden by environment variable env.
+func getAWSFilePath(env, name string) (string, *probe.Error) {
+	if path := os.Getenv(env); path != "" {
+		return path, nil
+	}
+	u, e := user.Current()
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return filepath.Join(u.HomeDir, ".aws", name), nil
+}
+
+// parseINI parses sections of key value pairs, as used by the shared AWS files.
+func parseINI(r io.Reader) (map[string]map[string]string, *probe.Error) {
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[name]; !ok {
+				sections[name] = make(map[string]string)
+			}
+			section = sections[name]
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || section == nil {
+			return nil, probe.NewError(fmt.Errorf("Invalid line ‘%s’.", line))
+		}
+		section[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return sections, nil
+}
+
+// loadAWSProfile returns the keys of profile in the shared AWS file env or name,
+// a missing file or profile is not an error. Profiles of the config file are
+// named ‘profile NAME’ except for the default profile.
+func loadAWSProfile(env, name, profile string) (map[string]string, *probe.Error) {
+	path, err := getAWSFilePath(env, name)
+	if err != nil {
+		return nil, err.Trace(env)
+	}
+	file, e := os.Open(path)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil, nil
+		}
+		return nil, probe.NewError(e)
+	}
+	defer file.Close()
+	sections, err := parseINI(file)
+	if err != nil {
+		return nil, err.Trace(path)
+	}
+	if keys, ok := sections[profile]; ok {
+		return keys, nil
+	}
+	return sections["profile "+profile], nil
+}
+
+// runShellCommand runs command with the shell of the platform and returns what it prints.
+func runShellCommand(command string) ([]byte, *probe.Error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if e := cmd.Run(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return stdout.Bytes(), nil
+}
+
+// runCredentialProcess runs command and parses the credentials it prints.
+func runCredentialProcess(command string) (credentials, *probe.Error) {
+	stdout, err := runShellCommand(command)
+	if err != nil {
+		return credentials{}, err.Trace(command)
+	}
+	var output processCredentials
+	if e := json.Unmarshal(stdout, &output); e != nil {
+		return credentials{}, probe.NewError(e)
+	}
+	if output.Version != 1 {
+		return credentials{}, probe.NewError(fmt.Errorf("Unsupported credential_process output version %d.", output.Version))
+	}
+	if output.AccessKeyID == "" || output.SecretAccessKey == "" {
+		return credentials{}, probe.NewError(fmt.Errorf("credential_process returned empty keys."))
+	}
+	creds := credentials{
+		AccessKeyID:     output.AccessKeyID,
+		SecretAccessKey: output.SecretAccessKey,
+		SessionToken:    output.SessionToken,
+		Source:          credentialsSourceProcess,
+	}
+	if output.Expiration != "" {
+		expiration, e := time.Parse(time.RFC3339, output.Expiration)
+		if e != nil {
+			return credentials{}, probe.NewError(e)
+		}
+		creds.Expiration = expiration.UTC()
+	}
+	return creds, nil
+}
+
+// lookupCredentials finds the first source of credentials for profile. The
+// credential_process command is only returned, not run.
+func lookupCredentials(profile string) (creds credentials, command string, err *probe.Error) {
+	accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKeyID != "" && secretAccessKey != "" {
+		return credentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Source:          credentialsSourceEnv,
+		}, "", nil
+	}
+	shared, err := loadAWSProfile("AWS_SHARED_CREDENTIALS_FILE", "credentials", profile)
+	if err != nil {
+		return credentials{}, "", err.Trace(profile)
+	}
+	if shared["aws_access_key_id"] != "" && shared["aws_secret_access_key"] != "" {
+		return credentials{
+			AccessKeyID:     shared["aws_access_key_id"],
+			SecretAccessKey: shared["aws_secret_access_key"],
+			SessionToken:    shared["aws_session_token"],
+			Source:          credentialsSourceShared,
+		}, "", nil
+	}
+	if shared["credential_process"] != "" {
+		return credentials{Source: credentialsSourceProcess}, shared["credential_process"], nil
+	}
+	config, err := loadAWSProfile("AWS_CONFIG_FILE", "config", profile)
+	if err != nil {
+		return credentials{}, "", err.Trace(profile)
+	}
+	if config["credential_process"] != "" {
+		return credentials{Source: credentialsSourceProcess}, config["credential_process"], nil
+	}
+	return credentials{}, "", nil
+}
+
+// getCredentialsSource returns which source resolves credentials of placeholder hosts, without running any command.
+func getCredentialsSource() (string, *probe.Error) {
+	creds, _, err := lookupCredentials(getCredentialsProfile())
+	if err != nil {
+		return "", err.Trace()
+	}
+	return creds.Source, nil
+}
+
+// resolveCredentials resolves credentials of placeholder hosts for the current
+// profile, ok is false if no source provides any.
+func resolveCredentials() (creds credentials, ok bool, err *probe.Error) {
+	profile := getCredentialsProfile()
+
+	credentialsCacheMutex.Lock()
+	defer credentialsCacheMutex.Unlock()
+	if creds, ok := credentialsCache[profile]; ok && !creds.expiresSoon() {
+		return creds, true, nil
+	}
+	creds, command, err := lookupCredentials(profile)
+	if err != nil {
+		return credentials{}, false, err.Trace()
+	}
+	if command != "" {
+		if creds, err = runCredentialProcess(command); err != nil {
+			return credentials{}, false, err.Trace(profile)
+		}
+	}
+	if creds.Source == "" {
+		return credentials{}, false, nil
+	}
+	credentialsCache[profile] = creds
+	return creds, true, nil
+}
+
+// resolveRefreshCommand returns the credentials printed by the refresh command
+// of a host, the command is run again once they are about to expire.
+func resolveRefreshCommand(command string) (credentials, *probe.Error) {
+	credentialsCacheMutex.Lock()
+	defer credentialsCacheMutex.Unlock()
+	if creds, ok := refreshCache[command]; ok && !creds.expiresSoon() {
+		return creds, nil
+	}
+	creds, err := runCredentialProcess(command)
+	if err != nil {
+		return credentials{}, err.Trace()
+	}
+	creds.Source = credentialsSourceRefresh
+	refreshCache[command] = creds
+	return creds, nil
+}
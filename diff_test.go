@@ -41,13 +41,13 @@ func (s *TestSuite) TestDiffObjects(c *C) {
 	objectPath1 := filepath.Join(root1, "object1")
 	data := "hello"
 	dataLen := len(data)
-	perr := putTarget(objectPath1, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr := putTarget(objectPath1, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
 	objectPath2 := filepath.Join(root2, "object1")
 	data = "hello"
 	dataLen = len(data)
-	perr = putTarget(objectPath2, int64(dataLen), bytes.NewReader([]byte(data)))
+	perr = putTarget(objectPath2, "", int64(dataLen), bytes.NewReader([]byte(data)))
 	c.Assert(perr, IsNil)
 
 	for diff := range doDiff(objectPath1, objectPath2, false) {
@@ -70,7 +70,7 @@ func (s *TestSuite) TestDiffDirs(c *C) {
 		objectPath := filepath.Join(root1, "object"+strconv.Itoa(i))
 		data := "hello"
 		dataLen := len(data)
-		perr = putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+		perr = putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 		c.Assert(perr, IsNil)
 	}
 
@@ -78,7 +78,7 @@ func (s *TestSuite) TestDiffDirs(c *C) {
 		objectPath := filepath.Join(root2, "object"+strconv.Itoa(i))
 		data := "hello"
 		dataLen := len(data)
-		perr = putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+		perr = putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 		c.Assert(perr, IsNil)
 	}
 
@@ -0,0 +1,53 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// DryRunMessage container for the summary of a dry run
+type DryRunMessage struct {
+	Operation    string `json:"operation"`
+	TotalObjects int    `json:"total-objects"`
+	TotalBytes   int64  `json:"total-bytes"`
+}
+
+// String colorized dry run message
+func (d DryRunMessage) String() string {
+	switch d.Operation {
+	case "mirror":
+		return console.Colorize("Mirror", fmt.Sprintf("Dry run: %d object(s), %s would be mirrored.", d.TotalObjects, humanize.IBytes(uint64(d.TotalBytes))))
+	case "rm":
+		return console.Colorize("Remove", fmt.Sprintf("Dry run: %d object(s), %s would be removed.", d.TotalObjects, humanize.IBytes(uint64(d.TotalBytes))))
+	default:
+		return console.Colorize("Copy", fmt.Sprintf("Dry run: %d object(s), %s would be copied.", d.TotalObjects, humanize.IBytes(uint64(d.TotalBytes))))
+	}
+}
+
+// JSON jsonified dry run message
+func (d DryRunMessage) JSON() string {
+	dryRunMessageBytes, e := json.Marshal(d)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(dryRunMessageBytes)
+}
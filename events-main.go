@@ -0,0 +1,305 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Manage bucket event notifications.
+var eventsCmd = cli.Command{
+	Name:   "events",
+	Usage:  "Add, remove or list event notifications on a bucket or prefix.",
+	Action: mainEvents,
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} add TARGET ARN [EVENTS [SUFFIX]]
+   mc {{.Name}} remove TARGET [ARN]
+   mc {{.Name}} list TARGET [ARN]
+
+   Events on objects below the prefix of TARGET and ending in SUFFIX are sent to the queue (sqs), topic
+   (sns) or cloud function (lambda) identified by ARN. EVENTS is a comma separated list of S3 event types,
+   ‘put’, ‘delete’ and ‘get’ are short for all created, removed and accessed events. EVENTS defaults
+   to ‘put,delete’. Adding a notification with the same ARN, prefix and suffix replaces it.
+
+   ‘remove’ and ‘list’ apply to all notifications below the prefix of TARGET, restricted to ARN if given. {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}
+
+EXAMPLES:
+
+   1. Send created and removed events of objects below prefix "photos/" to a queue on Amazon S3 cloud storage.
+      $ mc {{.Name}} add https://s3.amazonaws.com/backup/photos/ arn:aws:sqs:us-east-1:444455556666:photos
+
+   2. Send created events of ".jpg" objects to a cloud function.
+      $ mc {{.Name}} add https://s3.amazonaws.com/backup arn:aws:lambda:us-east-1:444455556666:function:thumbnail put .jpg
+
+   3. List all event notifications of a bucket.
+      $ mc {{.Name}} list https://s3.amazonaws.com/backup
+
+   4. Remove the notifications sent to a queue.
+      $ mc {{.Name}} remove https://s3.amazonaws.com/backup arn:aws:sqs:us-east-1:444455556666:photos
+`,
+}
+
+// EventsMessage is container for events command success and failure messages
+type EventsMessage struct {
+	Operation string   `json:"operation"`
+	Status    string   `json:"status"`
+	URL       string   `json:"url"`
+	ID        string   `json:"id,omitempty"`
+	ARN       string   `json:"arn,omitempty"`
+	Events    []string `json:"events,omitempty"`
+	Prefix    string   `json:"prefix,omitempty"`
+	Suffix    string   `json:"suffix,omitempty"`
+	Removed   int      `json:"removed,omitempty"`
+}
+
+// String colorized events message
+func (s EventsMessage) String() string {
+	switch {
+	case s.Status == "dry-run" && s.Operation == "add":
+		return console.Colorize("Events", "Event notification would be added for ‘"+s.URL+"’ (dry run)")
+	case s.Status == "dry-run" && s.Operation == "remove":
+		return console.Colorize("Events", "Event notifications would be removed for ‘"+s.URL+"’ (dry run)")
+	case s.Operation == "add":
+		return console.Colorize("Events", "Event notification added successfully for ‘"+s.URL+"’")
+	case s.Operation == "remove" && s.Removed == 0:
+		return console.Colorize("Events", "No event notifications to remove for ‘"+s.URL+"’")
+	case s.Operation == "remove":
+		return console.Colorize("Events", "Event notifications removed successfully for ‘"+s.URL+"’")
+	case s.Operation == "list" && s.ID == "":
+		return console.Colorize("Events", "No event notifications for ‘"+s.URL+"’")
+	case s.Operation == "list":
+		return console.Colorize("Events", s.URL+"*"+s.Suffix+" => ["+s.ID+"] "+s.ARN+" ("+strings.Join(s.Events, ", ")+")")
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified events message
+func (s EventsMessage) JSON() string {
+	eventsJSONBytes, err := json.Marshal(s)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(eventsJSONBytes)
+}
+
+func checkEventsSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "events", 1) // last argument is exit code
+	}
+	args := ctx.Args().Tail()
+	switch ctx.Args().Get(0) {
+	case "add":
+		if len(args) < 2 || len(args) > 4 {
+			cli.ShowCommandHelpAndExit(ctx, "events", 1) // last argument is exit code
+		}
+		_, err := parseNotificationARN(args.Get(1))
+		fatalIf(err.Trace(args.Get(1)), "Unable to validate ARN ‘"+args.Get(1)+"’.")
+		if len(args) > 2 {
+			_, err = parseNotificationEvents(args.Get(2))
+			fatalIf(err.Trace(args.Get(2)), "Unable to parse events ‘"+args.Get(2)+"’.")
+		}
+	case "remove", "list":
+		if len(args) < 1 || len(args) > 2 {
+			cli.ShowCommandHelpAndExit(ctx, "events", 1) // last argument is exit code
+		}
+		if len(args) == 2 {
+			_, err := parseNotificationARN(args.Get(1))
+			fatalIf(err.Trace(args.Get(1)), "Unable to validate ARN ‘"+args.Get(1)+"’.")
+		}
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "events", 1) // last argument is exit code
+	}
+	if strings.TrimSpace(args.Get(0)) == "" {
+		fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+	}
+}
+
+func setEventsPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Events": color.New(color.FgGreen, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Events": color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainEvents(ctx *cli.Context) {
+	checkEventsSyntax(ctx)
+
+	setEventsPalette(ctx.GlobalString("colors"))
+
+	config := mustGetMcConfig()
+
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	args := ctx.Args().Tail()
+	targetURL := getAliasURL(args.Get(0), config.Aliases)
+	arn := args.Get(1)
+	switch ctx.Args().Get(0) {
+	case "add":
+		eventsStr := "put,delete"
+		if len(args) > 2 {
+			eventsStr = args.Get(2)
+		}
+		events, err := parseNotificationEvents(eventsStr)
+		fatalIf(err.Trace(eventsStr), "Unable to parse events ‘"+eventsStr+"’.")
+		suffix := args.Get(3)
+
+		n, err := doAddNotification(targetURL, arn, events, suffix)
+		fatalIf(err.Trace(targetURL), "Unable to add event notification for ‘"+targetURL+"’.")
+
+		Prints("%s\n", EventsMessage{
+			Operation: "add",
+			Status:    status,
+			URL:       targetURL,
+			ID:        n.ID,
+			ARN:       arn,
+			Events:    events,
+			Prefix:    n.prefix(),
+			Suffix:    suffix,
+		})
+	case "remove":
+		removed, err := doRemoveNotifications(targetURL, arn)
+		fatalIf(err.Trace(targetURL), "Unable to remove event notifications for ‘"+targetURL+"’.")
+
+		Prints("%s\n", EventsMessage{
+			Operation: "remove",
+			Status:    status,
+			URL:       targetURL,
+			ARN:       arn,
+			Removed:   removed,
+		})
+	case "list":
+		configs, err := doListNotifications(targetURL, arn)
+		fatalIf(err.Trace(targetURL), "Unable to list event notifications for ‘"+targetURL+"’.")
+
+		if len(configs) == 0 {
+			Prints("%s\n", EventsMessage{
+				Operation: "list",
+				Status:    "success",
+				URL:       targetURL,
+			})
+		}
+		bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+		for _, n := range configs {
+			Prints("%s\n", EventsMessage{
+				Operation: "list",
+				Status:    "success",
+				URL:       strings.TrimSuffix(bucketURL, "/") + "/" + n.prefix(),
+				ID:        n.ID,
+				ARN:       n.arn(),
+				Events:    n.Events,
+				Prefix:    n.prefix(),
+				Suffix:    n.suffix(),
+			})
+		}
+	}
+}
+
+// getBucketNotification - fetch and parse the notification configuration of the bucket targetURL belongs to
+func getBucketNotification(targetURL string) (notification notificationConfiguration, prefix string, err *probe.Error) {
+	bucketURL, _, prefix := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return notificationConfiguration{}, "", err.Trace(bucketURL)
+	}
+	notificationStr, err := clnt.GetBucketNotification()
+	if err != nil {
+		return notificationConfiguration{}, "", err.Trace(bucketURL)
+	}
+	notification, err = parseNotification(notificationStr)
+	if err != nil {
+		return notificationConfiguration{}, "", err.Trace(bucketURL)
+	}
+	return notification, prefix, nil
+}
+
+// putBucketNotification - replace the notification configuration of the bucket targetURL belongs to
+func putBucketNotification(targetURL string, notification notificationConfiguration) *probe.Error {
+	if globalDryRunFlag {
+		return nil
+	}
+	bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return err.Trace(bucketURL)
+	}
+	return clnt.SetBucketNotification(notification.String()).Trace(bucketURL)
+}
+
+// doAddNotification - send events below the prefix of targetURL ending in suffix to arn,
+// preserving all other notifications
+func doAddNotification(targetURL, arn string, events []string, suffix string) (notificationConfig, *probe.Error) {
+	notification, prefix, err := getBucketNotification(targetURL)
+	if err != nil {
+		return notificationConfig{}, err.Trace(targetURL)
+	}
+	n, err := newNotificationConfig(arn, events, prefix, suffix)
+	if err != nil {
+		return notificationConfig{}, err.Trace(arn)
+	}
+	return n, putBucketNotification(targetURL, notification.addConfig(n)).Trace(targetURL)
+}
+
+// doRemoveNotifications - remove the notifications below the prefix of targetURL, for arn if given
+func doRemoveNotifications(targetURL, arn string) (int, *probe.Error) {
+	notification, prefix, err := getBucketNotification(targetURL)
+	if err != nil {
+		return 0, err.Trace(targetURL)
+	}
+	notification, removed := notification.removeConfigs(arn, prefix)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, putBucketNotification(targetURL, notification).Trace(targetURL)
+}
+
+// doListNotifications - notifications below the prefix of targetURL, for arn if given
+func doListNotifications(targetURL, arn string) ([]notificationConfig, *probe.Error) {
+	notification, prefix, err := getBucketNotification(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	var configs []notificationConfig
+	for _, n := range notification.Configs {
+		if n.matches(arn, prefix) {
+			configs = append(configs, n)
+		}
+	}
+	return configs, nil
+}
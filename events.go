@@ -0,0 +1,217 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"strings"
+
+	"github.com/minio/minio/pkg/probe"
+)
+
+// notificationElement - configuration elements mc does not manage, preserved as is
+type notificationElement struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+type notificationFilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+type notificationFilter struct {
+	Rules []notificationFilterRule `xml:"S3Key>FilterRule"`
+}
+
+// notificationConfig - a queue, topic or cloud function configuration, the kind is kept in XMLName
+type notificationConfig struct {
+	XMLName       xml.Name
+	ID            string                `xml:"Id,omitempty"`
+	Queue         string                `xml:"Queue,omitempty"`
+	Topic         string                `xml:"Topic,omitempty"`
+	CloudFunction string                `xml:"CloudFunction,omitempty"`
+	Events        []string              `xml:"Event"`
+	Filter        *notificationFilter   `xml:"Filter"`
+	Extra         []notificationElement `xml:",any"`
+}
+
+// notificationConfiguration - S3 bucket notification configuration document
+type notificationConfiguration struct {
+	XMLName xml.Name             `xml:"NotificationConfiguration"`
+	Configs []notificationConfig `xml:",any"`
+}
+
+// short names for the most common event types
+var notificationEventNames = map[string]string{
+	"put":    "s3:ObjectCreated:*",
+	"delete": "s3:ObjectRemoved:*",
+	"get":    "s3:ObjectAccessed:*",
+}
+
+// parseNotification - parse a notification configuration, an empty document has no configurations
+func parseNotification(data string) (notificationConfiguration, *probe.Error) {
+	config := notificationConfiguration{}
+	if strings.TrimSpace(data) == "" {
+		return config, nil
+	}
+	if err := xml.Unmarshal([]byte(data), &config); err != nil {
+		return notificationConfiguration{}, probe.NewError(err)
+	}
+	return config, nil
+}
+
+// String - notification configuration in XML
+func (n notificationConfiguration) String() string {
+	notificationBytes, err := xml.Marshal(n)
+	fatalIf(probe.NewError(err), "Unable to marshal into XML.")
+	return string(notificationBytes)
+}
+
+// parseNotificationARN - validate an ARN of the form ‘arn:partition:service:region:account-id:resource’,
+// returns the name of the configuration element the service belongs to
+func parseNotificationARN(arn string) (string, *probe.Error) {
+	splits := strings.SplitN(arn, ":", 6)
+	if len(splits) != 6 || splits[0] != "arn" || splits[5] == "" {
+		return "", errInvalidARN(arn).Trace(arn)
+	}
+	switch splits[2] {
+	case "sqs":
+		return "QueueConfiguration", nil
+	case "sns":
+		return "TopicConfiguration", nil
+	case "lambda":
+		return "CloudFunctionConfiguration", nil
+	}
+	return "", errInvalidARN(arn).Trace(arn)
+}
+
+// parseNotificationEvents - comma separated event types, either short names or full S3 event types
+func parseNotificationEvents(eventsStr string) ([]string, *probe.Error) {
+	var events []string
+	for _, event := range strings.Split(eventsStr, ",") {
+		event = strings.TrimSpace(event)
+		if name, ok := notificationEventNames[strings.ToLower(event)]; ok {
+			event = name
+		}
+		if !strings.HasPrefix(event, "s3:") || len(event) == len("s3:") {
+			return nil, errInvalidArgument().Trace(eventsStr)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// notificationConfigID - identifier of the configurations generated by mc, unique per destination and filter
+func notificationConfigID(arn, prefix, suffix string) string {
+	sum := sha1.Sum([]byte(arn + "\x00" + prefix + "\x00" + suffix))
+	return "mc-" + hex.EncodeToString(sum[:8])
+}
+
+// newNotificationConfig - a configuration sending events for objects matching prefix and suffix to arn
+func newNotificationConfig(arn string, events []string, prefix, suffix string) (notificationConfig, *probe.Error) {
+	name, err := parseNotificationARN(arn)
+	if err != nil {
+		return notificationConfig{}, err.Trace(arn)
+	}
+	n := notificationConfig{
+		XMLName: xml.Name{Local: name},
+		ID:      notificationConfigID(arn, prefix, suffix),
+		Events:  events,
+	}
+	switch name {
+	case "QueueConfiguration":
+		n.Queue = arn
+	case "TopicConfiguration":
+		n.Topic = arn
+	default:
+		n.CloudFunction = arn
+	}
+	if prefix != "" || suffix != "" {
+		n.Filter = &notificationFilter{}
+		if prefix != "" {
+			n.Filter.Rules = append(n.Filter.Rules, notificationFilterRule{Name: "prefix", Value: prefix})
+		}
+		if suffix != "" {
+			n.Filter.Rules = append(n.Filter.Rules, notificationFilterRule{Name: "suffix", Value: suffix})
+		}
+	}
+	return n, nil
+}
+
+// arn - destination of a configuration
+func (n notificationConfig) arn() string {
+	switch {
+	case n.Queue != "":
+		return n.Queue
+	case n.Topic != "":
+		return n.Topic
+	}
+	return n.CloudFunction
+}
+
+func (n notificationConfig) filterRule(name string) string {
+	if n.Filter == nil {
+		return ""
+	}
+	for _, rule := range n.Filter.Rules {
+		if strings.EqualFold(rule.Name, name) {
+			return rule.Value
+		}
+	}
+	return ""
+}
+
+func (n notificationConfig) prefix() string {
+	return n.filterRule("prefix")
+}
+
+func (n notificationConfig) suffix() string {
+	return n.filterRule("suffix")
+}
+
+// matches - configurations below prefix, for arn if given
+func (n notificationConfig) matches(arn, prefix string) bool {
+	return strings.HasPrefix(n.prefix(), prefix) && (arn == "" || n.arn() == arn)
+}
+
+// addConfig - add a configuration, replacing the one with the same destination and filter
+func (n notificationConfiguration) addConfig(config notificationConfig) notificationConfiguration {
+	for i, c := range n.Configs {
+		if c.arn() == config.arn() && c.prefix() == config.prefix() && c.suffix() == config.suffix() {
+			n.Configs[i] = config
+			return n
+		}
+	}
+	n.Configs = append(n.Configs, config)
+	return n
+}
+
+// removeConfigs - remove all configurations below prefix, for arn if given
+func (n notificationConfiguration) removeConfigs(arn, prefix string) (notificationConfiguration, int) {
+	var configs []notificationConfig
+	for _, c := range n.Configs {
+		if !c.matches(arn, prefix) {
+			configs = append(configs, c)
+		}
+	}
+	removed := len(n.Configs) - len(configs)
+	n.Configs = configs
+	return n, removed
+}
@@ -0,0 +1,170 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+const (
+	testQueueARN    = "arn:aws:sqs:us-east-1:444455556666:photos"
+	testFunctionARN = "arn:aws:lambda:us-east-1:444455556666:function:thumbnail"
+)
+
+func (s *TestSuite) TestNotificationConfigs(c *C) {
+	_, perr := parseNotificationARN("arn:aws:s3:::bucket")
+	c.Assert(perr, Not(IsNil))
+	_, perr = parseNotificationARN("sqs")
+	c.Assert(perr, Not(IsNil))
+	name, perr := parseNotificationARN(testQueueARN)
+	c.Assert(perr, IsNil)
+	c.Assert(name, Equals, "QueueConfiguration")
+
+	events, perr := parseNotificationEvents("put,s3:ObjectRemoved:Delete")
+	c.Assert(perr, IsNil)
+	c.Assert(events, DeepEquals, []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:Delete"})
+	_, perr = parseNotificationEvents("put,invalid")
+	c.Assert(perr, Not(IsNil))
+
+	notification, perr := parseNotification("")
+	c.Assert(perr, IsNil)
+	c.Assert(notification.Configs, HasLen, 0)
+
+	queue, perr := newNotificationConfig(testQueueARN, events, "photos/", "")
+	c.Assert(perr, IsNil)
+	function, perr := newNotificationConfig(testFunctionARN, events[:1], "photos/", ".jpg")
+	c.Assert(perr, IsNil)
+	notification = notification.addConfig(queue).addConfig(function)
+
+	// round trip through XML
+	notification, perr = parseNotification(notification.String())
+	c.Assert(perr, IsNil)
+	c.Assert(notification.Configs, HasLen, 2)
+	c.Assert(notification.Configs[0].XMLName.Local, Equals, "QueueConfiguration")
+	c.Assert(notification.Configs[0].arn(), Equals, testQueueARN)
+	c.Assert(notification.Configs[0].prefix(), Equals, "photos/")
+	c.Assert(notification.Configs[0].suffix(), Equals, "")
+	c.Assert(notification.Configs[1].XMLName.Local, Equals, "CloudFunctionConfiguration")
+	c.Assert(notification.Configs[1].suffix(), Equals, ".jpg")
+	c.Assert(notification.Configs[1].ID, Not(Equals), notification.Configs[0].ID)
+
+	// configurations with the same destination and filter are replaced
+	queue, perr = newNotificationConfig(testQueueARN, events[:1], "photos/", "")
+	c.Assert(perr, IsNil)
+	notification = notification.addConfig(queue)
+	c.Assert(notification.Configs, HasLen, 2)
+	c.Assert(notification.Configs[0].Events, HasLen, 1)
+
+	notification, removed := notification.removeConfigs(testFunctionARN, "")
+	c.Assert(removed, Equals, 1)
+	notification, removed = notification.removeConfigs("", "videos/")
+	c.Assert(removed, Equals, 0)
+	c.Assert(notification.Configs, HasLen, 1)
+
+	// elements not managed by mc are preserved
+	notification, perr = parseNotification(`<NotificationConfiguration><TopicConfiguration><Id>other</Id><Topic>arn:aws:sns:us-east-1:444455556666:topic</Topic><Event>s3:ReducedRedundancyLostObject</Event><Extra>value</Extra></TopicConfiguration></NotificationConfiguration>`)
+	c.Assert(perr, IsNil)
+	notification, perr = parseNotification(notification.addConfig(queue).String())
+	c.Assert(perr, IsNil)
+	c.Assert(notification.Configs, HasLen, 2)
+	c.Assert(notification.Configs[0].ID, Equals, "other")
+	c.Assert(notification.Configs[0].Extra, HasLen, 1)
+	c.Assert(notification.Configs[0].Extra[0].XMLName.Local, Equals, "Extra")
+
+	_, perr = parseNotification("invalid")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestEventsServer(c *C) {
+	s3v4Server.MakeBucket("events-bucket", "private")
+
+	n, perr := doAddNotification(s3v4Server.URL+"/events-bucket/photos/", testQueueARN, []string{"s3:ObjectCreated:*"}, "")
+	c.Assert(perr, IsNil)
+	c.Assert(n.prefix(), Equals, "photos/")
+	_, perr = doAddNotification(s3v4Server.URL+"/events-bucket/photos/", testFunctionARN, []string{"s3:ObjectRemoved:*"}, ".jpg")
+	c.Assert(perr, IsNil)
+	_, perr = doAddNotification(s3v4Server.URL+"/events-bucket/videos/", testQueueARN, []string{"s3:ObjectRemoved:*"}, "")
+	c.Assert(perr, IsNil)
+
+	configs, perr := doListNotifications(s3v4Server.URL+"/events-bucket", "")
+	c.Assert(perr, IsNil)
+	c.Assert(configs, HasLen, 3)
+	configs, perr = doListNotifications(s3v4Server.URL+"/events-bucket/photos/", testFunctionARN)
+	c.Assert(perr, IsNil)
+	c.Assert(configs, HasLen, 1)
+	c.Assert(configs[0].suffix(), Equals, ".jpg")
+
+	// overlapping configurations are rejected by the server
+	_, perr = doAddNotification(s3v4Server.URL+"/events-bucket/photos/2015/", testQueueARN, []string{"s3:ObjectCreated:Put"}, "")
+	c.Assert(perr, Not(IsNil))
+
+	globalDryRunFlag = true
+	removed, perr := doRemoveNotifications(s3v4Server.URL+"/events-bucket/photos/", "")
+	globalDryRunFlag = false
+	c.Assert(perr, IsNil)
+	c.Assert(removed, Equals, 2)
+	configs, perr = doListNotifications(s3v4Server.URL+"/events-bucket", "")
+	c.Assert(perr, IsNil)
+	c.Assert(configs, HasLen, 3)
+
+	removed, perr = doRemoveNotifications(s3v4Server.URL+"/events-bucket/photos/", "")
+	c.Assert(perr, IsNil)
+	c.Assert(removed, Equals, 2)
+	removed, perr = doRemoveNotifications(s3v4Server.URL+"/events-bucket", testQueueARN)
+	c.Assert(perr, IsNil)
+	c.Assert(removed, Equals, 1)
+	configs, perr = doListNotifications(s3v4Server.URL+"/events-bucket", "")
+	c.Assert(perr, IsNil)
+	c.Assert(configs, HasLen, 0)
+
+	// signature V2 and filesystem targets do not support event notifications
+	_, perr = doAddNotification(s3v2Server.URL+"/events-bucket", testQueueARN, []string{"s3:ObjectCreated:*"}, "")
+	c.Assert(perr, Not(IsNil))
+	_, perr = doListNotifications(c.MkDir(), "")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestEventsContext(c *C) {
+	console.IsExited = false
+
+	s3v4Server.MakeBucket("events-context", "private")
+	err := app.Run([]string{os.Args[0], "events", "add", s3v4Server.URL + "/events-context/photos/", testQueueARN})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "events", "add", s3v4Server.URL + "/events-context/videos/", testFunctionARN, "put", ".mp4"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "events", "list", s3v4Server.URL + "/events-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "events", "remove", s3v4Server.URL + "/events-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "events", "add", s3v4Server.URL + "/events-context", "arn:aws:s3:::invalid"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}
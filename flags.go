@@ -54,6 +54,16 @@ var (
 		Usage: "Enable debugging output.",
 	}
 
+	dryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the planned operations without modifying any target.",
+	}
+
+	profileFlag = cli.StringFlag{
+		Name:  "profile",
+		Usage: "Profile of the shared credentials file for hosts without keys.",
+	}
+
 	colorsFlag = cli.StringFlag{
 		Name:  "colors",
 		Value: "dark",
@@ -63,6 +73,116 @@ var (
 	// Add your new flags starting here
 )
 
+// Flags of individual commands, set on the Flags of the commands using them.
+var (
+	versionsFlag = cli.BoolFlag{
+		Name:  "versions",
+		Usage: "List all versions of objects, including delete markers.",
+	}
+
+	versionIDFlag = cli.StringFlag{
+		Name:  "version-id",
+		Usage: "Select a specific version of the source object.",
+	}
+
+	tagsFlag = cli.StringFlag{
+		Name:  "tags",
+		Usage: "Tag written objects, for example ‘team=storage&retention=30d’.",
+	}
+
+	fromFileFlag = cli.StringFlag{
+		Name:  "from-file",
+		Usage: "Read source URLs from a file, one per line, ‘-’ reads from stdin.",
+	}
+
+	reportFlag = cli.StringFlag{
+		Name:  "report",
+		Usage: "Write the JSON summary of the run to a file.",
+	}
+
+	storageClassFlag = cli.StringFlag{
+		Name:  "storage-class",
+		Usage: "Storage class of written objects, for example ‘REDUCED_REDUNDANCY’.",
+	}
+
+	methodFlag = cli.StringFlag{
+		Name:  "method",
+		Usage: "Upload method, ‘post’ for a form upload or ‘put’ for a presigned URL.",
+		Value: "post",
+	}
+
+	contentDispositionFlag = cli.StringFlag{
+		Name:  "content-disposition",
+		Usage: "Content-Disposition of responses, ‘attachment’ forces a download with the object name.",
+	}
+
+	contentTypeFlag = cli.StringFlag{
+		Name:  "content-type",
+		Usage: "Content-Type of responses.",
+	}
+
+	formatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Print URLs as a single index. Available options are [‘csv’, ‘json’, ‘html’]",
+	}
+
+	expiredFlag = cli.BoolFlag{
+		Name:  "expired",
+		Usage: "List only shared URLs which have expired.",
+	}
+
+	activeFlag = cli.BoolFlag{
+		Name:  "active",
+		Usage: "List only shared URLs which have not expired yet.",
+	}
+
+	listenFlag = cli.StringFlag{
+		Name:  "listen",
+		Value: ":8080",
+		Usage: "Address the embedded HTTP server listens on.",
+	}
+
+	failedOnlyFlag = cli.BoolFlag{
+		Name:  "failed-only",
+		Usage: "Transfer only the failed objects of a session.",
+	}
+
+	olderThanFlag = cli.StringFlag{
+		Name:  "older-than",
+		Usage: "Clear only sessions older than NN[d|h|m|s].",
+	}
+
+	sessionExpiryFlag = cli.StringFlag{
+		Name:  "session-expiry",
+		Usage: "Clear sessions abandoned for longer than NN[d|h|m|s] before starting.",
+	}
+
+	secretStoreFlag = cli.BoolFlag{
+		Name:  "secret-store",
+		Usage: "Encrypt the secret access key and session token with a master passphrase.",
+	}
+
+	secretCommandFlag = cli.StringFlag{
+		Name:  "secret-command",
+		Usage: "Read the secret access key from the output of a command.",
+	}
+
+	sessionTokenFlag = cli.StringFlag{
+		Name:  "session-token",
+		Usage: "Session token of temporary credentials.",
+	}
+
+	refreshCommandFlag = cli.StringFlag{
+		Name:  "refresh-command",
+		Usage: "Renew temporary credentials with the output of a command.",
+	}
+
+	recursiveFlag = cli.BoolFlag{
+		Name:  "recursive",
+		Usage: "Watch all subfolders, same as a trailing ‘...’ on the target.",
+	}
+)
+
 // registerCmd registers a cli command
 func registerCmd(cmd cli.Command) {
 	commands = append(commands, cmd)
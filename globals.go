@@ -18,10 +18,13 @@
 package main
 
 var (
-	globalQuietFlag = false // Quiet flag set via command line
-	globalMimicFlag = false // Unix flag set via command line
-	globalJSONFlag  = false // Json flag set via command line
-	globalDebugFlag = false // Debug flag set via command line
+	globalQuietFlag  = false // Quiet flag set via command line
+	globalMimicFlag  = false // Unix flag set via command line
+	globalJSONFlag   = false // Json flag set via command line
+	globalDebugFlag  = false // Debug flag set via command line
+	globalDryRunFlag = false // Dry run flag set via command line
+
+	globalProfileFlag = "" // Shared credentials profile set via command line
 )
 
 // mc configuration related constants.
@@ -32,6 +35,7 @@ const (
 	globalMCConfigDir        = ".mc/"
 	globalMCConfigWindowsDir = "mc\\"
 	globalMCConfigFile       = "config.json"
+	globalSecretsFile        = "secrets.json"
 
 	// session config and shared urls related constants
 	globalSessionDir        = "session"
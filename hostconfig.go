@@ -27,6 +27,13 @@ type hostConfig struct {
 	AccessKeyID     string `json:"accessKeyId"`
 	SecretAccessKey string `json:"secretAccessKey"`
 	API             string `json:"api"`
+	SecretRef       string `json:"secretRef,omitempty"`       // Secret access key kept out of config, see secrets.go.
+	SessionToken    string `json:"sessionToken,omitempty"`    // Session token of temporary credentials.
+	SessionTokenRef string `json:"sessionTokenRef,omitempty"` // Session token kept out of config, see secrets.go.
+	RefreshCommand  string `json:"refreshCommand,omitempty"`  // Prints temporary credentials, see credentials.go.
+
+	// refresh renews temporary credentials resolved from a command, nil for keys which do not expire.
+	refresh func() (credentials, *probe.Error)
 }
 
 // getHostConfig retrieves host specific configuration such as access keys, certs.
@@ -46,7 +53,7 @@ func getHostConfig(URL string) (hostConfig, *probe.Error) {
 		return hostCfg, nil
 	}
 	if _, ok := config.Hosts[url.Host]; ok {
-		return config.Hosts[url.Host], nil
+		return resolveHostConfig(config.Hosts[url.Host])
 	}
 	for globURL, hostCfg := range config.Hosts {
 		match, err := filepath.Match(globURL, url.Host)
@@ -54,8 +61,64 @@ func getHostConfig(URL string) (hostConfig, *probe.Error) {
 			return hostConfig{}, errInvalidGlobURL(globURL, URL).Trace()
 		}
 		if match {
-			return hostCfg, nil
+			return resolveHostConfig(hostCfg)
 		}
 	}
 	return hostConfig{}, errNoMatchingHost(URL).Trace()
 }
+
+// resolveHostConfig fills in the temporary credentials of a refresh command,
+// the secret access key and session token of a host kept out of config, or keys left as
+// placeholders from environment, shared credentials file or credential_process.
+func resolveHostConfig(hostCfg hostConfig) (hostConfig, *probe.Error) {
+	if hostCfg.RefreshCommand != "" {
+		command := hostCfg.RefreshCommand
+		creds, err := resolveRefreshCommand(command)
+		if err != nil {
+			return hostConfig{}, err.Trace(command)
+		}
+		hostCfg.AccessKeyID = creds.AccessKeyID
+		hostCfg.SecretAccessKey = creds.SecretAccessKey
+		hostCfg.SessionToken = creds.SessionToken
+		if !creds.Expiration.IsZero() {
+			hostCfg.refresh = func() (credentials, *probe.Error) {
+				return resolveRefreshCommand(command)
+			}
+		}
+		return hostCfg, nil
+	}
+	if hostCfg.SessionTokenRef != "" {
+		sessionToken, err := resolveSecretRef(hostCfg.SessionTokenRef)
+		if err != nil {
+			return hostConfig{}, err.Trace(hostCfg.SessionTokenRef)
+		}
+		hostCfg.SessionToken = sessionToken
+	}
+	if hostCfg.SecretRef != "" {
+		secretAccessKey, err := resolveSecretRef(hostCfg.SecretRef)
+		if err != nil {
+			return hostConfig{}, err.Trace(hostCfg.SecretRef)
+		}
+		hostCfg.SecretAccessKey = secretAccessKey
+		return hostCfg, nil
+	}
+	if !isPlaceholderHostConfig(hostCfg) {
+		return hostCfg, nil
+	}
+	creds, ok, err := resolveCredentials()
+	if err != nil {
+		return hostConfig{}, err.Trace()
+	}
+	if ok {
+		hostCfg.AccessKeyID = creds.AccessKeyID
+		hostCfg.SecretAccessKey = creds.SecretAccessKey
+		hostCfg.SessionToken = creds.SessionToken
+		if !creds.Expiration.IsZero() {
+			hostCfg.refresh = func() (credentials, *probe.Error) {
+				creds, _, err := resolveCredentials()
+				return creds, err
+			}
+		}
+	}
+	return hostCfg, nil
+}
@@ -0,0 +1,427 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Manage bucket lifecycle configuration.
+var lifecycleCmd = cli.Command{
+	Name:   "lifecycle",
+	Usage:  "Set, get or remove object expiration rules on a bucket or prefix, apply them on a folder.",
+	Action: mainLifecycle,
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set TARGET EXPIRY-DAYS [ABORT-INCOMPLETE-DAYS]
+   mc {{.Name}} get TARGET [TARGET ...]
+   mc {{.Name}} remove TARGET [TARGET ...]
+   mc {{.Name}} apply FOLDER EXPIRY-DAYS | RULES-URL
+
+   Objects below the prefix of TARGET expire EXPIRY-DAYS after they were last modified, incomplete
+   multipart uploads are aborted ABORT-INCOMPLETE-DAYS after they were initiated. Zero disables either
+   rule. Removing a bucket removes its entire lifecycle configuration.
+
+   Cloud storage enforces lifecycle rules on its own. Folders have no lifecycle configuration, ‘apply’
+   removes the files whose modification time is older than EXPIRY-DAYS, or enforces the expiration rules
+   of the bucket at RULES-URL with prefixes relative to FOLDER. Abort rules have no meaning on a folder
+   and are ignored. {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}
+
+EXAMPLES:
+
+   1. Expire objects below prefix "logs/" after 30 days on Amazon S3 cloud storage.
+      $ mc {{.Name}} set https://s3.amazonaws.com/backup/logs/ 30
+
+   2. Abort incomplete uploads after 7 days in the entire bucket, without expiring any objects.
+      $ mc {{.Name}} set https://play.minio.io:9000/mybucket 0 7
+
+   3. Get the lifecycle rules of a bucket.
+      $ mc {{.Name}} get https://s3.amazonaws.com/backup
+
+   4. Remove the rules of prefix "logs/".
+      $ mc {{.Name}} remove https://s3.amazonaws.com/backup/logs/
+
+   5. Remove files older than 30 days from a local folder.
+      $ mc {{.Name}} apply /var/log/archive 30
+
+   6. Enforce the expiration rules of a bucket on its local mirror.
+      $ mc {{.Name}} apply /mnt/backup https://s3.amazonaws.com/backup
+`,
+}
+
+// LifecycleMessage is container for lifecycle command success and failure messages
+type LifecycleMessage struct {
+	Operation           string `json:"operation"`
+	Status              string `json:"status"`
+	URL                 string `json:"url"`
+	ID                  string `json:"id,omitempty"`
+	RuleStatus          string `json:"ruleStatus,omitempty"`
+	ExpiryDays          int    `json:"expiryDays,omitempty"`
+	ExpiryDate          string `json:"expiryDate,omitempty"`
+	AbortIncompleteDays int    `json:"abortIncompleteDays,omitempty"`
+}
+
+// String colorized lifecycle message
+func (s LifecycleMessage) String() string {
+	switch {
+	case s.Status == "dry-run" && s.Operation == "set":
+		return console.Colorize("Lifecycle", "Lifecycle rule would be set for ‘"+s.URL+"’ (dry run)")
+	case s.Status == "dry-run" && s.Operation == "remove":
+		return console.Colorize("Lifecycle", "Lifecycle rules would be removed for ‘"+s.URL+"’ (dry run)")
+	case s.Status == "dry-run" && s.Operation == "apply":
+		return console.Colorize("Lifecycle", "‘"+s.URL+"’ would be removed, expired by rule ‘"+s.ID+"’ (dry run)")
+	case s.Operation == "set":
+		return console.Colorize("Lifecycle", "Lifecycle rule set successfully for ‘"+s.URL+"’")
+	case s.Operation == "remove":
+		return console.Colorize("Lifecycle", "Lifecycle rules removed successfully for ‘"+s.URL+"’")
+	case s.Operation == "apply":
+		return console.Colorize("Lifecycle", "Removed ‘"+s.URL+"’, expired by rule ‘"+s.ID+"’")
+	case s.Operation == "get" && s.ID == "":
+		return console.Colorize("Lifecycle", "No lifecycle rules for ‘"+s.URL+"’")
+	case s.Operation == "get":
+		var actions []string
+		if s.ExpiryDays > 0 {
+			actions = append(actions, fmt.Sprintf("expire after %d days", s.ExpiryDays))
+		}
+		if s.ExpiryDate != "" {
+			actions = append(actions, "expire on "+s.ExpiryDate)
+		}
+		if s.AbortIncompleteDays > 0 {
+			actions = append(actions, fmt.Sprintf("abort incomplete uploads after %d days", s.AbortIncompleteDays))
+		}
+		if len(actions) == 0 {
+			actions = append(actions, "other actions")
+		}
+		return console.Colorize("Lifecycle", s.URL+" => ["+s.ID+"] "+strings.Join(actions, ", ")+" ("+strings.ToLower(s.RuleStatus)+")")
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified lifecycle message
+func (s LifecycleMessage) JSON() string {
+	lifecycleJSONBytes, err := json.Marshal(s)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(lifecycleJSONBytes)
+}
+
+// parseLifecycleDays - number of days, zero disables a rule
+func parseLifecycleDays(arg string) (int, bool) {
+	days, err := strconv.Atoi(arg)
+	if err != nil || days < 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+func checkLifecycleSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "lifecycle", 1) // last argument is exit code
+	}
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "lifecycle", 1) // last argument is exit code
+	}
+	targets := ctx.Args().Tail()
+	switch ctx.Args().Get(0) {
+	case "set":
+		if len(targets) < 2 || len(targets) > 3 {
+			cli.ShowCommandHelpAndExit(ctx, "lifecycle", 1) // last argument is exit code
+		}
+		expiryDays, ok := parseLifecycleDays(targets.Get(1))
+		if !ok {
+			fatalIf(errInvalidArgument().Trace(targets.Get(1)), "Unable to parse expiry days ‘"+targets.Get(1)+"’.")
+		}
+		abortDays := 0
+		if len(targets) == 3 {
+			if abortDays, ok = parseLifecycleDays(targets.Get(2)); !ok {
+				fatalIf(errInvalidArgument().Trace(targets.Get(2)), "Unable to parse abort incomplete days ‘"+targets.Get(2)+"’.")
+			}
+		}
+		if expiryDays == 0 && abortDays == 0 {
+			fatalIf(errInvalidArgument().Trace(), "A rule needs either expiry days or abort incomplete days, use ‘remove’ to remove rules.")
+		}
+		targets = targets[:1]
+	case "apply":
+		if len(targets) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "lifecycle", 1) // last argument is exit code
+		}
+		if client.NewURL(targets.Get(0)).Type != client.Filesystem {
+			fatalIf(errInvalidArgument().Trace(targets.Get(0)),
+				"Lifecycle rules can only be applied on local folders, cloud storage enforces them on its own.")
+		}
+	case "get", "remove":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "lifecycle", 1) // last argument is exit code
+	}
+	for _, arg := range targets {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+func setLifecyclePalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Lifecycle": color.New(color.FgGreen, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Lifecycle": color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainLifecycle(ctx *cli.Context) {
+	checkLifecycleSyntax(ctx)
+
+	setLifecyclePalette(ctx.GlobalString("colors"))
+
+	config := mustGetMcConfig()
+
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	args := ctx.Args().Tail()
+	switch ctx.Args().Get(0) {
+	case "set":
+		targetURL := getAliasURL(args.Get(0), config.Aliases)
+		expiryDays, _ := parseLifecycleDays(args.Get(1))
+		abortDays, _ := parseLifecycleDays(args.Get(2))
+		fatalIf(doSetLifecycle(targetURL, expiryDays, abortDays).Trace(targetURL), "Unable to set lifecycle rule for ‘"+targetURL+"’.")
+
+		Prints("%s\n", LifecycleMessage{
+			Operation:           "set",
+			Status:              status,
+			URL:                 targetURL,
+			ExpiryDays:          expiryDays,
+			AbortIncompleteDays: abortDays,
+		})
+	case "remove":
+		for _, arg := range args {
+			targetURL := getAliasURL(arg, config.Aliases)
+			fatalIf(doRemoveLifecycle(targetURL).Trace(targetURL), "Unable to remove lifecycle rules for ‘"+targetURL+"’.")
+
+			Prints("%s\n", LifecycleMessage{
+				Operation: "remove",
+				Status:    status,
+				URL:       targetURL,
+			})
+		}
+	case "get":
+		for _, arg := range args {
+			targetURL := getAliasURL(arg, config.Aliases)
+			rules, err := doGetLifecycle(targetURL)
+			fatalIf(err.Trace(targetURL), "Unable to get lifecycle rules for ‘"+targetURL+"’.")
+
+			if len(rules) == 0 {
+				Prints("%s\n", LifecycleMessage{
+					Operation: "get",
+					Status:    "success",
+					URL:       targetURL,
+				})
+			}
+			bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+			for _, rule := range rules {
+				msg := LifecycleMessage{
+					Operation:           "get",
+					Status:              "success",
+					URL:                 strings.TrimSuffix(bucketURL, "/") + "/" + rule.prefix(),
+					ID:                  rule.ID,
+					RuleStatus:          rule.Status,
+					ExpiryDays:          rule.expiryDays(),
+					AbortIncompleteDays: rule.abortIncompleteDays(),
+				}
+				if rule.Expiration != nil {
+					msg.ExpiryDate = rule.Expiration.Date
+				}
+				Prints("%s\n", msg)
+			}
+		}
+	case "apply":
+		folder := args.Get(0)
+		rules, err := lifecycleApplyRules(getAliasURL(args.Get(1), config.Aliases))
+		fatalIf(err.Trace(args.Get(1)), "Unable to get lifecycle rules from ‘"+args.Get(1)+"’.")
+
+		err = doApplyLifecycle(folder, rules, time.Now().UTC(), func(objectURL string, rule lifecycleRule, err *probe.Error) {
+			if err != nil {
+				errorIf(err.Trace(objectURL), "Unable to remove expired ‘"+objectURL+"’.")
+				return
+			}
+			Prints("%s\n", LifecycleMessage{
+				Operation: "apply",
+				Status:    status,
+				URL:       objectURL,
+				ID:        rule.ID,
+			})
+		})
+		fatalIf(err.Trace(folder), "Unable to apply lifecycle rules on ‘"+folder+"’.")
+	}
+}
+
+// getBucketLifecycle - fetch and parse the lifecycle configuration of the bucket targetURL belongs to
+func getBucketLifecycle(targetURL string) (lifecycle lifecycleConfiguration, prefix string, err *probe.Error) {
+	bucketURL, _, prefix := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return lifecycleConfiguration{}, "", err.Trace(bucketURL)
+	}
+	lifecycleStr, err := clnt.GetBucketLifecycle()
+	if err != nil {
+		return lifecycleConfiguration{}, "", err.Trace(bucketURL)
+	}
+	lifecycle, err = parseLifecycle(lifecycleStr)
+	if err != nil {
+		return lifecycleConfiguration{}, "", err.Trace(bucketURL)
+	}
+	return lifecycle, prefix, nil
+}
+
+// putBucketLifecycle - replace the lifecycle configuration of the bucket targetURL belongs to,
+// a configuration without rules is removed
+func putBucketLifecycle(targetURL string, lifecycle lifecycleConfiguration) *probe.Error {
+	if globalDryRunFlag {
+		return nil
+	}
+	bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return err.Trace(bucketURL)
+	}
+	if len(lifecycle.Rules) == 0 {
+		return clnt.RemoveBucketLifecycle().Trace(bucketURL)
+	}
+	return clnt.SetBucketLifecycle(lifecycle.String()).Trace(bucketURL)
+}
+
+// doSetLifecycle - set the rule for the prefix of targetURL, preserving all other rules
+func doSetLifecycle(targetURL string, expiryDays, abortDays int) *probe.Error {
+	lifecycle, prefix, err := getBucketLifecycle(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	return putBucketLifecycle(targetURL, lifecycle.setPrefixRule(prefix, expiryDays, abortDays)).Trace(targetURL)
+}
+
+// doRemoveLifecycle - remove the rules for the prefix of targetURL, the whole configuration for a bucket
+func doRemoveLifecycle(targetURL string) *probe.Error {
+	lifecycle, prefix, err := getBucketLifecycle(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if prefix == "" {
+		lifecycle = lifecycleConfiguration{}
+	}
+	return putBucketLifecycle(targetURL, lifecycle.removePrefixRules(prefix)).Trace(targetURL)
+}
+
+// doGetLifecycle - rules for all prefixes starting with the prefix of targetURL
+func doGetLifecycle(targetURL string) ([]lifecycleRule, *probe.Error) {
+	lifecycle, prefix, err := getBucketLifecycle(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	var rules []lifecycleRule
+	for _, rule := range lifecycle.Rules {
+		if strings.HasPrefix(rule.prefix(), prefix) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// lifecycleApplyRules - a single rule expiring everything after a number of days, or the rules of a bucket
+func lifecycleApplyRules(arg string) (lifecycleConfiguration, *probe.Error) {
+	if days, ok := parseLifecycleDays(arg); ok {
+		return lifecycleConfiguration{}.setPrefixRule("", days, 0), nil
+	}
+	if client.NewURL(arg).Type != client.Object {
+		return lifecycleConfiguration{}, errInvalidArgument().Trace(arg)
+	}
+	lifecycle, _, err := getBucketLifecycle(arg)
+	if err != nil {
+		return lifecycleConfiguration{}, err.Trace(arg)
+	}
+	return lifecycle, nil
+}
+
+// doApplyLifecycle - remove the files below folder expired by the lifecycle rules at time now, file paths
+// relative to folder are matched against the rule prefixes. expiredFunc is called for every expired file.
+func doApplyLifecycle(folder string, lifecycle lifecycleConfiguration, now time.Time,
+	expiredFunc func(objectURL string, rule lifecycleRule, err *probe.Error)) *probe.Error {
+	clnt, content, err := url2Stat(folder)
+	if err != nil {
+		return err.Trace(folder)
+	}
+	if !content.Type.IsDir() {
+		return errSourceIsNotDir(folder).Trace(folder)
+	}
+	separator := string(clnt.URL().Separator)
+	folder = strings.TrimSuffix(folder, separator) + separator
+	if clnt, err = url2Client(folder); err != nil {
+		return err.Trace(folder)
+	}
+	for entry := range clnt.List(true) {
+		if entry.Err != nil {
+			return entry.Err.Trace(folder)
+		}
+		if !entry.Content.Type.IsRegular() {
+			continue
+		}
+		rule := lifecycle.expiredBy(filepath.ToSlash(entry.Content.Name), entry.Content.Time, now)
+		if rule == nil {
+			continue
+		}
+		objectURL := folder + entry.Content.Name
+		if globalDryRunFlag {
+			expiredFunc(objectURL, *rule, nil)
+			continue
+		}
+		objectClnt, err := url2Client(objectURL)
+		if err == nil {
+			err = objectClnt.Remove()
+		}
+		if err != nil {
+			expiredFunc(objectURL, *rule, err.Trace(objectURL))
+			continue
+		}
+		expiredFunc(objectURL, *rule, nil)
+	}
+	return nil
+}
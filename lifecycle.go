@@ -0,0 +1,203 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/probe"
+)
+
+// lifecycleElement - rule elements mc does not manage, preserved as is
+type lifecycleElement struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+type lifecycleFilter struct {
+	Prefix string             `xml:"Prefix"`
+	Extra  []lifecycleElement `xml:",any"`
+}
+
+type lifecycleExpiration struct {
+	Days                      int    `xml:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker string `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+type lifecycleAbortIncomplete struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// lifecycleRule - a single lifecycle rule, the prefix is either a top level element or part of a filter
+type lifecycleRule struct {
+	ID                             string                    `xml:"ID,omitempty"`
+	Prefix                         *string                   `xml:"Prefix"`
+	Filter                         *lifecycleFilter          `xml:"Filter"`
+	Status                         string                    `xml:"Status"`
+	Expiration                     *lifecycleExpiration      `xml:"Expiration"`
+	AbortIncompleteMultipartUpload *lifecycleAbortIncomplete `xml:"AbortIncompleteMultipartUpload"`
+	Extra                          []lifecycleElement        `xml:",any"`
+}
+
+// lifecycleConfiguration - S3 bucket lifecycle configuration document
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+// parseLifecycle - parse a lifecycle configuration, an empty document has no rules
+func parseLifecycle(data string) (lifecycleConfiguration, *probe.Error) {
+	config := lifecycleConfiguration{}
+	if strings.TrimSpace(data) == "" {
+		return config, nil
+	}
+	if err := xml.Unmarshal([]byte(data), &config); err != nil {
+		return lifecycleConfiguration{}, probe.NewError(err)
+	}
+	return config, nil
+}
+
+// String - lifecycle configuration in XML
+func (l lifecycleConfiguration) String() string {
+	lifecycleBytes, err := xml.Marshal(l)
+	fatalIf(probe.NewError(err), "Unable to marshal into XML.")
+	return string(lifecycleBytes)
+}
+
+func (r lifecycleRule) prefix() string {
+	if r.Filter != nil {
+		return r.Filter.Prefix
+	}
+	if r.Prefix != nil {
+		return *r.Prefix
+	}
+	return ""
+}
+
+// isPrefixRule - rules selecting objects only by prefix, rules with tag filters are never touched
+func (r lifecycleRule) isPrefixRule() bool {
+	return r.Filter == nil || len(r.Filter.Extra) == 0
+}
+
+func (r lifecycleRule) expiryDays() int {
+	if r.Expiration == nil {
+		return 0
+	}
+	return r.Expiration.Days
+}
+
+func (r lifecycleRule) abortIncompleteDays() int {
+	if r.AbortIncompleteMultipartUpload == nil {
+		return 0
+	}
+	return r.AbortIncompleteMultipartUpload.DaysAfterInitiation
+}
+
+// hasActions - a rule without any action is rejected by S3
+func (r lifecycleRule) hasActions() bool {
+	return r.Expiration != nil || r.AbortIncompleteMultipartUpload != nil || len(r.Extra) > 0
+}
+
+// isExpired - is an object last modified at modTime expired by this rule at time now
+func (r lifecycleRule) isExpired(key string, modTime, now time.Time) bool {
+	if r.Status != "Enabled" || r.Expiration == nil || !r.isPrefixRule() || !strings.HasPrefix(key, r.prefix()) {
+		return false
+	}
+	if r.Expiration.Days > 0 {
+		return now.Sub(modTime) >= time.Duration(r.Expiration.Days)*24*time.Hour
+	}
+	if r.Expiration.Date != "" {
+		date, err := time.Parse(time.RFC3339, r.Expiration.Date)
+		return err == nil && !now.Before(date)
+	}
+	return false
+}
+
+// lifecycleRuleID - identifier of the rules generated by mc, S3 limits IDs to 255 characters
+func lifecycleRuleID(prefix string) string {
+	id := "mc-expire-" + prefix
+	if len(id) > 255 {
+		id = id[:255]
+	}
+	return id
+}
+
+// setPrefixRule - returns a new configuration where the prefix expires after expiryDays and incomplete
+// uploads are aborted after abortDays, zero days removes the action. Other rules are left untouched.
+func (l lifecycleConfiguration) setPrefixRule(prefix string, expiryDays, abortDays int) lifecycleConfiguration {
+	newConfig := lifecycleConfiguration{}
+	found := false
+	for _, rule := range l.Rules {
+		if !rule.isPrefixRule() || rule.prefix() != prefix || found {
+			newConfig.Rules = append(newConfig.Rules, rule)
+			continue
+		}
+		found = true
+		rule.Status = "Enabled"
+		rule.Expiration, rule.AbortIncompleteMultipartUpload = nil, nil
+		if expiryDays > 0 {
+			rule.Expiration = &lifecycleExpiration{Days: expiryDays}
+		}
+		if abortDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &lifecycleAbortIncomplete{DaysAfterInitiation: abortDays}
+		}
+		if rule.hasActions() {
+			newConfig.Rules = append(newConfig.Rules, rule)
+		}
+	}
+	if found || (expiryDays <= 0 && abortDays <= 0) {
+		return newConfig
+	}
+	rule := lifecycleRule{
+		ID:     lifecycleRuleID(prefix),
+		Prefix: &prefix,
+		Status: "Enabled",
+	}
+	if expiryDays > 0 {
+		rule.Expiration = &lifecycleExpiration{Days: expiryDays}
+	}
+	if abortDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &lifecycleAbortIncomplete{DaysAfterInitiation: abortDays}
+	}
+	newConfig.Rules = append(newConfig.Rules, rule)
+	return newConfig
+}
+
+// removePrefixRules - returns a new configuration without the rules for prefix
+func (l lifecycleConfiguration) removePrefixRules(prefix string) lifecycleConfiguration {
+	newConfig := lifecycleConfiguration{}
+	for _, rule := range l.Rules {
+		if rule.isPrefixRule() && rule.prefix() == prefix {
+			continue
+		}
+		newConfig.Rules = append(newConfig.Rules, rule)
+	}
+	return newConfig
+}
+
+// expiredBy - the first rule expiring an object, nil if the object is kept
+func (l lifecycleConfiguration) expiredBy(key string, modTime, now time.Time) *lifecycleRule {
+	for i := range l.Rules {
+		if l.Rules[i].isExpired(key, modTime, now) {
+			return &l.Rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestLifecycleRules(c *C) {
+	lifecycle, perr := parseLifecycle("")
+	c.Assert(perr, IsNil)
+	c.Assert(lifecycle.Rules, HasLen, 0)
+
+	lifecycle = lifecycle.setPrefixRule("logs/", 30, 0)
+	lifecycle = lifecycle.setPrefixRule("", 0, 7)
+
+	// round trip through XML
+	lifecycle, perr = parseLifecycle(lifecycle.String())
+	c.Assert(perr, IsNil)
+	c.Assert(lifecycle.Rules, HasLen, 2)
+	c.Assert(lifecycle.Rules[0].prefix(), Equals, "logs/")
+	c.Assert(lifecycle.Rules[0].expiryDays(), Equals, 30)
+	c.Assert(lifecycle.Rules[0].abortIncompleteDays(), Equals, 0)
+	c.Assert(lifecycle.Rules[1].prefix(), Equals, "")
+	c.Assert(lifecycle.Rules[1].abortIncompleteDays(), Equals, 7)
+
+	// rules are updated in place
+	lifecycle = lifecycle.setPrefixRule("logs/", 10, 1)
+	c.Assert(lifecycle.Rules, HasLen, 2)
+	c.Assert(lifecycle.Rules[0].expiryDays(), Equals, 10)
+	c.Assert(lifecycle.Rules[0].abortIncompleteDays(), Equals, 1)
+
+	now := time.Now().UTC()
+	c.Assert(lifecycle.expiredBy("logs/old", now.Add(-11*24*time.Hour), now), Not(IsNil))
+	c.Assert(lifecycle.expiredBy("logs/new", now.Add(-9*24*time.Hour), now), IsNil)
+	c.Assert(lifecycle.expiredBy("data/old", now.Add(-11*24*time.Hour), now), IsNil)
+
+	lifecycle = lifecycle.removePrefixRules("logs/")
+	c.Assert(lifecycle.Rules, HasLen, 1)
+
+	// rules not managed by mc are preserved
+	lifecycle, perr = parseLifecycle(`<LifecycleConfiguration><Rule><ID>archive</ID><Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter><Status>Disabled</Status><Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`)
+	c.Assert(perr, IsNil)
+	lifecycle = lifecycle.setPrefixRule("", 5, 0)
+	lifecycle, perr = parseLifecycle(lifecycle.String())
+	c.Assert(perr, IsNil)
+	c.Assert(lifecycle.Rules, HasLen, 2)
+	c.Assert(lifecycle.Rules[0].isPrefixRule(), Equals, false)
+	c.Assert(lifecycle.Rules[0].Extra, HasLen, 1)
+	c.Assert(lifecycle.Rules[0].Extra[0].XMLName.Local, Equals, "Transition")
+	c.Assert(lifecycle.expiredBy("any", now.Add(-6*24*time.Hour), now).ID, Equals, lifecycleRuleID(""))
+
+	_, perr = parseLifecycle("invalid")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestLifecycleServer(c *C) {
+	s3v4Server.MakeBucket("lifecycle-bucket", "private")
+
+	perr := doSetLifecycle(s3v4Server.URL+"/lifecycle-bucket/logs/", 30, 7)
+	c.Assert(perr, IsNil)
+	perr = doSetLifecycle(s3v4Server.URL+"/lifecycle-bucket/tmp/", 1, 0)
+	c.Assert(perr, IsNil)
+
+	rules, perr := doGetLifecycle(s3v4Server.URL + "/lifecycle-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(rules, HasLen, 2)
+	rules, perr = doGetLifecycle(s3v4Server.URL + "/lifecycle-bucket/logs/")
+	c.Assert(perr, IsNil)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].expiryDays(), Equals, 30)
+	c.Assert(rules[0].abortIncompleteDays(), Equals, 7)
+
+	perr = doRemoveLifecycle(s3v4Server.URL + "/lifecycle-bucket/logs/")
+	c.Assert(perr, IsNil)
+	rules, perr = doGetLifecycle(s3v4Server.URL + "/lifecycle-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(rules, HasLen, 1)
+
+	perr = doRemoveLifecycle(s3v4Server.URL + "/lifecycle-bucket")
+	c.Assert(perr, IsNil)
+	rules, perr = doGetLifecycle(s3v4Server.URL + "/lifecycle-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(rules, HasLen, 0)
+
+	// signature V2 and filesystem targets do not support lifecycle configuration
+	perr = doSetLifecycle(s3v2Server.URL+"/lifecycle-bucket/logs/", 30, 0)
+	c.Assert(perr, Not(IsNil))
+	_, perr = doGetLifecycle(c.MkDir())
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestLifecycleApplyFS(c *C) {
+	root := c.MkDir()
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	for _, name := range []string{"logs/old.log", "logs/new.log", "data/old.dat"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte(name), 0600), IsNil)
+		if filepath.Base(path) != "new.log" {
+			c.Assert(os.Chtimes(path, old, old), IsNil)
+		}
+	}
+
+	// rules of a bucket apply with prefixes relative to the folder
+	s3v4Server.MakeBucket("lifecycle-apply", "private")
+	perr := doSetLifecycle(s3v4Server.URL+"/lifecycle-apply/logs/", 30, 0)
+	c.Assert(perr, IsNil)
+	lifecycle, perr := lifecycleApplyRules(s3v4Server.URL + "/lifecycle-apply")
+	c.Assert(perr, IsNil)
+
+	var expired []string
+	expiredFunc := func(objectURL string, rule lifecycleRule, err *probe.Error) {
+		c.Assert(err, IsNil)
+		expired = append(expired, objectURL)
+	}
+
+	globalDryRunFlag = true
+	perr = doApplyLifecycle(root, lifecycle, time.Now().UTC(), expiredFunc)
+	globalDryRunFlag = false
+	c.Assert(perr, IsNil)
+	c.Assert(expired, DeepEquals, []string{filepath.Join(root, "logs", "old.log")})
+	_, err := os.Stat(filepath.Join(root, "logs", "old.log"))
+	c.Assert(err, IsNil)
+
+	expired = nil
+	perr = doApplyLifecycle(root, lifecycle, time.Now().UTC(), expiredFunc)
+	c.Assert(perr, IsNil)
+	c.Assert(expired, DeepEquals, []string{filepath.Join(root, "logs", "old.log")})
+	_, err = os.Stat(filepath.Join(root, "logs", "old.log"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// a plain number of days expires everything below the folder
+	lifecycle, perr = lifecycleApplyRules("30")
+	c.Assert(perr, IsNil)
+	expired = nil
+	perr = doApplyLifecycle(root+string(os.PathSeparator), lifecycle, time.Now().UTC(), expiredFunc)
+	c.Assert(perr, IsNil)
+	c.Assert(expired, DeepEquals, []string{filepath.Join(root, "data", "old.dat")})
+	_, err = os.Stat(filepath.Join(root, "logs", "new.log"))
+	c.Assert(err, IsNil)
+}
+
+func (s *TestSuite) TestLifecycleContext(c *C) {
+	console.IsExited = false
+
+	s3v4Server.MakeBucket("lifecycle-context", "private")
+	err := app.Run([]string{os.Args[0], "lifecycle", "set", s3v4Server.URL + "/lifecycle-context/logs/", "30", "7"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "lifecycle", "get", s3v4Server.URL + "/lifecycle-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "lifecycle", "remove", s3v4Server.URL + "/lifecycle-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "lifecycle", "apply", c.MkDir(), "30"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "lifecycle", "set", s3v4Server.URL + "/lifecycle-context", "0"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+	err = app.Run([]string{os.Args[0], "lifecycle", "apply", s3v4Server.URL + "/lifecycle-context", "30"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}
@@ -30,12 +30,16 @@ var lsCmd = cli.Command{
 	Name:   "ls",
 	Usage:  "List files and folders.",
 	Action: mainList,
+	Flags:  []cli.Flag{versionsFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} TARGET [TARGET ...]
+   mc {{.Name}} [FLAGS] TARGET [TARGET ...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. List buckets on Amazon S3 cloud storage.
       $ mc {{.Name}} https://s3.amazonaws.com/
@@ -66,6 +70,11 @@ EXAMPLES:
       $ mc --mimic ls
       [2015-05-19 17:28:22 PDT]    41B 本語.md
 
+   7. List all versions of objects in a versioned bucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} --versions s3/andoria/
+      [2015-05-19 17:28:22 PDT]    41B config.json (3sL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY) [latest]
+      [2015-05-19 17:24:19 PDT]    38B config.json (3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrH)
+
 `,
 }
 
@@ -94,6 +103,8 @@ func setListPalette(style string) {
 		"Dir":  color.New(color.FgCyan, color.Bold),
 		"Size": color.New(color.FgYellow),
 		"Time": color.New(color.FgGreen),
+
+		"Version": color.New(color.FgMagenta),
 	})
 	if style == "light" {
 		console.SetCustomPalette(map[string]*color.Color{
@@ -101,6 +112,8 @@ func setListPalette(style string) {
 			"Dir":  color.New(color.FgWhite, color.Bold),
 			"Size": color.New(color.FgWhite, color.Bold),
 			"Time": color.New(color.FgWhite, color.Bold),
+
+			"Version": color.New(color.FgWhite, color.Bold),
 		})
 		return
 	}
@@ -129,10 +142,10 @@ func mainList(ctx *cli.Context) {
 	for _, targetURL := range targetURLs {
 		// if recursive strip off the "..."
 		var clnt client.Client
-		clnt, err = url2Client(stripRecursiveURL(targetURL))
+		clnt, err = url2ListClient(stripRecursiveURL(targetURL))
 		fatalIf(err.Trace(targetURL), "Unable to initialize target ‘"+targetURL+"’.")
 
-		err = doList(clnt, isURLRecursive(targetURL), len(targetURLs) > 1)
+		err = doList(clnt, isURLRecursive(targetURL), ctx.Bool("versions"), len(targetURLs) > 1)
 		fatalIf(err.Trace(clnt.URL().String()), "Unable to list target ‘"+clnt.URL().String()+"’.")
 	}
 }
@@ -43,6 +43,13 @@ type ContentMessage struct {
 	Time     time.Time `json:"lastModified"`
 	Size     int64     `json:"size"`
 	Name     string    `json:"name"`
+
+	VersionID      string `json:"versionId,omitempty"`
+	IsLatest       bool   `json:"isLatest,omitempty"`
+	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+
+	StorageClass string            `json:"storageClass,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
 }
 
 // String colorized string message
@@ -55,6 +62,15 @@ func (c ContentMessage) String() string {
 		}
 		return message + console.Colorize("File", fmt.Sprintf("%s", c.Name))
 	}()
+	if c.VersionID != "" {
+		message = message + console.Colorize("Version", fmt.Sprintf(" (%s)", c.VersionID))
+		if c.IsLatest {
+			message = message + console.Colorize("Version", " [latest]")
+		}
+		if c.IsDeleteMarker {
+			message = message + console.Colorize("Version", " [deleted]")
+		}
+	}
 	return message
 }
 
@@ -99,18 +115,27 @@ func parseContent(c *client.Content) ContentMessage {
 		}
 		return c.Name
 	}()
+	content.VersionID = c.VersionID
+	content.IsLatest = c.IsLatest
+	content.IsDeleteMarker = c.IsDeleteMarker
+	content.StorageClass = c.StorageClass
+	content.Tags = c.Tags
 	return content
 }
 
 // doList - list all entities inside a folder.
-func doList(clnt client.Client, recursive, multipleArgs bool) *probe.Error {
+func doList(clnt client.Client, recursive, versions, multipleArgs bool) *probe.Error {
 	var err *probe.Error
 	var parentContent *client.Content
 	parentContent, err = clnt.Stat()
 	if err != nil {
 		return err.Trace(clnt.URL().String())
 	}
-	for contentCh := range clnt.List(recursive) {
+	listCh := clnt.List(recursive)
+	if versions {
+		listCh = clnt.ListVersions(recursive)
+	}
+	for contentCh := range listCh {
 		if contentCh.Err != nil {
 			switch contentCh.Err.ToGoError().(type) {
 			// handle this specifically for filesystem
@@ -39,7 +39,7 @@ func (s *TestSuite) TestLSContext(c *C) {
 		objectPath := filepath.Join(root, "object"+strconv.Itoa(i))
 		data := "hello"
 		dataLen := len(data)
-		perr = putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+		perr = putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 		c.Assert(perr, IsNil)
 	}
 
@@ -47,7 +47,7 @@ func (s *TestSuite) TestLSContext(c *C) {
 		objectPath := server.URL + "/bucket/object" + strconv.Itoa(i)
 		data := "hello"
 		dataLen := len(data)
-		perr := putTarget(objectPath, int64(dataLen), bytes.NewReader([]byte(data)))
+		perr := putTarget(objectPath, "", int64(dataLen), bytes.NewReader([]byte(data)))
 		c.Assert(perr, IsNil)
 	}
 
@@ -89,6 +89,8 @@ func registerBefore(ctx *cli.Context) error {
 	globalMimicFlag = ctx.GlobalBool("mimic")
 	globalDebugFlag = ctx.GlobalBool("debug")
 	globalJSONFlag = ctx.GlobalBool("json")
+	globalDryRunFlag = ctx.GlobalBool("dry-run")
+	globalProfileFlag = ctx.GlobalString("profile")
 	if globalDebugFlag {
 		console.NoDebugPrint = false
 	}
@@ -116,27 +118,36 @@ func findClosestCommands(command string) []string {
 
 func registerApp() *cli.App {
 	// Register all the commands
-	registerCmd(lsCmd)      // List contents of a bucket.
-	registerCmd(mbCmd)      // Make a bucket.
-	registerCmd(catCmd)     // Display contents of a file.
-	registerCmd(pigCmd)     // Write contents of stdin to a file.
-	registerCmd(cpCmd)      // Copy objects and files from multiple sources to single destination.
-	registerCmd(mirrorCmd)  // Mirror objects and files from single source to multiple destinations.
-	registerCmd(sessionCmd) // Manage sessions for copy and mirror.
-	registerCmd(shareCmd)   // Share documents via URL.
-	registerCmd(diffCmd)    // Computer differences between two files or folders.
-	registerCmd(accessCmd)  // Set access permissions.
-	registerCmd(configCmd)  // Configure minio client.
-	registerCmd(updateCmd)  // Check for new software updates.
-	registerCmd(versionCmd) // Print version.
+	registerCmd(lsCmd)        // List contents of a bucket.
+	registerCmd(mbCmd)        // Make a bucket.
+	registerCmd(catCmd)       // Display contents of a file.
+	registerCmd(statCmd)      // Display information about files and objects.
+	registerCmd(tagCmd)       // Manage object tags.
+	registerCmd(pigCmd)       // Write contents of stdin to a file.
+	registerCmd(cpCmd)        // Copy objects and files from multiple sources to single destination.
+	registerCmd(mirrorCmd)    // Mirror objects and files from single source to multiple destinations.
+	registerCmd(sessionCmd)   // Manage sessions for copy and mirror.
+	registerCmd(batchCmd)     // Run cp, mirror and rm jobs from a batch file.
+	registerCmd(shareCmd)     // Share documents via URL.
+	registerCmd(watchCmd)     // Watch for object changes.
+	registerCmd(diffCmd)      // Computer differences between two files or folders.
+	registerCmd(accessCmd)    // Set access permissions.
+	registerCmd(policyCmd)    // Manage bucket policies.
+	registerCmd(lifecycleCmd) // Manage bucket lifecycle rules.
+	registerCmd(eventsCmd)    // Manage bucket event notifications.
+	registerCmd(configCmd)    // Configure minio client.
+	registerCmd(updateCmd)    // Check for new software updates.
+	registerCmd(versionCmd)   // Print version.
 
 	// register all the flags
-	registerFlag(configFlag) // Path to configuration folder.
-	registerFlag(quietFlag)  // Suppress chatty console output.
-	registerFlag(mimicFlag)  // Behave like operating system tools. Use with shell aliases.
-	registerFlag(jsonFlag)   // Enable json formatted output.
-	registerFlag(debugFlag)  // Enable debugging output.
-	registerFlag(colorsFlag) // Choose different styles of console coloring.
+	registerFlag(configFlag)  // Path to configuration folder.
+	registerFlag(quietFlag)   // Suppress chatty console output.
+	registerFlag(mimicFlag)   // Behave like operating system tools. Use with shell aliases.
+	registerFlag(jsonFlag)    // Enable json formatted output.
+	registerFlag(debugFlag)   // Enable debugging output.
+	registerFlag(dryRunFlag)  // Print planned operations without performing them.
+	registerFlag(profileFlag) // Shared credentials profile.
+	registerFlag(colorsFlag)  // Choose different styles of console coloring.
 
 	app := cli.NewApp()
 	app.Usage = "Minio Client for cloud storage and filesystems."
@@ -57,6 +57,9 @@ type MakeBucketMessage struct {
 
 // String colorized make bucket message
 func (s MakeBucketMessage) String() string {
+	if s.Status == "dry-run" {
+		return console.Colorize("MakeBucket", "Bucket would be created ‘"+s.Bucket+"’ (dry run)")
+	}
 	return console.Colorize("MakeBucket", "Bucket created successfully  ‘"+s.Bucket+"’")
 }
 
@@ -106,19 +109,26 @@ func mainMakeBucket(ctx *cli.Context) {
 		targetURL := getAliasURL(arg, config.Aliases)
 
 		fatalIf(doMakeBucket(targetURL).Trace(targetURL), "Unable to make bucket ‘"+targetURL+"’.")
+		status := "success"
+		if globalDryRunFlag {
+			status = "dry-run"
+		}
 		Prints("%s\n", MakeBucketMessage{
-			Status: "success",
+			Status: status,
 			Bucket: targetURL,
 		})
 	}
 }
 
-// doMakeBucket -
+// doMakeBucket - make a bucket or folder, in dry run mode only the URL is validated
 func doMakeBucket(targetURL string) *probe.Error {
 	clnt, err := url2Client(targetURL)
 	if err != nil {
 		return err.Trace(targetURL)
 	}
+	if globalDryRunFlag {
+		return nil
+	}
 	err = clnt.MakeBucket()
 	if err != nil {
 		return err.Trace(targetURL)
@@ -17,8 +17,11 @@
 package main
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
+	"github.com/minio/mc/pkg/client/s3test"
 	"github.com/minio/mc/pkg/console"
 	. "gopkg.in/check.v1"
 )
@@ -105,3 +108,161 @@ func (s *TestSuite) TestAccessContext(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestMbAndAccessServer(c *C) {
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		perr := doMakeBucket(s3Server.URL + "/access-bucket")
+		c.Assert(perr, IsNil)
+
+		for _, perm := range []bucketPerms{"private", "readonly", "public", "authorized"} {
+			perr = doSetAccess(s3Server.URL+"/access-bucket", perm)
+			c.Assert(perr, IsNil)
+			acl, ok := s3Server.BucketACL("access-bucket")
+			c.Assert(ok, Equals, true)
+			c.Assert(acl, Equals, perm.String())
+
+			perms, perr := doGetAccess(s3Server.URL + "/access-bucket")
+			c.Assert(perr, IsNil)
+			c.Assert(perms, Equals, perm)
+		}
+	}
+}
+
+func (s *TestSuite) TestAccessObjectServer(c *C) {
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		s3Server.MakeBucket("object-access", "private")
+		for _, object := range []string{"photos/1.jpg", "photos/2015/2.jpg", "videos/3.mp4"} {
+			c.Assert(s3Server.PutObject("object-access", object, []byte(object)), Equals, true)
+		}
+
+		perr := doSetAccess(s3Server.URL+"/object-access/videos/3.mp4", bucketReadOnly)
+		c.Assert(perr, IsNil)
+		perms, perr := doGetAccess(s3Server.URL + "/object-access/videos/3.mp4")
+		c.Assert(perr, IsNil)
+		c.Assert(perms, Equals, bucketReadOnly)
+
+		objects, failed, perr := doSetAccessRecursive(s3Server.URL+"/object-access/photos/...", bucketPublic)
+		c.Assert(perr, IsNil)
+		c.Assert(objects, Equals, 2)
+		c.Assert(failed, Equals, 0)
+		for _, object := range []string{"photos/1.jpg", "photos/2015/2.jpg"} {
+			acl, ok := s3Server.ObjectACL("object-access", object)
+			c.Assert(ok, Equals, true)
+			c.Assert(acl, Equals, "public-read-write")
+		}
+		acl, _ := s3Server.ObjectACL("object-access", "videos/3.mp4")
+		c.Assert(acl, Equals, "public-read")
+
+		got := make(map[string]bucketPerms)
+		perr = doGetAccessRecursive(s3Server.URL+"/object-access...", func(objectURL string, perms bucketPerms) {
+			got[objectURL] = perms
+		})
+		c.Assert(perr, IsNil)
+		c.Assert(got, DeepEquals, map[string]bucketPerms{
+			s3Server.URL + "/object-access/photos/1.jpg":      bucketPublic,
+			s3Server.URL + "/object-access/photos/2015/2.jpg": bucketPublic,
+			s3Server.URL + "/object-access/videos/3.mp4":      bucketReadOnly,
+		})
+
+		// the bucket acl is left alone
+		bucketACL, _ := s3Server.BucketACL("object-access")
+		c.Assert(bucketACL, Equals, "private")
+	}
+}
+
+func (s *TestSuite) TestAccessObjectPartial(c *C) {
+	s3v4Server.MakeBucket("partial-access", "private")
+	for _, object := range []string{"1.jpg", "2.jpg", "3.jpg"} {
+		c.Assert(s3v4Server.PutObject("partial-access", object, []byte(object)), Equals, true)
+	}
+	c.Assert(s3v4Server.LockObjectACL("partial-access", "2.jpg"), Equals, true)
+
+	objects, failed, perr := doSetAccessRecursive(s3v4Server.URL+"/partial-access/...", bucketReadOnly)
+	c.Assert(perr, IsNil)
+	c.Assert(objects, Equals, 2)
+	c.Assert(failed, Equals, 1)
+	acl, _ := s3v4Server.ObjectACL("partial-access", "3.jpg")
+	c.Assert(acl, Equals, "public-read")
+
+	// objects which failed are reported through the exit status.
+	defer func() {
+		console.ExitStatus = 0
+		console.IsError = false
+	}()
+	err := app.Run([]string{os.Args[0], "access", "set", "public", s3v4Server.URL + "/partial-access/..."})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(console.ExitStatus, Equals, exitStatusPartial)
+	acl, _ = s3v4Server.ObjectACL("partial-access", "1.jpg")
+	c.Assert(acl, Equals, "public-read-write")
+}
+
+func (s *TestSuite) TestAccessObjectFS(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "access-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{"a", "dir/b", "dir/sub/c"} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600), IsNil)
+	}
+
+	objects, _, perr := doSetAccessRecursive(filepath.Join(root, "dir")+"...", bucketReadOnly)
+	c.Assert(perr, IsNil)
+	c.Assert(objects, Equals, 2)
+	for _, name := range []string{"dir/b", "dir/sub/c"} {
+		st, err := os.Stat(filepath.Join(root, name))
+		c.Assert(err, IsNil)
+		c.Assert(st.Mode().Perm(), Equals, os.FileMode(0644))
+	}
+
+	perms, perr := doGetAccess(filepath.Join(root, "a"))
+	c.Assert(perr, IsNil)
+	c.Assert(perms, Equals, bucketPrivate)
+
+	// nothing changes in dry run mode
+	globalDryRunFlag = true
+	defer func() { globalDryRunFlag = false }()
+	objects, _, perr = doSetAccessRecursive(filepath.Join(root, "dir")+"...", bucketPublic)
+	c.Assert(perr, IsNil)
+	c.Assert(objects, Equals, 2)
+	st, err := os.Stat(filepath.Join(root, "dir/b"))
+	c.Assert(err, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0644))
+}
+
+func (s *TestSuite) TestAccessFolderFS(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "access-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	bucket := filepath.Join(root, "bucket")
+	c.Assert(doMakeBucket(bucket), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(bucket, "folder"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(bucket, "folder", "file"), []byte("file"), 0600), IsNil)
+
+	for _, perm := range []bucketPerms{bucketPrivate, bucketReadOnly, bucketPublic, bucketAuthorized} {
+		perr := doSetAccess(bucket, perm)
+		c.Assert(perr, IsNil)
+		perms, perr := doGetAccess(bucket)
+		c.Assert(perr, IsNil)
+		c.Assert(perms, Equals, perm)
+
+		got := make(map[string]bucketPerms)
+		perr = doGetAccessRecursive(bucket+"...", func(objectURL string, perms bucketPerms) {
+			got[objectURL] = perms
+		})
+		c.Assert(perr, IsNil)
+		c.Assert(got, DeepEquals, map[string]bucketPerms{
+			filepath.Join(bucket, "folder"):         perm,
+			filepath.Join(bucket, "folder", "file"): perm,
+		})
+	}
+
+	st, err := os.Stat(filepath.Join(bucket, "folder"))
+	c.Assert(err, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0750))
+	st, err = os.Stat(filepath.Join(bucket, "folder", "file"))
+	c.Assert(err, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0640))
+}
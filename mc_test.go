@@ -28,6 +28,8 @@ import (
 	"net/http/httptest"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3test"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/quick"
 	. "gopkg.in/check.v1"
@@ -44,6 +46,9 @@ var _ = Suite(&TestSuite{})
 var server *httptest.Server
 var app *cli.App
 
+// S3 servers verifying signature V4 and V2 respectively
+var s3v4Server, s3v2Server *s3test.Server
+
 func (s *TestSuite) SetUpSuite(c *C) {
 	objectAPI := objectAPIHandler(objectAPIHandler{lock: &sync.Mutex{}, bucket: "bucket", object: make(map[string][]byte)})
 	server = httptest.NewServer(objectAPI)
@@ -69,6 +74,19 @@ func (s *TestSuite) SetUpSuite(c *C) {
 	config, perr := newConfig()
 	c.Assert(perr, IsNil)
 
+	s3v4Server = s3test.NewServer("S3V4ACCESSKEY", "S3V4SECRETKEY")
+	s3v2Server = s3test.NewServer("S3V2ACCESSKEY", "S3V2SECRETKEY")
+	config.Data().(*configV5).Hosts[client.NewURL(s3v4Server.URL).Host] = hostConfig{
+		AccessKeyID:     s3v4Server.AccessKeyID,
+		SecretAccessKey: s3v4Server.SecretAccessKey,
+		API:             "S3v4",
+	}
+	config.Data().(*configV5).Hosts[client.NewURL(s3v2Server.URL).Host] = hostConfig{
+		AccessKeyID:     s3v2Server.AccessKeyID,
+		SecretAccessKey: s3v2Server.SecretAccessKey,
+		API:             "S3v2",
+	}
+
 	perr = writeConfig(config)
 	c.Assert(perr, IsNil)
 
@@ -83,6 +101,12 @@ func (s *TestSuite) TearDownSuite(c *C) {
 	if server != nil {
 		server.Close()
 	}
+	if s3v4Server != nil {
+		s3v4Server.Close()
+	}
+	if s3v2Server != nil {
+		s3v2Server.Close()
+	}
 }
 
 func (s *TestSuite) TestGetNewClient(c *C) {
@@ -21,10 +21,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net"
 	"os"
-	"runtime"
 	"sync"
 
 	"github.com/fatih/color"
@@ -38,12 +36,16 @@ var mirrorCmd = cli.Command{
 	Name:   "mirror",
 	Usage:  "Mirror folders recursively from a single source to many destinations.",
 	Action: mainMirror,
+	Flags:  []cli.Flag{reportFlag, storageClassFlag, sessionExpiryFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} SOURCE TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] SOURCE TARGET [TARGET...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Mirror a bucket recursively from Minio cloud storage to multiple buckets on Amazon S3 cloud storage.
       $ mc {{.Name}} https://play.minio.io:9000/photos/2014 https://s3.amazonaws.com/backup-photos https://s3-west-1.amazonaws.com/local-photos
@@ -59,6 +61,17 @@ EXAMPLES:
 
    5. Mirror a local folder with space characters to Amazon s3 cloud storage
       $ mc {{.Name}} 'workdir/documents/Aug 2015' s3/miniocloud
+
+   6. Mirror a local folder to Amazon S3 cloud storage with reduced redundancy. Storage classes are ignored by filesystem targets.
+      $ mc {{.Name}} --storage-class REDUCED_REDUNDANCY backup/ s3/archive
+
+   7. Mirror a local folder to Amazon S3 cloud storage and write the JSON summary of the run to a file.
+      $ mc {{.Name}} --report report.json backup/ s3/archive
+
+EXIT STATUS:
+   0 - All objects were mirrored.
+   1 - Fatal error, nothing or only part of the objects were mirrored.
+   2 - One or more objects failed to mirror, retry them with ‘mc session retry SESSION-ID’.
 `,
 }
 
@@ -81,8 +94,8 @@ func (m MirrorMessage) JSON() string {
 	return string(mirrorMessageBytes)
 }
 
-// doMirror - Mirror an object to multiple destination. mirrorURLs status contains a copy of sURLs and error if any.
-func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- mirrorURLs) {
+// doMirror - Mirror an object to multiple destination, stored with storageClass if set. mirrorURLs status contains a copy of sURLs and error if any.
+func doMirror(sURLs mirrorURLs, storageClass string, progressReader interface{}, mirrorQueueCh <-chan bool, wg *sync.WaitGroup, statusCh chan<- mirrorURLs) {
 	defer wg.Done() // Notify that this copy routine is done.
 	defer func() {
 		<-mirrorQueueCh
@@ -94,14 +107,16 @@ func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan
 		return
 	}
 
-	if !globalQuietFlag && !globalJSONFlag {
-		progressReader.(*barSend).SetCaption(sURLs.SourceContent.Name + ": ")
+	// Sessions without progress bar account their transfers.
+	bar, showProgress := progressReader.(*barSend)
+	if showProgress {
+		bar.SetCaption(sURLs.SourceContent.Name + ": ")
 	}
 
 	reader, length, err := getSource(sURLs.SourceContent.Name)
 	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorGet(int64(length))
+		if showProgress {
+			bar.ErrorGet(int64(length))
 		}
 		sURLs.Error = err.Trace(sURLs.SourceContent.Name)
 		statusCh <- sURLs
@@ -114,7 +129,7 @@ func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan
 	}
 
 	var newReader io.ReadCloser
-	if globalQuietFlag || globalJSONFlag {
+	if !showProgress {
 		Prints("%s\n", MirrorMessage{
 			Source:  sURLs.SourceContent.Name,
 			Targets: targetURLs,
@@ -122,14 +137,14 @@ func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan
 		newReader = progressReader.(*accounter).NewProxyReader(reader)
 	} else {
 		// set up progress
-		newReader = progressReader.(*barSend).NewProxyReader(reader)
+		newReader = bar.NewProxyReader(reader)
 	}
 	defer newReader.Close()
 
-	err = putTargets(targetURLs, length, newReader)
+	err = putTargets(targetURLs, storageClass, length, newReader)
 	if err != nil {
-		if !globalQuietFlag && !globalJSONFlag {
-			progressReader.(*barSend).ErrorPut(int64(length))
+		if showProgress {
+			bar.ErrorPut(int64(length))
 		}
 		sURLs.Error = err.Trace(targetURLs...)
 		statusCh <- sURLs
@@ -142,13 +157,13 @@ func doMirror(sURLs mirrorURLs, progressReader interface{}, mirrorQueueCh <-chan
 
 // doMirrorFake - Perform a fake mirror to update the progress bar appropriately.
 func doMirrorFake(sURLs mirrorURLs, progressReader interface{}) {
-	if !globalDebugFlag && !globalJSONFlag {
-		progressReader.(*barSend).Progress(sURLs.SourceContent.Size)
+	if bar, ok := progressReader.(*barSend); ok {
+		bar.Progress(sURLs.SourceContent.Size)
 	}
 }
 
 // doPrepareMirrorURLs scans the source URL and prepares a list of objects for mirroring.
-func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
+func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) *probe.Error {
 	sourceURL := session.Header.CommandArgs[0] // first one is source.
 	targetURLs := session.Header.CommandArgs[1:]
 	var totalBytes int64
@@ -157,12 +172,13 @@ func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
 	// Create a session data file to store the processed URLs.
 	dataFP := session.NewDataWriter()
 
+	showProgress := session.showProgress()
 	var scanBar scanBarFunc
-	if !globalQuietFlag && !globalJSONFlag { // set up progress bar
+	if showProgress { // set up progress bar
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareMirrorURLs(sourceURL, targetURLs)
+	URLsCh := prepareMirrorURLs(sourceURL, targetURLs, showProgress)
 	done := false
 	for done == false {
 		select {
@@ -173,22 +189,22 @@ func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
 			}
 			if sURLs.Error != nil {
 				// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-				if !globalQuietFlag && !globalJSONFlag {
+				if showProgress {
 					console.Eraseline()
 				}
 				errorIf(sURLs.Error.Trace(), "Unable to prepare URLs for mirroring.")
 				break
 			}
-			if sURLs.isEmpty() {
+			if sURLs.isEmpty() || session.Header.IsFiltered(sURLs.SourceContent.Name) {
 				break
 			}
 			jsonData, err := json.Marshal(sURLs)
 			if err != nil {
 				session.Delete()
-				fatalIf(probe.NewError(err), "Unable to marshal URLs into JSON.")
+				return probe.NewError(err)
 			}
 			fmt.Fprintln(dataFP, string(jsonData))
-			if !globalQuietFlag && !globalJSONFlag {
+			if showProgress {
 				scanBar(sURLs.SourceContent.Name)
 			}
 
@@ -196,7 +212,7 @@ func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
 			totalObjects++
 		case <-trapCh:
 			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
-			if !globalQuietFlag && !globalJSONFlag {
+			if showProgress {
 				console.Eraseline()
 			}
 			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
@@ -205,19 +221,68 @@ func doPrepareMirrorURLs(session *sessionV2, trapCh <-chan bool) {
 	}
 	session.Header.TotalBytes = totalBytes
 	session.Header.TotalObjects = totalObjects
-	session.Save()
+	return session.Save().Trace(session.SessionID)
+}
+
+// doMirrorDryRun - print the planned mirror operations along with totals, nothing is mirrored.
+func doMirrorDryRun(session *sessionV2) *probe.Error {
+	if session.showProgress() {
+		console.Eraseline()
+	}
+	scanner := bufio.NewScanner(session.NewDataReader())
+	isCopied, err := session.NewSkipFilter()
+	if err != nil {
+		return err.Trace(session.SessionID)
+	}
+	var totalBytes int64
+	var totalObjects int
+	for index := 0; scanner.Scan(); index++ {
+		var sURLs mirrorURLs
+		json.Unmarshal([]byte(scanner.Text()), &sURLs)
+		if isCopied(index, sURLs.SourceContent.Name) {
+			continue
+		}
+		var targetURLs []string
+		for _, targetContent := range sURLs.TargetContents {
+			targetURLs = append(targetURLs, targetContent.Name)
+		}
+		Prints("%s\n", MirrorMessage{
+			Source:  sURLs.SourceContent.Name,
+			Targets: targetURLs,
+		})
+		totalBytes += sURLs.SourceContent.Size
+		totalObjects++
+	}
+	Prints("%s\n", DryRunMessage{
+		Operation:    "mirror",
+		TotalObjects: totalObjects,
+		TotalBytes:   totalBytes,
+	})
+	return nil
 }
 
-func doMirrorSession(session *sessionV2) {
+// doMirrorSession mirrors the objects of a session, errors which stop the whole session are returned.
+func doMirrorSession(session *sessionV2) (RunReportMessage, *probe.Error) {
 	trapCh := signalTrap(os.Interrupt, os.Kill)
 
 	if !session.HasData() {
-		doPrepareMirrorURLs(session, trapCh)
+		if err := doPrepareMirrorURLs(session, trapCh); err != nil {
+			return RunReportMessage{}, err.Trace(session.SessionID)
+		}
+	}
+
+	// Only print the mirror plan, do not transfer anything.
+	if globalDryRunFlag {
+		if err := doMirrorDryRun(session); err != nil {
+			return RunReportMessage{}, err.Trace(session.SessionID)
+		}
+		return newRunReport(session).Finish(), nil
 	}
 
 	// Set up progress bar.
+	showProgress := session.showProgress()
 	var progressReader interface{}
-	if !globalQuietFlag && !globalJSONFlag {
+	if showProgress {
 		progressReader = newProgressBar(session.Header.TotalBytes)
 	} else {
 		progressReader = newAccounter(session.Header.TotalBytes)
@@ -227,12 +292,15 @@ func doMirrorSession(session *sessionV2) {
 	scanner := bufio.NewScanner(session.NewDataReader())
 	// isCopied returns true if an object has been already copied
 	// or not. This is useful when we resume from a session.
-	isCopied := isCopiedFactory(session.Header.LastCopied)
+	isCopied, err := session.NewSkipFilter()
+	if err != nil {
+		return RunReportMessage{}, err.Trace(session.SessionID)
+	}
+	report := newRunReport(session)
 
 	wg := new(sync.WaitGroup)
-	// Limit numner of mirror routines based on available CPU resources.
-	mirrorQueue := make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
-	defer close(mirrorQueue)
+	// Limit number of mirror routines, sessions run by a batch share the limit.
+	mirrorQueue := session.TransferQueue()
 	// Status channel for receiveing mirror return status.
 	statusCh := make(chan mirrorURLs)
 
@@ -244,28 +312,34 @@ func doMirrorSession(session *sessionV2) {
 			select {
 			case sURLs, ok := <-statusCh: // Receive status.
 				if !ok { // We are done here. Top level function has returned.
-					if !globalQuietFlag && !globalJSONFlag {
+					if showProgress {
 						progressReader.(*barSend).Finish()
 					} else {
-						console.Println(console.Colorize("Mirror", progressReader.(*accounter).Finish()))
+						progressReader.(*accounter).Finish()
 					}
 					return
 				}
 				if sURLs.Error == nil {
-					session.Header.LastCopied = sURLs.SourceContent.Name
+					report.Done(sURLs.SourceContent.Size)
+					errorIf(session.SetObjectStatus(sURLs.index, sURLs.SourceContent.Name, sURLs.SourceContent.Size, nil).Trace(), "Unable to record status of ‘"+sURLs.SourceContent.Name+"’.")
 					session.Save()
 				} else {
 					// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-					if !globalQuietFlag && !globalJSONFlag {
+					if showProgress {
 						console.Eraseline()
 					}
 					errorIf(sURLs.Error.Trace(), fmt.Sprintf("Failed to mirror ‘%s’.", sURLs.SourceContent.Name))
+					report.Failed()
+					errorIf(session.SetObjectStatus(sURLs.index, sURLs.SourceContent.Name, sURLs.SourceContent.Size, sURLs.Error).Trace(), "Unable to record status of ‘"+sURLs.SourceContent.Name+"’.")
 					// all the cases which are handled where session should be saved are contained in the following
 					// switch case, we shouldn't be saving sessions for all errors since some errors might need to be
 					// reported to user properly.
 					//
 					// All other critical cases should be handled properly gracefully
 					// handle more errors and save the session.
+					if session.batch { // other sessions of the batch keep running.
+						break
+					}
 					switch sURLs.Error.ToGoError().(type) {
 					case *net.OpError:
 						gracefulSessionSave(session)
@@ -275,7 +349,7 @@ func doMirrorSession(session *sessionV2) {
 				}
 			case <-trapCh: // Receive interrupt notification.
 				// Print in new line and adjust to top so that we don't print over the ongoing progress bar
-				if !globalQuietFlag && !globalJSONFlag {
+				if showProgress {
 					console.Eraseline()
 				}
 				gracefulSessionSave(session)
@@ -290,11 +364,13 @@ func doMirrorSession(session *sessionV2) {
 		mirrorWg := new(sync.WaitGroup)
 		defer close(statusCh)
 
-		for scanner.Scan() {
+		for index := 0; scanner.Scan(); index++ {
 			var sURLs mirrorURLs
 			json.Unmarshal([]byte(scanner.Text()), &sURLs)
-			if isCopied(sURLs.SourceContent.Name) {
+			sURLs.index = index
+			if isCopied(index, sURLs.SourceContent.Name) {
 				doMirrorFake(sURLs, progressReader)
+				report.Skipped()
 			} else {
 				// Wait for other mirror routines to
 				// complete. We only have limited CPU
@@ -303,13 +379,14 @@ func doMirrorSession(session *sessionV2) {
 				// Account for each mirror routines we start.
 				mirrorWg.Add(1)
 				// Do mirroring in background concurrently.
-				go doMirror(sURLs, progressReader, mirrorQueue, mirrorWg, statusCh)
+				go doMirror(sURLs, session.Header.StorageClass, progressReader, mirrorQueue, mirrorWg, statusCh)
 			}
 		}
 		mirrorWg.Wait()
 	}()
 
 	wg.Wait()
+	return report.Finish(), nil
 }
 
 func setMirrorPalette(style string) {
@@ -333,6 +410,8 @@ func mainMirror(ctx *cli.Context) {
 	checkMirrorSyntax(ctx)
 
 	setMirrorPalette(ctx.GlobalString("colors"))
+	setReportPalette(ctx.GlobalString("colors"))
+	errorIf(expireSessions(ctx.String("session-expiry")).Trace(), "Unable to expire abandoned sessions.")
 
 	var e error
 	session := newSessionV2()
@@ -350,7 +429,11 @@ func mainMirror(ctx *cli.Context) {
 		session.Delete()
 		fatalIf(err.Trace(ctx.Args()...), fmt.Sprintf("One or more unknown argument types found in ‘%s’.", ctx.Args()))
 	}
+	// Storage class is validated by checkMirrorSyntax.
+	session.Header.StorageClass, _ = parseStorageClass(ctx.String("storage-class"))
 
-	doMirrorSession(session)
-	session.Delete()
+	report, err := doMirrorSession(session)
+	fatalIf(err.Trace(session.SessionID), "Unable to mirror session ‘"+session.SessionID+"’.")
+	fatalIf(session.Finish().Trace(session.SessionID), "Unable to finish session ‘"+session.SessionID+"’.")
+	printRunReport(report, ctx.String("report"))
 }
@@ -32,6 +32,8 @@ type mirrorURLs struct {
 	SourceContent  *client.Content
 	TargetContents []*client.Content
 	Error          *probe.Error `json:"-"`
+	// index of the line in the session data file, it identifies the object in the status log.
+	index int
 }
 
 func (m mirrorURLs) isEmpty() bool {
@@ -54,6 +56,8 @@ func checkMirrorSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
 		cli.ShowCommandHelpAndExit(ctx, "mirror", 1) // last argument is exit code.
 	}
+	checkReportSyntax(ctx)
+	checkSessionExpirySyntax(ctx)
 
 	// extract URLs.
 	URLs, err := args2URLs(ctx.Args())
@@ -75,6 +79,7 @@ func checkMirrorSyntax(ctx *cli.Context) {
 	if len(tgtURLs) == 0 && tgtURLs == nil {
 		fatalIf(errInvalidArgument().Trace(), "Invalid number of target arguments to mirror command.")
 	}
+	checkStorageClassSyntax(ctx.String("storage-class"), tgtURLs...)
 
 	for _, tgtURL := range tgtURLs {
 		// Recursive URLs are not allowed in target.
@@ -97,12 +102,13 @@ func checkMirrorSyntax(ctx *cli.Context) {
 	}
 }
 
-func deltaSourceTargets(sourceClnt client.Client, targetClnts []client.Client) <-chan mirrorURLs {
+// deltaSourceTargets lists the source objects missing on the targets, the scan is animated with showProgress.
+func deltaSourceTargets(sourceClnt client.Client, targetClnts []client.Client, showProgress bool) <-chan mirrorURLs {
 	mirrorURLsCh := make(chan mirrorURLs)
 
 	go func() {
 		defer close(mirrorURLsCh)
-		id := newRandomID(8)
+		id := newSortedListID()
 
 		doneCh := make(chan bool)
 		defer close(doneCh)
@@ -111,7 +117,7 @@ func deltaSourceTargets(sourceClnt client.Client, targetClnts []client.Client) <
 			for {
 				select {
 				case <-time.Tick(100 * time.Millisecond):
-					if !globalQuietFlag && !globalJSONFlag {
+					if showProgress {
 						console.PrintC("\r" + "Scanning.. " + string(<-cursorCh))
 					}
 				case <-doneCh:
@@ -180,7 +186,7 @@ func deltaSourceTargets(sourceClnt client.Client, targetClnts []client.Client) <
 			}
 		}
 		doneCh <- true
-		if !globalQuietFlag && !globalJSONFlag {
+		if showProgress {
 			console.Eraseline()
 		}
 	}()
@@ -188,7 +194,7 @@ func deltaSourceTargets(sourceClnt client.Client, targetClnts []client.Client) <
 }
 
 // prepareMirrorURLs - prepares target and source URLs for mirroring.
-func prepareMirrorURLs(sourceURL string, targetURLs []string) <-chan mirrorURLs {
+func prepareMirrorURLs(sourceURL string, targetURLs []string, showProgress bool) <-chan mirrorURLs {
 	mirrorURLsCh := make(chan mirrorURLs)
 
 	go func() {
@@ -223,7 +229,7 @@ func prepareMirrorURLs(sourceURL string, targetURLs []string) <-chan mirrorURLs
 			}
 			targetClnts[i] = targetClnt
 		}
-		for sURLs := range deltaSourceTargets(sourceClnt, targetClnts) {
+		for sURLs := range deltaSourceTargets(sourceClnt, targetClnts, showProgress) {
 			mirrorURLsCh <- sURLs
 		}
 	}()
@@ -29,12 +29,16 @@ var pigCmd = cli.Command{
 	Name:   "pig",
 	Usage:  "Write contents of stdin to files. Pig is the opposite of cat command.",
 	Action: mainPig,
+	Flags:  []cli.Flag{tagsFlag, storageClassFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc {{.Name}} TARGET [TARGET...]
+   mc {{.Name}} [FLAGS] TARGET [TARGET...]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Write contents of stdin to an object on Amazon S3 cloud storage.
       $ mc {{.Name}} https://s3.amazonaws.com/personalbuck/meeting-notes.txt
@@ -47,6 +51,12 @@ EXAMPLES:
 
    4. Contatinate a zip file to two object storage servers simultaneously.
       $ cat ~/myphotos.zip | mc {{.Name}} https://s3.amazonaws.com/mybucket/photos.zip  https://minio.mystartup.io:9000/backup/photos.zip 
+
+   5. Stream a database dump to Amazon S3 and tag the object.
+      $ mysqldump -u root -p ******* accountsdb | mc {{.Name}} --tags "team=db&retention=7d" s3/ferenginar/backups/accountsdb.sql
+
+   6. Stream a database dump to Amazon S3 with reduced redundancy storage.
+      $ mysqldump -u root -p ******* accountsdb | mc {{.Name}} --storage-class REDUCED_REDUNDANCY s3/ferenginar/backups/accountsdb.sql
 `,
 }
 
@@ -55,19 +65,40 @@ func checkPigSyntax(ctx *cli.Context) {
 	if !ctx.Args().Present() || ctx.Args().First() == "help" {
 		cli.ShowCommandHelpAndExit(ctx, "pig", 1) // last argument is exit code
 	}
+	if ctx.String("tags") != "" || ctx.String("storage-class") != "" {
+		URLs, err := args2URLs(ctx.Args())
+		fatalIf(err.Trace(ctx.Args()...), "One or more unknown URL types passed.")
+		checkTagsSyntax(ctx.String("tags"), URLs...)
+		checkStorageClassSyntax(ctx.String("storage-class"), URLs...)
+	}
 }
 
-// pig writes contents of stdin a collection of URLs.
-func pig(targetURLs []string) *probe.Error {
+// pig writes contents of stdin a collection of URLs with the given storage class and tags.
+func pig(targetURLs []string, storageClassStr, tagsStr string) *probe.Error {
 	URLs := []string{}
 	config := mustGetMcConfig()
 	for _, URL := range targetURLs {
 		URLs = append(URLs, getAliasURL(URL, config.Aliases))
 	}
 
+	// stdin is left untouched on a dry run, only report the targets.
+	if globalDryRunFlag {
+		for _, URL := range URLs {
+			if _, err := url2Client(URL); err != nil {
+				return err.Trace(URL)
+			}
+			Prints("%s\n", CopyMessage{
+				Source: "<STDIN>",
+				Target: URL,
+			})
+		}
+		return nil
+	}
+
 	//Stream from stdin to multiple objects until EOF.
 	// Ignore size, since os.Stat() would not return proper size all the time for local filesystem for example /proc files.
-	err := putTargets(URLs, 0, os.Stdin)
+	storageClass, _ := parseStorageClass(storageClassStr)
+	err := putTargets(URLs, storageClass, 0, os.Stdin)
 	// TODO: See if this check is necessary.
 	switch e := err.ToGoError().(type) {
 	case *os.PathError:
@@ -76,11 +107,31 @@ func pig(targetURLs []string) *probe.Error {
 			return nil
 		}
 	}
-	return err.Trace()
+	if err != nil {
+		return err.Trace()
+	}
+	return tagTargets(URLs, tagsStr).Trace(URLs...)
+}
+
+// tagTargets - tag all written objects with the tags given on the command line
+func tagTargets(targetURLs []string, tagsStr string) *probe.Error {
+	if tagsStr == "" {
+		return nil
+	}
+	tags, err := parseTags(tagsStr)
+	if err != nil {
+		return err.Trace(tagsStr)
+	}
+	for _, targetURL := range targetURLs {
+		if err := setTags(targetURL, tags); err != nil {
+			return err.Trace(targetURL)
+		}
+	}
+	return nil
 }
 
 // mainPig is the main entry point for pig command.
 func mainPig(ctx *cli.Context) {
 	checkPigSyntax(ctx)
-	fatalIf(pig(ctx.Args()).Trace(ctx.Args()...), "Unable to write to one or more targets.")
+	fatalIf(pig(ctx.Args(), ctx.String("storage-class"), ctx.String("tags")).Trace(ctx.Args()...), "Unable to write to one or more targets.")
 }
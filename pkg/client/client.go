@@ -18,6 +18,7 @@ package client
 
 import (
 	"io"
+	"net/url"
 	"os"
 	"time"
 
@@ -29,17 +30,36 @@ type Client interface {
 	// Common operations
 	Stat() (content *Content, err *probe.Error)
 	List(recursive bool) <-chan ContentOnChannel
+	Watch(recursive bool, doneCh <-chan struct{}) (<-chan EventOnChannel, *probe.Error)
 
 	// Bucket operations
 	MakeBucket() *probe.Error
 	GetBucketACL() (acl string, error *probe.Error)
 	SetBucketACL(acl string) *probe.Error
+	GetBucketPolicy() (policy string, error *probe.Error)
+	SetBucketPolicy(policy string) *probe.Error
+	RemoveBucketPolicy() *probe.Error
+	GetBucketLifecycle() (lifecycle string, error *probe.Error)
+	SetBucketLifecycle(lifecycle string) *probe.Error
+	RemoveBucketLifecycle() *probe.Error
+	GetBucketNotification() (notification string, error *probe.Error)
+	SetBucketNotification(notification string) *probe.Error
+	GetBucketVersioning() (status string, error *probe.Error)
+	SetBucketVersioning(status string) *probe.Error
+	ListVersions(recursive bool) <-chan ContentOnChannel
 
 	// Object operations
-	ShareDownload(expires time.Duration) (string, *probe.Error)
+	GetObjectACL() (acl string, error *probe.Error)
+	SetObjectACL(acl string) *probe.Error
+	GetObjectTags() (tags map[string]string, error *probe.Error)
+	SetObjectTags(tags map[string]string) *probe.Error
+	RemoveObjectTags() *probe.Error
+	ShareDownload(expires time.Duration, reqParams url.Values) (string, *probe.Error)
 	ShareUpload(bool, time.Duration, string) (map[string]string, *probe.Error)
+	ShareUploadURL(expires time.Duration, contentType string) (string, *probe.Error)
 	GetObject(offset, length int64) (body io.ReadCloser, size int64, err *probe.Error)
 	PutObject(size int64, data io.Reader) *probe.Error
+	Remove() *probe.Error
 
 	// URL returns back internal url
 	URL() *URL
@@ -51,12 +71,47 @@ type ContentOnChannel struct {
 	Err     *probe.Error
 }
 
+// EventOnChannel - Watch events on channel
+type EventOnChannel struct {
+	Event *Event
+	Err   *probe.Error
+}
+
+// EventType - type of an object change
+type EventType string
+
+// Object change event types
+const (
+	EventCreate EventType = "created"
+	EventRemove EventType = "removed"
+	EventModify EventType = "modified"
+)
+
+// Event container for an object change below a watched URL, Name is relative to the URL as in listings
+type Event struct {
+	Type EventType
+	Time time.Time
+	Name string
+	Size int64
+}
+
 // Content container for content metadata
 type Content struct {
 	Name string
 	Time time.Time
 	Size int64
 	Type os.FileMode
+
+	// set only for versions of an object
+	VersionID      string `json:",omitempty"`
+	IsLatest       bool   `json:",omitempty"`
+	IsDeleteMarker bool   `json:",omitempty"`
+
+	// set only when listing with object tags
+	Tags map[string]string `json:",omitempty"`
+
+	// set only for objects on cloud storage
+	StorageClass string `json:",omitempty"`
 }
 
 // Config - see http://docs.amazonwebservices.com/AmazonS3/latest/dev/index.html?RESTAuthentication.html
@@ -68,4 +123,24 @@ type Config struct {
 	AppVersion      string
 	AppComments     []string
 	Debug           bool
+
+	// ListTags fetches the tags of every object while listing, costs an extra request per object
+	ListTags bool
+
+	// StorageClass of uploaded objects, the server default if empty
+	StorageClass string
+
+	// SessionToken of temporary credentials, sent as X-Amz-Security-Token
+	SessionToken string
+
+	// Refresh, if set, is called before every request is signed and returns the
+	// credentials to sign with, temporary credentials are renewed by Refresh
+	Refresh func() (Credentials, *probe.Error)
+}
+
+// Credentials - keys to sign requests with, SessionToken is only set for temporary credentials
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
 }
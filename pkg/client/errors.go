@@ -62,6 +62,15 @@ func (e InvalidACLType) Error() string {
 	return "invalid acl type: " + e.ACL
 }
 
+// InvalidVersioningStatus - invalid bucket versioning status
+type InvalidVersioningStatus struct {
+	Status string
+}
+
+func (e InvalidVersioningStatus) Error() string {
+	return "invalid versioning status: " + e.Status
+}
+
 // InvalidMaxKeys - invalid maxkeys provided
 type InvalidMaxKeys struct {
 	MaxKeys int
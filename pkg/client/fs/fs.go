@@ -18,6 +18,7 @@ package fs
 
 import (
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -132,6 +133,14 @@ func (f *fsClient) PutObject(size int64, data io.Reader) *probe.Error {
 	return nil
 }
 
+// Remove - remove a file, folders are only removed when empty
+func (f *fsClient) Remove() *probe.Error {
+	if err := os.Remove(f.Path); err != nil {
+		return probe.NewError(err)
+	}
+	return nil
+}
+
 // get - download an object from bucket
 func (f *fsClient) get() (io.ReadCloser, int64, *probe.Error) {
 	body, err := os.Open(f.Path)
@@ -145,7 +154,7 @@ func (f *fsClient) get() (io.ReadCloser, int64, *probe.Error) {
 	return body, content.Size, nil
 }
 
-func (f *fsClient) ShareDownload(expires time.Duration) (string, *probe.Error) {
+func (f *fsClient) ShareDownload(expires time.Duration, reqParams url.Values) (string, *probe.Error) {
 	return "", probe.NewError(client.APINotImplemented{API: "ShareDownload", APIType: "filesystem"})
 }
 
@@ -153,6 +162,10 @@ func (f *fsClient) ShareUpload(recursive bool, expires time.Duration, contentTyp
 	return nil, probe.NewError(client.APINotImplemented{API: "ShareUpload", APIType: "filesystem"})
 }
 
+func (f *fsClient) ShareUploadURL(expires time.Duration, contentType string) (string, *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "ShareUploadURL", APIType: "filesystem"})
+}
+
 // GetObject download an full or part object from bucket
 // getobject returns a reader, length and nil for no errors
 // with errors getobject will return nil reader, length and typed errors
@@ -463,14 +476,176 @@ func (f *fsClient) MakeBucket() *probe.Error {
 	return nil
 }
 
-// GetBucketACL - get bucket acl
+// GetBucketACL - get canned acl of a folder from its permission bits
 func (f *fsClient) GetBucketACL() (acl string, error *probe.Error) {
-	return "", probe.NewError(client.APINotImplemented{API: "GetBucketACL", APIType: "filesystem"})
+	st, err := f.fsStat()
+	if err != nil {
+		return "", err.Trace(f.Path)
+	}
+	return mode2ACL(st.Mode().Perm()), nil
 }
 
-// SetBucketACL - create a new bucket acl
+// SetBucketACL - set permission bits from a canned acl on a folder and everything below it,
+// symlinks are not followed
 func (f *fsClient) SetBucketACL(acl string) *probe.Error {
-	return probe.NewError(client.APINotImplemented{API: "SetBucketACL", APIType: "filesystem"})
+	if _, ok := acl2Mode(acl, 0); !ok {
+		return probe.NewError(client.InvalidACLType{ACL: acl})
+	}
+	if _, err := f.fsStat(); err != nil {
+		return err.Trace(f.Path)
+	}
+	walkFn := func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			return nil
+		}
+		mode, _ := acl2Mode(acl, fi.Mode())
+		return os.Chmod(fp, mode)
+	}
+	if e := filepath.Walk(f.Path, walkFn); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// GetBucketPolicy - get bucket policy
+func (f *fsClient) GetBucketPolicy() (policy string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: "filesystem"})
+}
+
+// SetBucketPolicy - set bucket policy
+func (f *fsClient) SetBucketPolicy(policy string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: "filesystem"})
+}
+
+// RemoveBucketPolicy - remove bucket policy
+func (f *fsClient) RemoveBucketPolicy() *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RemoveBucketPolicy", APIType: "filesystem"})
+}
+
+// GetBucketLifecycle - get bucket lifecycle configuration
+func (f *fsClient) GetBucketLifecycle() (lifecycle string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketLifecycle", APIType: "filesystem"})
+}
+
+// SetBucketLifecycle - set bucket lifecycle configuration
+func (f *fsClient) SetBucketLifecycle(lifecycle string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketLifecycle", APIType: "filesystem"})
+}
+
+// RemoveBucketLifecycle - remove bucket lifecycle configuration
+func (f *fsClient) RemoveBucketLifecycle() *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RemoveBucketLifecycle", APIType: "filesystem"})
+}
+
+// GetBucketNotification - get bucket notification configuration
+func (f *fsClient) GetBucketNotification() (notification string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketNotification", APIType: "filesystem"})
+}
+
+// SetBucketNotification - set bucket notification configuration
+func (f *fsClient) SetBucketNotification(notification string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketNotification", APIType: "filesystem"})
+}
+
+// GetBucketVersioning - get bucket versioning status
+func (f *fsClient) GetBucketVersioning() (status string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: "filesystem"})
+}
+
+// SetBucketVersioning - set bucket versioning status
+func (f *fsClient) SetBucketVersioning(status string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: "filesystem"})
+}
+
+// ListVersions - list object versions
+func (f *fsClient) ListVersions(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel, 1)
+	contentCh <- client.ContentOnChannel{Err: probe.NewError(client.APINotImplemented{API: "ListVersions", APIType: "filesystem"})}
+	close(contentCh)
+	return contentCh
+}
+
+// GetObjectTags - get object tags
+func (f *fsClient) GetObjectTags() (tags map[string]string, error *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "GetObjectTags", APIType: "filesystem"})
+}
+
+// SetObjectTags - set object tags
+func (f *fsClient) SetObjectTags(tags map[string]string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetObjectTags", APIType: "filesystem"})
+}
+
+// RemoveObjectTags - remove object tags
+func (f *fsClient) RemoveObjectTags() *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RemoveObjectTags", APIType: "filesystem"})
+}
+
+// acl2Mode - map a canned acl to the unix permission bits replacing those of
+// current, authenticated users are approximated by the group. Write access for
+// everyone is granted to the group only, others never get write permission.
+// Directories are searchable wherever they are readable, files keep their
+// execute bits for the classes which may read them.
+func acl2Mode(acl string, current os.FileMode) (os.FileMode, bool) {
+	var mode os.FileMode
+	switch acl {
+	case "private":
+		mode = 0600
+	case "authenticated-read":
+		mode = 0640
+	case "public-read":
+		mode = 0644
+	case "public-read-write":
+		mode = 0664
+	default:
+		return 0, false
+	}
+	execMask := (mode & 0444) >> 2
+	if current.IsDir() {
+		return mode | execMask, true
+	}
+	return mode | current.Perm()&execMask, true
+}
+
+// mode2ACL - map unix permission bits to the closest canned acl
+func mode2ACL(mode os.FileMode) string {
+	switch {
+	case mode&0002 != 0, mode&0020 != 0 && mode&0004 != 0:
+		return "public-read-write"
+	case mode&0004 != 0:
+		return "public-read"
+	case mode&0040 != 0:
+		return "authenticated-read"
+	default:
+		return "private"
+	}
+}
+
+// GetObjectACL - get canned acl of a file from its permission bits
+func (f *fsClient) GetObjectACL() (acl string, error *probe.Error) {
+	st, err := f.fsStat()
+	if err != nil {
+		return "", err.Trace(f.Path)
+	}
+	return mode2ACL(st.Mode().Perm()), nil
+}
+
+// SetObjectACL - set permission bits of a file from a canned acl
+func (f *fsClient) SetObjectACL(acl string) *probe.Error {
+	st, err := f.fsStat()
+	if err != nil {
+		return err.Trace(f.Path)
+	}
+	mode, ok := acl2Mode(acl, st.Mode())
+	if !ok {
+		return probe.NewError(client.InvalidACLType{ACL: acl})
+	}
+	if e := os.Chmod(f.Path, mode); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
 }
 
 // getFSMetadata -
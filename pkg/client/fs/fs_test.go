@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/client/fs"
@@ -161,7 +162,7 @@ func (s *MySuite) TestStatBucket(c *C) {
 	c.Assert(perr, IsNil)
 }
 
-func (s *MySuite) TestBucketACLFails(c *C) {
+func (s *MySuite) TestBucketACL(c *C) {
 	root, err := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(err, IsNil)
 	defer os.RemoveAll(root)
@@ -171,14 +172,119 @@ func (s *MySuite) TestBucketACLFails(c *C) {
 	c.Assert(perr, IsNil)
 	perr = fsc.MakeBucket()
 	c.Assert(perr, IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(bucketPath, "folder"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(bucketPath, "folder", "object"), []byte("hello"), 0600), IsNil)
+
+	modes := map[string][2]os.FileMode{
+		"private":            {0700, 0600},
+		"authenticated-read": {0750, 0640},
+		"public-read":        {0755, 0644},
+		"public-read-write":  {0775, 0664},
+	}
+	for acl, mode := range modes {
+		perr = fsc.SetBucketACL(acl)
+		c.Assert(perr, IsNil)
+		for _, dir := range []string{bucketPath, filepath.Join(bucketPath, "folder")} {
+			st, err := os.Stat(dir)
+			c.Assert(err, IsNil)
+			c.Assert(st.Mode().Perm(), Equals, mode[0])
+		}
+		st, err := os.Stat(filepath.Join(bucketPath, "folder", "object"))
+		c.Assert(err, IsNil)
+		c.Assert(st.Mode().Perm(), Equals, mode[1])
 
-	perr = fsc.SetBucketACL("private")
+		bucketACL, perr := fsc.GetBucketACL()
+		c.Assert(perr, IsNil)
+		c.Assert(bucketACL, Equals, acl)
+	}
+
+	perr = fsc.SetBucketACL("invalid")
 	c.Assert(perr, Not(IsNil))
 
+	fsc, perr = fs.New(filepath.Join(root, "missing"))
+	c.Assert(perr, IsNil)
 	_, perr = fsc.GetBucketACL()
 	c.Assert(perr, Not(IsNil))
 }
 
+func (s *MySuite) TestObjectACL(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, perr := fs.New(objectPath)
+	c.Assert(perr, IsNil)
+	perr = fsc.PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	modes := map[string]os.FileMode{
+		"private":            0600,
+		"authenticated-read": 0640,
+		"public-read":        0644,
+		"public-read-write":  0664,
+	}
+	for acl, mode := range modes {
+		perr = fsc.SetObjectACL(acl)
+		c.Assert(perr, IsNil)
+		st, err := os.Stat(objectPath)
+		c.Assert(err, IsNil)
+		c.Assert(st.Mode().Perm(), Equals, mode)
+
+		objectACL, perr := fsc.GetObjectACL()
+		c.Assert(perr, IsNil)
+		c.Assert(objectACL, Equals, acl)
+	}
+
+	// folders stay searchable
+	fsc, perr = fs.New(root)
+	c.Assert(perr, IsNil)
+	perr = fsc.SetObjectACL("public-read")
+	c.Assert(perr, IsNil)
+	st, err := os.Stat(root)
+	c.Assert(err, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0755))
+
+	perr = fsc.SetObjectACL("invalid")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *MySuite) TestACLExecutable(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	scriptPath := filepath.Join(root, "script.sh")
+	c.Assert(ioutil.WriteFile(scriptPath, []byte("#!/bin/sh\n"), 0755), IsNil)
+	fsc, perr := fs.New(root)
+	c.Assert(perr, IsNil)
+
+	// execute bits are kept for the classes which may read, others never get write permission.
+	modes := map[string]os.FileMode{
+		"public-read-write":  0775,
+		"public-read":        0755,
+		"authenticated-read": 0750,
+		"private":            0700,
+	}
+	for _, acl := range []string{"public-read-write", "public-read", "authenticated-read", "private"} {
+		perr = fsc.SetBucketACL(acl)
+		c.Assert(perr, IsNil)
+		st, err := os.Stat(scriptPath)
+		c.Assert(err, IsNil)
+		c.Assert(st.Mode().Perm(), Equals, modes[acl])
+	}
+
+	// execute bits which were not set are not granted.
+	c.Assert(os.Chmod(scriptPath, 0700), IsNil)
+	fsc, perr = fs.New(scriptPath)
+	c.Assert(perr, IsNil)
+	perr = fsc.SetObjectACL("public-read-write")
+	c.Assert(perr, IsNil)
+	st, err := os.Stat(scriptPath)
+	c.Assert(err, IsNil)
+	c.Assert(st.Mode().Perm(), Equals, os.FileMode(0764))
+}
+
 func (s *MySuite) TestPutObject(c *C) {
 	root, err := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(err, IsNil)
@@ -195,6 +301,26 @@ func (s *MySuite) TestPutObject(c *C) {
 	c.Assert(perr, IsNil)
 }
 
+func (s *MySuite) TestRemove(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	objectPath := filepath.Join(root, "object")
+	fsc, perr := fs.New(objectPath)
+	c.Assert(perr, IsNil)
+	perr = fsc.PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(perr, IsNil)
+
+	perr = fsc.Remove()
+	c.Assert(perr, IsNil)
+	_, err = os.Stat(objectPath)
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	perr = fsc.Remove()
+	c.Assert(perr, Not(IsNil))
+}
+
 func (s *MySuite) TestGetObject(c *C) {
 	root, err := ioutil.TempDir(os.TempDir(), "fs-")
 	c.Assert(err, IsNil)
@@ -262,3 +388,62 @@ func (s *MySuite) TestStatObject(c *C) {
 	c.Assert(content.Name, Equals, objectPath)
 	c.Assert(content.Size, Equals, int64(dataLen))
 }
+
+func (s *MySuite) TestWatch(c *C) {
+	root, err := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(root)
+
+	fsc, perr := fs.New(root + string(os.PathSeparator))
+	c.Assert(perr, IsNil)
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	eventCh, perr := fsc.Watch(true, doneCh)
+	if perr != nil {
+		if _, ok := perr.ToGoError().(client.APINotImplemented); ok {
+			c.Skip("inotify is not available on this platform")
+		}
+	}
+	c.Assert(perr, IsNil)
+
+	nextEvent := func() *client.Event {
+		select {
+		case event := <-eventCh:
+			c.Assert(event.Err, IsNil)
+			return event.Event
+		case <-time.After(5 * time.Second):
+			c.Fatal("timed out waiting for event")
+		}
+		return nil
+	}
+
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "object"), []byte("hello"), 0600), IsNil)
+	event := nextEvent()
+	c.Assert(event.Type, Equals, client.EventCreate)
+	c.Assert(event.Name, Equals, "object")
+	c.Assert(event.Size, Equals, int64(5))
+
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "object"), []byte("hello world"), 0600), IsNil)
+	event = nextEvent()
+	c.Assert(event.Type, Equals, client.EventModify)
+	c.Assert(event.Size, Equals, int64(11))
+
+	// new folders are watched as well
+	c.Assert(os.Mkdir(filepath.Join(root, "folder"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "folder", "nested"), []byte("hello"), 0600), IsNil)
+	event = nextEvent()
+	c.Assert(event.Type, Equals, client.EventCreate)
+	c.Assert(event.Name, Equals, filepath.Join("folder", "nested"))
+
+	c.Assert(os.Remove(filepath.Join(root, "object")), IsNil)
+	event = nextEvent()
+	c.Assert(event.Type, Equals, client.EventRemove)
+	c.Assert(event.Name, Equals, "object")
+
+	// only folders can be watched
+	fsc, perr = fs.New(filepath.Join(root, "folder", "nested"))
+	c.Assert(perr, IsNil)
+	_, perr = fsc.Watch(false, doneCh)
+	c.Assert(perr, Not(IsNil))
+}
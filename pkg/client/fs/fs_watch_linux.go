@@ -0,0 +1,245 @@
+// +build linux
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO |
+	syscall.IN_MOVED_FROM | syscall.IN_DELETE | syscall.IN_DELETE_SELF
+
+// inotifyWatcher - translates inotify events of the watched folders into object change events
+type inotifyWatcher struct {
+	fd        int
+	file      *os.File
+	root      string
+	recursive bool
+	folders   map[int32]string // watch descriptors to watched folders
+	created   map[string]bool  // files created but not yet closed after writing
+	listed    map[string]bool  // files reported while adding a folder, their inotify events may follow
+	eventCh   chan client.EventOnChannel
+	doneCh    <-chan struct{}
+}
+
+// Watch - stream changes of the files below a folder, uses inotify
+func (f *fsClient) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventOnChannel, *probe.Error) {
+	st, err := f.fsStat()
+	if err != nil {
+		return nil, err.Trace(f.Path)
+	}
+	if !st.IsDir() {
+		return nil, probe.NewError(client.InvalidArgument{}).Trace(f.Path)
+	}
+	fd, e := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if e != nil {
+		return nil, probe.NewError(os.NewSyscallError("inotify_init1", e))
+	}
+	w := &inotifyWatcher{
+		fd: fd,
+		// a non blocking descriptor is handled by the runtime poller, closing the file unblocks reads
+		file:      os.NewFile(uintptr(fd), "inotify"),
+		root:      strings.TrimSuffix(f.Path, string(f.URL().Separator)) + string(f.URL().Separator),
+		recursive: recursive,
+		folders:   make(map[int32]string),
+		created:   make(map[string]bool),
+		listed:    make(map[string]bool),
+		eventCh:   make(chan client.EventOnChannel),
+		doneCh:    doneCh,
+	}
+	if err := w.addFolder(strings.TrimSuffix(w.root, string(f.URL().Separator)), false); err != nil {
+		w.file.Close()
+		return nil, err.Trace(f.Path)
+	}
+	exitCh := make(chan struct{})
+	go func() {
+		select {
+		case <-doneCh:
+		case <-exitCh:
+		}
+		w.file.Close()
+	}()
+	go func() {
+		defer close(w.eventCh)
+		defer close(exitCh)
+		w.readEvents()
+	}()
+	return w.eventCh, nil
+}
+
+// send - deliver an event unless the caller is done, returns false once the caller is done
+func (w *inotifyWatcher) send(event client.EventOnChannel) bool {
+	select {
+	case w.eventCh <- event:
+		return true
+	case <-w.doneCh:
+		return false
+	}
+}
+
+// sendEvent - deliver a change of the file at path
+func (w *inotifyWatcher) sendEvent(eventType client.EventType, path string) bool {
+	event := &client.Event{
+		Type: eventType,
+		Time: time.Now().UTC(),
+		Name: strings.TrimPrefix(path, w.root),
+	}
+	if eventType != client.EventRemove {
+		st, err := os.Lstat(path)
+		if err != nil {
+			// already gone again, its removal follows
+			return true
+		}
+		event.Size = st.Size()
+	}
+	return w.send(client.EventOnChannel{Event: event})
+}
+
+// addFolder - watch folder, and all its subfolders if recursive. Files found in folders
+// appearing while watching are reported as created.
+func (w *inotifyWatcher) addFolder(folder string, existing bool) *probe.Error {
+	walkFn := func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if path != folder && (os.IsNotExist(err) || os.IsPermission(err)) {
+				return nil
+			}
+			return err
+		}
+		if !fi.IsDir() {
+			if existing && fi.Mode().IsRegular() {
+				w.listed[path] = true
+				w.sendEvent(client.EventCreate, path)
+			}
+			return nil
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			if path != folder {
+				return nil
+			}
+			return os.NewSyscallError("inotify_add_watch", err)
+		}
+		w.folders[int32(wd)] = path
+		return nil
+	}
+	if !w.recursive {
+		wd, err := syscall.InotifyAddWatch(w.fd, folder, inotifyMask)
+		if err != nil {
+			return probe.NewError(os.NewSyscallError("inotify_add_watch", err))
+		}
+		w.folders[int32(wd)] = folder
+		return nil
+	}
+	if err := filepath.Walk(folder, walkFn); err != nil {
+		return probe.NewError(err)
+	}
+	return nil
+}
+
+// readEvents - read and translate inotify events until the watcher is closed or the watched folder is gone
+func (w *inotifyWatcher) readEvents() {
+	var buf [syscall.SizeofInotifyEvent * 4096]byte
+	for {
+		n, err := w.file.Read(buf[:])
+		if err != nil {
+			select {
+			case <-w.doneCh:
+			default:
+				w.send(client.EventOnChannel{Err: probe.NewError(err)})
+			}
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+int(raw.Len)]), "\x00")
+			offset = nameStart + int(raw.Len)
+			if !w.handleEvent(raw.Wd, raw.Mask, name) {
+				return
+			}
+		}
+	}
+}
+
+// handleEvent - translate a single inotify event, returns false once watching ends
+func (w *inotifyWatcher) handleEvent(wd int32, mask uint32, name string) bool {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return w.send(client.EventOnChannel{Err: probe.NewError(os.NewSyscallError("inotify", syscall.EOVERFLOW))})
+	}
+	folder, ok := w.folders[wd]
+	if !ok {
+		return true
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		// watch removed along with its folder
+		delete(w.folders, wd)
+		return len(w.folders) > 0
+	}
+	if name == "" {
+		// events on the watched folder itself
+		return true
+	}
+	path := filepath.Join(folder, name)
+	if mask&syscall.IN_ISDIR != 0 {
+		if w.recursive && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+			if err := w.addFolder(path, true); err != nil {
+				return w.send(client.EventOnChannel{Err: err.Trace(path)})
+			}
+		}
+		return true
+	}
+	switch {
+	case mask&syscall.IN_CREATE != 0 && w.listed[path]:
+		// created after the folder was watched but before it was walked, already reported
+		w.created[path] = false
+	case mask&syscall.IN_CREATE != 0:
+		st, err := os.Lstat(path)
+		if err == nil && !st.Mode().IsRegular() {
+			// symlinks and special files are never written to
+			return w.sendEvent(client.EventCreate, path)
+		}
+		w.created[path] = true
+	case mask&syscall.IN_CLOSE_WRITE != 0:
+		created, ok := w.created[path]
+		delete(w.created, path)
+		delete(w.listed, path)
+		switch {
+		case ok && !created:
+			return true
+		case created:
+			return w.sendEvent(client.EventCreate, path)
+		}
+		return w.sendEvent(client.EventModify, path)
+	case mask&syscall.IN_MOVED_TO != 0:
+		return w.sendEvent(client.EventCreate, path)
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		delete(w.created, path)
+		delete(w.listed, path)
+		return w.sendEvent(client.EventRemove, path)
+	}
+	return true
+}
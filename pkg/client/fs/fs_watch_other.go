@@ -0,0 +1,29 @@
+// +build !linux
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fs
+
+import (
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Watch - inotify is only available on linux, callers fall back to comparing listings
+func (f *fsClient) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventOnChannel, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "Watch", APIType: "filesystem"})
+}
@@ -0,0 +1,81 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import "encoding/xml"
+
+type lifecycleRule struct {
+	ID     string
+	Prefix *string
+	Filter *struct {
+		Prefix string
+	}
+	Status     string
+	Expiration *struct {
+		Days int
+		Date string
+	}
+	AbortIncompleteMultipartUpload *struct {
+		DaysAfterInitiation int
+	}
+	Transition                  *struct{}
+	NoncurrentVersionExpiration *struct{}
+	NoncurrentVersionTransition *struct{}
+}
+
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+// isValidLifecycle checks a lifecycle configuration the way S3 does for the rules mc sends,
+// every rule needs a status, a prefix and at least one action
+func isValidLifecycle(data []byte) bool {
+	var config lifecycleConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return false
+	}
+	if len(config.Rules) == 0 || len(config.Rules) > 1000 {
+		return false
+	}
+	ids := make(map[string]bool)
+	for _, rule := range config.Rules {
+		if rule.Status != "Enabled" && rule.Status != "Disabled" {
+			return false
+		}
+		if (rule.Prefix == nil) == (rule.Filter == nil) {
+			return false
+		}
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return false
+			}
+			ids[rule.ID] = true
+		}
+		if rule.Expiration != nil && rule.Expiration.Days <= 0 && rule.Expiration.Date == "" {
+			return false
+		}
+		if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+			return false
+		}
+		if rule.Expiration == nil && rule.AbortIncompleteMultipartUpload == nil && rule.Transition == nil &&
+			rule.NoncurrentVersionExpiration == nil && rule.NoncurrentVersionTransition == nil {
+			return false
+		}
+	}
+	return true
+}
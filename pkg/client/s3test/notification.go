@@ -0,0 +1,219 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+type notificationFilterRule struct {
+	Name  string
+	Value string
+}
+
+// notificationConfig is a QueueConfiguration, TopicConfiguration or CloudFunctionConfiguration
+type notificationConfig struct {
+	XMLName       xml.Name
+	ID            string `xml:"Id"`
+	Queue         string
+	Topic         string
+	CloudFunction string
+	Events        []string                 `xml:"Event"`
+	FilterRules   []notificationFilterRule `xml:"Filter>S3Key>FilterRule"`
+}
+
+type notificationConfiguration struct {
+	XMLName xml.Name             `xml:"NotificationConfiguration"`
+	Configs []notificationConfig `xml:",any"`
+}
+
+// notificationEvents are the event types a notification can be configured for
+var notificationEvents = []string{
+	"s3:ObjectCreated:*",
+	"s3:ObjectCreated:Put",
+	"s3:ObjectCreated:Post",
+	"s3:ObjectCreated:Copy",
+	"s3:ObjectCreated:CompleteMultipartUpload",
+	"s3:ObjectRemoved:*",
+	"s3:ObjectRemoved:Delete",
+	"s3:ObjectRemoved:DeleteMarkerCreated",
+	"s3:ObjectAccessed:*",
+	"s3:ObjectAccessed:Get",
+	"s3:ObjectAccessed:Head",
+	"s3:ReducedRedundancyLostObject",
+}
+
+func isValidNotificationEvent(event string) bool {
+	for _, e := range notificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// eventsOverlap - two event types overlap if they are equal or one is a wildcard for the other
+func eventsOverlap(a, b string) bool {
+	switch {
+	case a == b:
+		return true
+	case strings.HasSuffix(a, ":*"):
+		return strings.HasPrefix(b, strings.TrimSuffix(a, "*"))
+	case strings.HasSuffix(b, ":*"):
+		return strings.HasPrefix(a, strings.TrimSuffix(b, "*"))
+	}
+	return false
+}
+
+// arn returns the destination of a configuration along with the service it has to belong to
+func (n notificationConfig) arn() (arn, service string) {
+	switch n.XMLName.Local {
+	case "QueueConfiguration":
+		return n.Queue, "sqs"
+	case "TopicConfiguration":
+		return n.Topic, "sns"
+	case "CloudFunctionConfiguration":
+		return n.CloudFunction, "lambda"
+	}
+	return "", ""
+}
+
+// filter returns the prefix and suffix filter rules, ok is false for unknown or repeated rules
+func (n notificationConfig) filter() (prefix, suffix string, ok bool) {
+	var hasPrefix, hasSuffix bool
+	for _, rule := range n.FilterRules {
+		switch strings.ToLower(rule.Name) {
+		case "prefix":
+			if hasPrefix {
+				return "", "", false
+			}
+			prefix, hasPrefix = rule.Value, true
+		case "suffix":
+			if hasSuffix {
+				return "", "", false
+			}
+			suffix, hasSuffix = rule.Value, true
+		default:
+			return "", "", false
+		}
+	}
+	return prefix, suffix, true
+}
+
+// overlaps - configurations overlap if they share an event type for some object name
+func (n notificationConfig) overlaps(m notificationConfig) bool {
+	nPrefix, nSuffix, _ := n.filter()
+	mPrefix, mSuffix, _ := m.filter()
+	if !strings.HasPrefix(nPrefix, mPrefix) && !strings.HasPrefix(mPrefix, nPrefix) {
+		return false
+	}
+	if !strings.HasSuffix(nSuffix, mSuffix) && !strings.HasSuffix(mSuffix, nSuffix) {
+		return false
+	}
+	for _, a := range n.Events {
+		for _, b := range m.Events {
+			if eventsOverlap(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isValidNotification validates destinations, event types and filter rules of every configuration
+func isValidNotification(config notificationConfiguration) *apiError {
+	ids := make(map[string]bool)
+	for i, n := range config.Configs {
+		arn, service := n.arn()
+		// arn:partition:service:region:account-id:resource
+		splits := strings.SplitN(arn, ":", 6)
+		if len(splits) != 6 || splits[0] != "arn" || splits[2] != service || splits[5] == "" {
+			return errInvalidNotificationDestination
+		}
+		if len(n.Events) == 0 {
+			return errInvalidNotificationDestination
+		}
+		for _, event := range n.Events {
+			if !isValidNotificationEvent(event) {
+				return errInvalidNotificationDestination
+			}
+		}
+		if _, _, ok := n.filter(); !ok {
+			return errInvalidNotificationFilter
+		}
+		if n.ID != "" {
+			if ids[n.ID] {
+				return errInvalidNotificationDestination
+			}
+			ids[n.ID] = true
+		}
+		for _, m := range config.Configs[:i] {
+			if n.overlaps(m) {
+				return errOverlappingNotification
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) getBucketNotification(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if len(b.notification) == 0 {
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"NotificationConfiguration"`
+			Xmlns   string   `xml:"xmlns,attr"`
+		}{Xmlns: s3Namespace})
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.notification)
+}
+
+func (s *Server) putBucketNotification(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	var config notificationConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	for _, n := range config.Configs {
+		if arn, _ := n.arn(); arn == "" {
+			writeError(w, r, errMalformedXML)
+			return
+		}
+	}
+	if err := isValidNotification(config); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	b.notification = nil
+	if len(config.Configs) > 0 {
+		b.notification = body
+	}
+	w.WriteHeader(http.StatusOK)
+}
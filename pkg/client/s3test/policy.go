@@ -0,0 +1,219 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// stringSet is a policy element which may either be a single string or a list of strings
+type stringSet []string
+
+func (s *stringSet) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = stringSet{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = stringSet(list)
+	return nil
+}
+
+// principal is either "*" or {"AWS": ...}
+type principal struct {
+	AWS stringSet
+}
+
+func (p *principal) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		p.AWS = stringSet{single}
+		return nil
+	}
+	var aws struct {
+		AWS stringSet
+	}
+	if err := json.Unmarshal(data, &aws); err != nil {
+		return err
+	}
+	p.AWS = aws.AWS
+	return nil
+}
+
+type statement struct {
+	Effect    string
+	Principal principal
+	Action    stringSet
+	Resource  stringSet
+	Condition map[string]map[string]stringSet
+}
+
+type bucketPolicy struct {
+	Version   string
+	Statement []statement
+}
+
+func parsePolicy(data []byte) (bucketPolicy, error) {
+	var policy bucketPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return bucketPolicy{}, err
+	}
+	if len(policy.Statement) == 0 {
+		return bucketPolicy{}, errors.New("policy has no statements")
+	}
+	for _, st := range policy.Statement {
+		if st.Effect != "Allow" && st.Effect != "Deny" {
+			return bucketPolicy{}, errors.New("invalid effect " + st.Effect)
+		}
+		if len(st.Action) == 0 || len(st.Resource) == 0 {
+			return bucketPolicy{}, errors.New("statement without action or resource")
+		}
+	}
+	return policy, nil
+}
+
+// wildcardMatch matches a pattern where '*' matches any sequence of characters
+func wildcardMatch(pattern, text string) bool {
+	if pattern == "" {
+		return text == ""
+	}
+	if pattern[0] == '*' {
+		for i := 0; i <= len(text); i++ {
+			if wildcardMatch(pattern[1:], text[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return text != "" && pattern[0] == text[0] && wildcardMatch(pattern[1:], text[1:])
+}
+
+func matchesAny(patterns stringSet, value string) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAction maps a request to its policy action and resource
+func requestAction(r *http.Request) (action, resource string) {
+	bucketName, objectName := path2BucketAndObject(r.URL.Path)
+	query := r.URL.Query()
+	_, isUploads := query["uploads"]
+	_, isUploadID := query["uploadId"]
+	_, isLocation := query["location"]
+	if objectName == "" {
+		resource = "arn:aws:s3:::" + bucketName
+		switch {
+		case r.Method == "GET" && isUploads:
+			return "s3:ListBucketMultipartUploads", resource
+		case r.Method == "GET" && isLocation:
+			return "s3:GetBucketLocation", resource
+		case r.Method == "GET" || r.Method == "HEAD":
+			return "s3:ListBucket", resource
+		}
+		return "", resource
+	}
+	resource = "arn:aws:s3:::" + bucketName + "/" + objectName
+	switch {
+	case r.Method == "GET" && isUploadID:
+		return "s3:ListMultipartUploadParts", resource
+	case r.Method == "GET" || r.Method == "HEAD":
+		return "s3:GetObject", resource
+	case r.Method == "PUT", r.Method == "POST":
+		return "s3:PutObject", resource
+	case r.Method == "DELETE" && isUploadID:
+		return "s3:AbortMultipartUpload", resource
+	case r.Method == "DELETE":
+		return "s3:DeleteObject", resource
+	}
+	return "", resource
+}
+
+// conditionsMatch supports the StringEquals and StringLike operators
+func conditionsMatch(r *http.Request, conditions map[string]map[string]stringSet) bool {
+	query := r.URL.Query()
+	for operator, keys := range conditions {
+		for key, values := range keys {
+			var value string
+			switch key {
+			case "s3:prefix":
+				value = query.Get("prefix")
+			case "s3:delimiter":
+				value = query.Get("delimiter")
+			default:
+				return false
+			}
+			switch operator {
+			case "StringEquals":
+				found := false
+				for _, v := range values {
+					if v == value {
+						found = true
+					}
+				}
+				if !found {
+					return false
+				}
+			case "StringLike":
+				if !matchesAny(values, value) {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isAllowedByPolicy evaluates the bucket policy for an anonymous request, explicit denies win
+func isAllowedByPolicy(r *http.Request, data []byte) bool {
+	if data == nil {
+		return false
+	}
+	policy, err := parsePolicy(data)
+	if err != nil {
+		return false
+	}
+	action, resource := requestAction(r)
+	if action == "" {
+		return false
+	}
+	allowed := false
+	for _, st := range policy.Statement {
+		if !matchesAny(st.Principal.AWS, "*") || !matchesAny(st.Action, action) || !matchesAny(st.Resource, resource) {
+			continue
+		}
+		if !conditionsMatch(r, st.Condition) {
+			continue
+		}
+		if st.Effect == "Deny" {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
@@ -0,0 +1,271 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// Grantee URIs used to express canned ACLs.
+const (
+	allUsersURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+type owner struct {
+	ID          string
+	DisplayName string
+}
+
+// the only owner this server knows about
+var defaultOwner = owner{ID: "minio", DisplayName: "minio"}
+
+type bucketEntry struct {
+	Name         string
+	CreationDate time.Time
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Owner   owner
+	Buckets struct {
+		Bucket []bucketEntry
+	}
+}
+
+type commonPrefix struct {
+	Prefix string
+}
+
+type objectEntry struct {
+	Key          string
+	LastModified time.Time
+	ETag         string
+	Size         int64
+	Owner        owner
+	StorageClass string
+}
+
+type listBucketResult struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	Name           string
+	Prefix         string
+	Marker         string
+	NextMarker     string
+	MaxKeys        int
+	Delimiter      string
+	IsTruncated    bool
+	Contents       []objectEntry
+	CommonPrefixes []commonPrefix
+}
+
+type grantee struct {
+	ID          string `xml:",omitempty"`
+	DisplayName string `xml:",omitempty"`
+	URI         string `xml:",omitempty"`
+}
+
+type grant struct {
+	Grantee    grantee
+	Permission string
+}
+
+type accessControlPolicy struct {
+	XMLName           xml.Name `xml:"AccessControlPolicy"`
+	Xmlns             string   `xml:"xmlns,attr"`
+	Owner             owner
+	AccessControlList struct {
+		Grant []grant
+	}
+}
+
+type locationConstraint struct {
+	XMLName  xml.Name `xml:"LocationConstraint"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:",chardata"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string
+	Key      string
+	UploadID string `xml:"UploadId"`
+}
+
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+type completeMultipartUpload struct {
+	Parts []completePart `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string
+	Bucket   string
+	Key      string
+	ETag     string
+}
+
+type partEntry struct {
+	PartNumber   int
+	LastModified time.Time
+	ETag         string
+	Size         int64
+}
+
+type listPartsResult struct {
+	XMLName              xml.Name `xml:"ListPartsResult"`
+	Xmlns                string   `xml:"xmlns,attr"`
+	Bucket               string
+	Key                  string
+	UploadID             string `xml:"UploadId"`
+	Initiator            owner
+	Owner                owner
+	StorageClass         string
+	PartNumberMarker     int
+	NextPartNumberMarker int
+	MaxParts             int
+	IsTruncated          bool
+	Parts                []partEntry `xml:"Part"`
+}
+
+type uploadEntry struct {
+	Key          string
+	UploadID     string `xml:"UploadId"`
+	Initiator    owner
+	Owner        owner
+	StorageClass string
+	Initiated    time.Time
+}
+
+type listMultipartUploadsResult struct {
+	XMLName            xml.Name `xml:"ListMultipartUploadsResult"`
+	Xmlns              string   `xml:"xmlns,attr"`
+	Bucket             string
+	KeyMarker          string
+	UploadIDMarker     string `xml:"UploadIdMarker"`
+	NextKeyMarker      string
+	NextUploadIDMarker string `xml:"NextUploadIdMarker"`
+	Prefix             string
+	MaxUploads         int
+	IsTruncated        bool
+	Uploads            []uploadEntry `xml:"Upload"`
+}
+
+// apiError is an S3 error code along with its http status
+type apiError struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+// errorResponse is the xml body sent along with every failed request
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string
+	Message   string
+	Resource  string
+	RequestID string `xml:"RequestId"`
+	HostID    string `xml:"HostId"`
+}
+
+var (
+	errAccessDenied                   = &apiError{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	errBadDigest                      = &apiError{"BadDigest", "The Content-MD5 you specified did not match what we received.", http.StatusBadRequest}
+	errBucketAlreadyExists            = &apiError{"BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.", http.StatusConflict}
+	errBucketNotEmpty                 = &apiError{"BucketNotEmpty", "The bucket you tried to delete is not empty.", http.StatusConflict}
+	errEntityTooLarge                 = &apiError{"EntityTooLarge", "Your proposed upload exceeds the maximum allowed object size.", http.StatusBadRequest}
+	errExpiredRequest                 = &apiError{"AccessDenied", "Request has expired.", http.StatusForbidden}
+	errInvalidAccessKeyID             = &apiError{"InvalidAccessKeyId", "The access key ID you provided does not exist in our records.", http.StatusForbidden}
+	errInvalidToken                   = &apiError{"InvalidToken", "The provided token is malformed or otherwise invalid.", http.StatusBadRequest}
+	errInvalidArgument                = &apiError{"InvalidArgument", "Invalid argument.", http.StatusBadRequest}
+	errInvalidBucketName              = &apiError{"InvalidBucketName", "The specified bucket is not valid.", http.StatusBadRequest}
+	errInvalidPart                    = &apiError{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	errInvalidNotificationDestination = &apiError{"InvalidArgument", "Unable to validate the following destination configurations.", http.StatusBadRequest}
+	errInvalidNotificationFilter      = &apiError{"InvalidArgument", "Cannot specify more than one prefix or suffix rule in a filter.", http.StatusBadRequest}
+	errOverlappingNotification        = &apiError{"InvalidArgument", "Configurations overlap. Configurations on the same bucket cannot share a common event type.", http.StatusBadRequest}
+	errInvalidTag                     = &apiError{"InvalidTag", "The tag provided was not a valid tag.", http.StatusBadRequest}
+	errInvalidStorageClass            = &apiError{"InvalidStorageClass", "The storage class you specified is not valid.", http.StatusBadRequest}
+	errInvalidRange                   = &apiError{"InvalidRange", "The requested range is not satisfiable.", http.StatusRequestedRangeNotSatisfiable}
+	errMalformedPOSTRequest           = &apiError{"MalformedPOSTRequest", "The body of your POST request is not well-formed multipart/form-data.", http.StatusBadRequest}
+	errMalformedPolicy                = &apiError{"MalformedPolicy", "Policies must be valid JSON and the first byte must be '{'.", http.StatusBadRequest}
+	errMalformedXML                   = &apiError{"MalformedXML", "The XML you provided was not well-formed.", http.StatusBadRequest}
+	errMethodNotAllowed               = &apiError{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	errMissingDateHeader              = &apiError{"AccessDenied", "AWS authentication requires a valid Date or x-amz-date header.", http.StatusForbidden}
+	errNoSuchBucket                   = &apiError{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	errNoSuchBucketPolicy             = &apiError{"NoSuchBucketPolicy", "The bucket policy does not exist.", http.StatusNotFound}
+	errNoSuchLifecycleConfiguration   = &apiError{"NoSuchLifecycleConfiguration", "The lifecycle configuration does not exist.", http.StatusNotFound}
+	errMissingContentMD5              = &apiError{"InvalidRequest", "Missing required header for this request: Content-MD5.", http.StatusBadRequest}
+	errNoSuchKey                      = &apiError{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	errNoSuchUpload                   = &apiError{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	errNoSuchVersion                  = &apiError{"NoSuchVersion", "The specified version does not exist.", http.StatusNotFound}
+	errNotImplemented                 = &apiError{"NotImplemented", "A header you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	errPolicyConditionFailed          = &apiError{"AccessDenied", "Invalid according to Policy: Policy Condition failed.", http.StatusForbidden}
+	errSignatureDoesNotMatch          = &apiError{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	errContentSHA256Mismatch          = &apiError{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+)
+
+// writeError writes an S3 error response, HEAD requests only carry the status code
+func writeError(w http.ResponseWriter, r *http.Request, e *apiError) {
+	w.Header().Set("x-amz-request-id", "s3test")
+	if r.Method == "HEAD" {
+		w.WriteHeader(e.StatusCode)
+		return
+	}
+	response := errorResponse{
+		Code:      e.Code,
+		Message:   e.Message,
+		Resource:  r.URL.Path,
+		RequestID: "s3test",
+	}
+	data, err := xml.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(data)))
+	w.WriteHeader(e.StatusCode)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// writeXML writes a successful xml response
+func writeXML(w http.ResponseWriter, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
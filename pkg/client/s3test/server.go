@@ -0,0 +1,1286 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package s3test implements an in-memory S3 compatible server for tests.
+//
+// The server speaks enough of the S3 protocol to exercise the s3v2 and
+// s3v4 clients without network access: service, bucket and object
+// operations, multipart uploads, storage classes, canned ACLs, bucket policies, bucket
+// lifecycle and notification configuration, object versioning, object tagging,
+// presigned GET and PUT with response header overrides and browser based POST uploads. Every authenticated request is
+// verified against signature V2 or signature V4, anonymous requests are
+// only allowed on buckets with a public canned ACL or by a bucket policy.
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory S3 compatible server listening on a system-chosen
+// port on the local loopback interface
+type Server struct {
+	*httptest.Server
+
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken, if set, is required on every signed request like for temporary credentials
+	SessionToken string
+
+	mutex        *sync.Mutex
+	buckets      map[string]*bucket
+	uploads      map[string]*upload
+	nextUploadID int
+}
+
+type object struct {
+	data         []byte
+	acl          string
+	contentType  string
+	etag         string
+	lastModified time.Time
+	versionID    string
+	deleteMarker bool
+	tags         map[string]string
+	storageClass string
+	aclLocked    bool
+}
+
+type bucket struct {
+	acl          string
+	policy       []byte
+	lifecycle    []byte
+	notification []byte
+	created      time.Time
+	objects      map[string]*object
+
+	// versioning is empty until versioning is enabled, versions are kept oldest first
+	versioning    string
+	versions      map[string][]*object
+	nextVersionID int
+}
+
+type upload struct {
+	bucket       string
+	object       string
+	initiated    time.Time
+	parts        map[int]*object
+	storageClass string
+}
+
+// NewServer starts and returns a new server, requests are authenticated
+// with the given credentials. The caller should call Close when finished,
+// to shut it down.
+func NewServer(accessKeyID, secretAccessKey string) *Server {
+	s := &Server{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		mutex:           &sync.Mutex{},
+		buckets:         make(map[string]*bucket),
+		uploads:         make(map[string]*upload),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// MakeBucket creates a bucket with a canned acl, bypassing authentication
+func (s *Server) MakeBucket(bucketName, acl string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.buckets[bucketName] = &bucket{acl: acl, created: time.Now().UTC(), objects: make(map[string]*object)}
+}
+
+// PutObject stores an object in an existing bucket, bypassing authentication
+func (s *Server) PutObject(bucketName, objectName string, data []byte) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return false
+	}
+	b.storeObject(objectName, newObject(data, "application/octet-stream"))
+	return true
+}
+
+// GetObject returns the content of an object, if it exists
+func (s *Server) GetObject(bucketName, objectName string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return nil, false
+	}
+	return o.data, true
+}
+
+// BucketACL returns the canned acl of a bucket, if it exists
+func (s *Server) BucketACL(bucketName string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return "", false
+	}
+	return b.acl, true
+}
+
+// ObjectACL returns the canned acl of an object, if it exists
+func (s *Server) ObjectACL(bucketName, objectName string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return "", false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return "", false
+	}
+	return o.acl, true
+}
+
+// LockObjectACL makes changes to the acl of an object fail with access denied, if it exists
+func (s *Server) LockObjectACL(bucketName, objectName string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return false
+	}
+	o.aclLocked = true
+	return true
+}
+
+func newObject(data []byte, contentType string) *object {
+	md5Sum := md5.Sum(data)
+	return &object{
+		data:         data,
+		acl:          "private",
+		contentType:  contentType,
+		etag:         hex.EncodeToString(md5Sum[:]),
+		lastModified: time.Now().UTC(),
+	}
+}
+
+// ObjectStorageClass returns the storage class of an object, if it exists
+func (s *Server) ObjectStorageClass(bucketName, objectName string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return "", false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return "", false
+	}
+	return storageClassName(o.storageClass), true
+}
+
+// storageClassName - objects and uploads stored without a storage class are STANDARD
+func storageClassName(storageClass string) string {
+	if storageClass == "" {
+		return "STANDARD"
+	}
+	return storageClass
+}
+
+// requestStorageClass - storage class requested by the x-amz-storage-class header
+func requestStorageClass(r *http.Request) (string, *apiError) {
+	storageClass := r.Header.Get("x-amz-storage-class")
+	switch storageClass {
+	case "", "STANDARD", "REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA":
+		return storageClass, nil
+	}
+	return "", errInvalidStorageClass
+}
+
+// isValidBucketName - verify bucket name in accordance with
+//  - http://docs.aws.amazon.com/AmazonS3/latest/dev/UsingBucket.html
+func isValidBucketName(bucketName string) bool {
+	if len(bucketName) < 3 || len(bucketName) > 63 {
+		return false
+	}
+	match, _ := regexp.MatchString("^[a-z0-9][a-z0-9\\.\\-]+[a-z0-9]$", bucketName)
+	return match && !strings.Contains(bucketName, "..")
+}
+
+func isValidCannedACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read":
+		return true
+	}
+	return false
+}
+
+// path2BucketAndObject gives bucketName and objectName from an URL path
+func path2BucketAndObject(path string) (bucketName, objectName string) {
+	splits := strings.SplitN(path, "/", 3)
+	switch len(splits) {
+	case 0, 1:
+		bucketName = ""
+		objectName = ""
+	case 2:
+		bucketName = splits[1]
+		objectName = ""
+	case 3:
+		bucketName = splits[1]
+		objectName = splits[2]
+	}
+	return bucketName, objectName
+}
+
+// authenticate verifies the request signature, if there is one
+func (s *Server) authenticate(r *http.Request, body []byte) (authenticated bool, err *apiError) {
+	query := r.URL.Query()
+	authorization := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(authorization, signV4Algorithm):
+		err = s.verifyHeaderV4(r, body)
+	case strings.HasPrefix(authorization, "AWS "):
+		err = s.verifyHeaderV2(r)
+	case query.Get("X-Amz-Algorithm") != "":
+		err = s.verifyPresignedV4(r)
+	case query.Get("AWSAccessKeyId") != "":
+		err = s.verifyPresignedV2(r)
+	case authorization != "":
+		return false, errInvalidArgument
+	default:
+		return false, nil
+	}
+	if err == nil {
+		err = s.verifySessionToken(requestSessionToken(r))
+	}
+	return true, err
+}
+
+// requestSessionToken returns the session token of a request, sent as header or in the query of presigned requests
+func requestSessionToken(r *http.Request) string {
+	if token := r.Header.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	query := r.URL.Query()
+	if token := query.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	return query.Get("x-amz-security-token")
+}
+
+// verifySessionToken checks the token of a signed request when the server requires one
+func (s *Server) verifySessionToken(token string) *apiError {
+	if s.SessionToken != "" && token != s.SessionToken {
+		return errInvalidToken
+	}
+	return nil
+}
+
+// isAnonymousAllowed decides if an unsigned request is allowed by the bucket canned acl or policy
+func isAnonymousAllowed(r *http.Request, b *bucket) bool {
+	if b == nil {
+		return false
+	}
+	if isAllowedByPolicy(r, b.policy) {
+		return true
+	}
+	// object acls only ever widen read access
+	_, objectName := path2BucketAndObject(r.URL.Path)
+	_, isACL := r.URL.Query()["acl"]
+	if o, ok := b.objects[objectName]; ok && !isACL && (r.Method == "GET" || r.Method == "HEAD") {
+		if o.acl == "public-read" || o.acl == "public-read-write" {
+			return true
+		}
+	}
+	switch r.Method {
+	case "GET", "HEAD":
+		return b.acl == "public-read" || b.acl == "public-read-write"
+	case "PUT", "DELETE", "POST":
+		return b.acl == "public-read-write"
+	}
+	return false
+}
+
+// ServeHTTP dispatches S3 requests, implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, e := ioutil.ReadAll(r.Body)
+	if e != nil {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	bucketName, objectName := path2BucketAndObject(r.URL.Path)
+	authenticated, err := s.authenticate(r, body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// browser based uploads carry their signature in the form
+	isPostPolicy := r.Method == "POST" && objectName == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+	if !authenticated && !isPostPolicy && !isAnonymousAllowed(r, s.buckets[bucketName]) {
+		writeError(w, r, errAccessDenied)
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case bucketName == "":
+		switch r.Method {
+		case "GET":
+			s.listBuckets(w, r)
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+	case objectName == "":
+		_, isACL := query["acl"]
+		_, isUploads := query["uploads"]
+		_, isLocation := query["location"]
+		_, isPolicy := query["policy"]
+		_, isLifecycle := query["lifecycle"]
+		_, isVersioning := query["versioning"]
+		_, isVersions := query["versions"]
+		_, isNotification := query["notification"]
+		switch {
+		case r.Method == "GET" && isNotification:
+			s.getBucketNotification(w, r, bucketName)
+		case r.Method == "PUT" && isNotification:
+			s.putBucketNotification(w, r, bucketName, body)
+		case r.Method == "GET" && isVersioning:
+			s.getBucketVersioning(w, r, bucketName)
+		case r.Method == "PUT" && isVersioning:
+			s.putBucketVersioning(w, r, bucketName, body)
+		case r.Method == "GET" && isVersions:
+			s.listObjectVersions(w, r, bucketName)
+		case r.Method == "GET" && isLifecycle:
+			s.getBucketLifecycle(w, r, bucketName)
+		case r.Method == "PUT" && isLifecycle:
+			s.putBucketLifecycle(w, r, bucketName, body)
+		case r.Method == "DELETE" && isLifecycle:
+			s.deleteBucketLifecycle(w, r, bucketName)
+		case r.Method == "GET" && isPolicy:
+			s.getBucketPolicy(w, r, bucketName)
+		case r.Method == "PUT" && isPolicy:
+			s.putBucketPolicy(w, r, bucketName, body)
+		case r.Method == "DELETE" && isPolicy:
+			s.deleteBucketPolicy(w, r, bucketName)
+		case r.Method == "GET" && isACL:
+			s.getBucketACL(w, r, bucketName)
+		case r.Method == "GET" && isUploads:
+			s.listMultipartUploads(w, r, bucketName)
+		case r.Method == "GET" && isLocation:
+			s.getBucketLocation(w, r, bucketName)
+		case r.Method == "GET":
+			s.listObjects(w, r, bucketName)
+		case r.Method == "PUT" && isACL:
+			s.putBucketACL(w, r, bucketName)
+		case r.Method == "PUT":
+			s.putBucket(w, r, bucketName)
+		case r.Method == "HEAD":
+			s.headBucket(w, r, bucketName)
+		case r.Method == "DELETE":
+			s.deleteBucket(w, r, bucketName)
+		case isPostPolicy:
+			s.postPolicyUpload(w, r, bucketName, body)
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+	default:
+		_, isUploads := query["uploads"]
+		_, isUploadID := query["uploadId"]
+		_, isACL := query["acl"]
+		_, isTagging := query["tagging"]
+		switch {
+		case r.Method == "GET" && isTagging:
+			s.getObjectTagging(w, r, bucketName, objectName)
+		case r.Method == "PUT" && isTagging:
+			s.putObjectTagging(w, r, bucketName, objectName, body)
+		case r.Method == "DELETE" && isTagging:
+			s.deleteObjectTagging(w, r, bucketName, objectName)
+		case r.Method == "GET" && isACL:
+			s.getObjectACL(w, r, bucketName, objectName)
+		case r.Method == "PUT" && isACL:
+			s.putObjectACL(w, r, bucketName, objectName)
+		case r.Method == "GET" && isUploadID:
+			s.listObjectParts(w, r, bucketName, objectName)
+		case r.Method == "GET":
+			s.getObject(w, r, bucketName, objectName)
+		case r.Method == "HEAD":
+			s.headObject(w, r, bucketName, objectName)
+		case r.Method == "PUT" && isUploadID:
+			s.putObjectPart(w, r, bucketName, objectName, body)
+		case r.Method == "PUT":
+			s.putObject(w, r, bucketName, objectName, body)
+		case r.Method == "POST" && isUploads:
+			s.newMultipartUpload(w, r, bucketName, objectName)
+		case r.Method == "POST" && isUploadID:
+			s.completeMultipartUpload(w, r, bucketName, objectName, body)
+		case r.Method == "DELETE" && isUploadID:
+			s.abortMultipartUpload(w, r, bucketName, objectName)
+		case r.Method == "DELETE":
+			s.deleteObject(w, r, bucketName, objectName)
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+	}
+}
+
+/// Service operations
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	response := listAllMyBucketsResult{Xmlns: s3Namespace, Owner: defaultOwner}
+	for _, name := range names {
+		response.Buckets.Bucket = append(response.Buckets.Bucket, bucketEntry{Name: name, CreationDate: s.buckets[name].created})
+	}
+	writeXML(w, response)
+}
+
+/// Bucket operations
+
+func (s *Server) putBucket(w http.ResponseWriter, r *http.Request, bucketName string) {
+	if !isValidBucketName(bucketName) {
+		writeError(w, r, errInvalidBucketName)
+		return
+	}
+	if _, ok := s.buckets[bucketName]; ok {
+		writeError(w, r, errBucketAlreadyExists)
+		return
+	}
+	acl := r.Header.Get("x-amz-acl")
+	if acl == "" {
+		acl = "private"
+	}
+	if !isValidCannedACL(acl) {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+	s.buckets[bucketName] = &bucket{acl: acl, created: time.Now().UTC(), objects: make(map[string]*object)}
+	w.Header().Set("Location", "/"+bucketName)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) headBucket(w http.ResponseWriter, r *http.Request, bucketName string) {
+	if _, ok := s.buckets[bucketName]; !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if len(b.objects) > 0 {
+		writeError(w, r, errBucketNotEmpty)
+		return
+	}
+	delete(s.buckets, bucketName)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getBucketLocation(w http.ResponseWriter, r *http.Request, bucketName string) {
+	if _, ok := s.buckets[bucketName]; !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	writeXML(w, locationConstraint{Xmlns: s3Namespace})
+}
+
+func (s *Server) getBucketACL(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	writeXML(w, newAccessControlPolicy(b.acl))
+}
+
+// newAccessControlPolicy expands a canned acl into its grants
+func newAccessControlPolicy(acl string) accessControlPolicy {
+	response := accessControlPolicy{Xmlns: s3Namespace, Owner: defaultOwner}
+	grants := []grant{{Grantee: grantee{ID: defaultOwner.ID, DisplayName: defaultOwner.DisplayName}, Permission: "FULL_CONTROL"}}
+	switch acl {
+	case "public-read":
+		grants = append(grants, grant{Grantee: grantee{URI: allUsersURI}, Permission: "READ"})
+	case "public-read-write":
+		grants = append(grants, grant{Grantee: grantee{URI: allUsersURI}, Permission: "READ"})
+		grants = append(grants, grant{Grantee: grantee{URI: allUsersURI}, Permission: "WRITE"})
+	case "authenticated-read":
+		grants = append(grants, grant{Grantee: grantee{URI: authenticatedUsersURI}, Permission: "READ"})
+	}
+	response.AccessControlList.Grant = grants
+	return response
+}
+
+func (s *Server) putBucketACL(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	acl := r.Header.Get("x-amz-acl")
+	if !isValidCannedACL(acl) {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+	b.acl = acl
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if b.policy == nil {
+		writeError(w, r, errNoSuchBucketPolicy)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b.policy)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.policy)
+}
+
+func (s *Server) putBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if _, err := parsePolicy(body); err != nil {
+		writeError(w, r, errMalformedPolicy)
+		return
+	}
+	b.policy = body
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	b.policy = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getBucketLifecycle(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if b.lifecycle == nil {
+		writeError(w, r, errNoSuchLifecycleConfiguration)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b.lifecycle)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.lifecycle)
+}
+
+func (s *Server) putBucketLifecycle(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	// Content-MD5 is mandatory for lifecycle configuration
+	if r.Header.Get("Content-MD5") == "" {
+		writeError(w, r, errMissingContentMD5)
+		return
+	}
+	if err := verifyContentMD5(r, body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if !isValidLifecycle(body) {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	b.lifecycle = body
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteBucketLifecycle(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	b.lifecycle = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	marker := query.Get("marker")
+	delimiter := query.Get("delimiter")
+	maxKeys := 1000
+	if query.Get("max-keys") != "" {
+		var e error
+		maxKeys, e = strconv.Atoi(query.Get("max-keys"))
+		if e != nil || maxKeys < 0 {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+	}
+	var keys []string
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	response := listBucketResult{
+		Xmlns:     s3Namespace,
+		Name:      bucketName,
+		Prefix:    prefix,
+		Marker:    marker,
+		MaxKeys:   maxKeys,
+		Delimiter: delimiter,
+	}
+	seenPrefixes := make(map[string]bool)
+	var count int
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		// keys sharing a common prefix upto the delimiter are rolled up
+		keyPrefix := ""
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				keyPrefix = key[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if keyPrefix != "" && (seenPrefixes[keyPrefix] || keyPrefix <= marker) {
+			continue
+		}
+		if count == maxKeys {
+			response.IsTruncated = true
+			break
+		}
+		count++
+		switch keyPrefix {
+		case "":
+			o := b.objects[key]
+			response.Contents = append(response.Contents, objectEntry{
+				Key:          key,
+				LastModified: o.lastModified,
+				ETag:         "\"" + o.etag + "\"",
+				Size:         int64(len(o.data)),
+				Owner:        defaultOwner,
+				StorageClass: storageClassName(o.storageClass),
+			})
+			response.NextMarker = key
+		default:
+			seenPrefixes[keyPrefix] = true
+			response.CommonPrefixes = append(response.CommonPrefixes, commonPrefix{Prefix: keyPrefix})
+			response.NextMarker = keyPrefix
+		}
+	}
+	writeXML(w, response)
+}
+
+/// Object operations
+
+func (s *Server) getObjectFromBucket(w http.ResponseWriter, r *http.Request, bucketName, objectName string) (*object, bool) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return nil, false
+	}
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		o, ok := b.findVersion(objectName, versionID)
+		switch {
+		case !ok:
+			writeError(w, r, errNoSuchVersion)
+			return nil, false
+		case o.deleteMarker:
+			w.Header().Set("x-amz-delete-marker", "true")
+			writeError(w, r, errMethodNotAllowed)
+			return nil, false
+		}
+		return o, true
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		writeError(w, r, errNoSuchKey)
+		return nil, false
+	}
+	return o, true
+}
+
+func setObjectHeaders(w http.ResponseWriter, o *object) {
+	w.Header().Set("ETag", "\""+o.etag+"\"")
+	w.Header().Set("Last-Modified", o.lastModified.Format(http.TimeFormat))
+	w.Header().Set("Content-Type", o.contentType)
+	w.Header().Set("Accept-Ranges", "bytes")
+	if o.versionID != "" {
+		w.Header().Set("x-amz-version-id", o.versionID)
+	}
+	// like S3 the storage class is only reported when it is not STANDARD
+	if storageClassName(o.storageClass) != "STANDARD" {
+		w.Header().Set("x-amz-storage-class", storageClassName(o.storageClass))
+	}
+}
+
+// responseOverrides maps the query parameters overriding response headers of a GET to their headers
+var responseOverrides = map[string]string{
+	"response-cache-control":       "Cache-Control",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-encoding":    "Content-Encoding",
+	"response-content-language":    "Content-Language",
+	"response-content-type":        "Content-Type",
+	"response-expires":             "Expires",
+}
+
+func setResponseOverrides(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for param, header := range responseOverrides {
+		if value := query.Get(param); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+}
+
+// parseRange parses a single 'bytes=' range, start and end are inclusive
+func parseRange(rangeHeader string, size int64) (start, end int64, ok bool) {
+	spec := strings.TrimPrefix(rangeHeader, "bytes=")
+	if spec == rangeHeader || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	splits := strings.SplitN(spec, "-", 2)
+	if len(splits) != 2 {
+		return 0, 0, false
+	}
+	var err error
+	switch {
+	case splits[0] == "":
+		// suffix range, last N bytes
+		var length int64
+		length, err = strconv.ParseInt(splits[1], 10, 64)
+		if err != nil || length <= 0 {
+			return 0, 0, false
+		}
+		if length > size {
+			length = size
+		}
+		return size - length, size - 1, true
+	default:
+		start, err = strconv.ParseInt(splits[0], 10, 64)
+		if err != nil || start >= size {
+			return 0, 0, false
+		}
+		end = size - 1
+		if splits[1] != "" {
+			end, err = strconv.ParseInt(splits[1], 10, 64)
+			if err != nil || end < start {
+				return 0, 0, false
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		return start, end, true
+	}
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	setObjectHeaders(w, o)
+	setResponseOverrides(w, r)
+	data := o.data
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && len(o.data) > 0 {
+		start, end, ok := parseRange(rangeHeader, int64(len(o.data)))
+		if !ok {
+			writeError(w, r, errInvalidRange)
+			return
+		}
+		data = o.data[start : end+1]
+		w.Header().Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)+"/"+strconv.Itoa(len(o.data)))
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	io.Copy(w, bytes.NewReader(data))
+}
+
+func (s *Server) headObject(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	setObjectHeaders(w, o)
+	w.Header().Set("Content-Length", strconv.Itoa(len(o.data)))
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifyContentMD5 validates an optional Content-MD5 header against the body
+func verifyContentMD5(r *http.Request, body []byte) *apiError {
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" {
+		md5Sum := md5.Sum(body)
+		if base64.StdEncoding.EncodeToString(md5Sum[:]) != contentMD5 {
+			return errBadDigest
+		}
+	}
+	return nil
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucketName, objectName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if err := verifyContentMD5(r, body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	storageClass, err := requestStorageClass(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	o := newObject(body, contentType)
+	o.storageClass = storageClass
+	if acl := r.Header.Get("x-amz-acl"); acl != "" {
+		if !isValidCannedACL(acl) {
+			writeError(w, r, errNotImplemented)
+			return
+		}
+		o.acl = acl
+	}
+	b.storeObject(objectName, o)
+	w.Header().Set("ETag", "\""+o.etag+"\"")
+	if o.versionID != "" {
+		w.Header().Set("x-amz-version-id", o.versionID)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getObjectACL(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	writeXML(w, newAccessControlPolicy(o.acl))
+}
+
+func (s *Server) putObjectACL(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	if o.aclLocked {
+		writeError(w, r, errAccessDenied)
+		return
+	}
+	acl := r.Header.Get("x-amz-acl")
+	if !isValidCannedACL(acl) {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+	o.acl = acl
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		b.deleteVersion(objectName, versionID)
+		w.Header().Set("x-amz-version-id", versionID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if b.versioning == "" {
+		delete(b.objects, objectName)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	marker := &object{lastModified: time.Now().UTC(), deleteMarker: true}
+	b.storeObject(objectName, marker)
+	w.Header().Set("x-amz-delete-marker", "true")
+	w.Header().Set("x-amz-version-id", marker.versionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/// Browser based uploads
+
+// postPolicy is the decoded form of a POST policy document
+type postPolicy struct {
+	Expiration string          `json:"expiration"`
+	Conditions [][]interface{} `json:"conditions"`
+}
+
+// checkPostPolicy verifies the policy expiry and conditions against the form fields
+func checkPostPolicy(form map[string]string, size int64) *apiError {
+	policyBytes, e := base64.StdEncoding.DecodeString(form["policy"])
+	if e != nil {
+		return errMalformedPOSTRequest
+	}
+	var policy postPolicy
+	if e := json.Unmarshal(policyBytes, &policy); e != nil {
+		return errMalformedPOSTRequest
+	}
+	expiration, e := time.Parse("2006-01-02T15:04:05.999Z", policy.Expiration)
+	if e != nil {
+		return errMalformedPOSTRequest
+	}
+	if time.Now().UTC().After(expiration) {
+		return errExpiredRequest
+	}
+	for _, condition := range policy.Conditions {
+		if len(condition) != 3 {
+			return errMalformedPOSTRequest
+		}
+		operator, _ := condition[0].(string)
+		switch operator {
+		case "content-length-range":
+			min, _ := condition[1].(float64)
+			max, _ := condition[2].(float64)
+			if size < int64(min) || size > int64(max) {
+				return errEntityTooLarge
+			}
+			continue
+		}
+		field, _ := condition[1].(string)
+		value, _ := condition[2].(string)
+		field = strings.ToLower(strings.TrimPrefix(field, "$"))
+		// these fields are part of the signature itself
+		switch field {
+		case "x-amz-date", "x-amz-algorithm", "x-amz-credential":
+			continue
+		}
+		switch operator {
+		case "eq":
+			if form[field] != value {
+				return errPolicyConditionFailed
+			}
+		case "starts-with":
+			if !strings.HasPrefix(form[field], value) {
+				return errPolicyConditionFailed
+			}
+		default:
+			return errMalformedPOSTRequest
+		}
+	}
+	return nil
+}
+
+func (s *Server) postPolicyUpload(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	reader, e := r.MultipartReader()
+	if e != nil {
+		writeError(w, r, errMalformedPOSTRequest)
+		return
+	}
+	form := make(map[string]string)
+	var data []byte
+	var filename string
+	var hasFile bool
+	for {
+		part, e := reader.NextPart()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			writeError(w, r, errMalformedPOSTRequest)
+			return
+		}
+		value, e := ioutil.ReadAll(part)
+		if e != nil {
+			writeError(w, r, errMalformedPOSTRequest)
+			return
+		}
+		switch part.FormName() {
+		case "file":
+			data, filename, hasFile = value, part.FileName(), true
+		default:
+			form[strings.ToLower(part.FormName())] = string(value)
+		}
+	}
+	if !hasFile || form["key"] == "" || form["policy"] == "" {
+		writeError(w, r, errMalformedPOSTRequest)
+		return
+	}
+	form["key"] = strings.Replace(form["key"], "${filename}", filename, -1)
+	form["bucket"] = bucketName
+	if err := s.verifyPostPolicySignature(form); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := checkPostPolicy(form, int64(len(data))); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	contentType := form["content-type"]
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	o := newObject(data, contentType)
+	b.storeObject(form["key"], o)
+	w.Header().Set("ETag", "\""+o.etag+"\"")
+	w.Header().Set("Location", "/"+bucketName+"/"+form["key"])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/// Multipart operations
+
+func (s *Server) getUpload(w http.ResponseWriter, r *http.Request, bucketName, objectName string) (*upload, bool) {
+	if _, ok := s.buckets[bucketName]; !ok {
+		writeError(w, r, errNoSuchBucket)
+		return nil, false
+	}
+	u, ok := s.uploads[r.URL.Query().Get("uploadId")]
+	if !ok || u.bucket != bucketName || u.object != objectName {
+		writeError(w, r, errNoSuchUpload)
+		return nil, false
+	}
+	return u, true
+}
+
+func (s *Server) newMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	if _, ok := s.buckets[bucketName]; !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	storageClass, err := requestStorageClass(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.nextUploadID++
+	uploadID := strconv.Itoa(s.nextUploadID)
+	s.uploads[uploadID] = &upload{
+		bucket:       bucketName,
+		object:       objectName,
+		initiated:    time.Now().UTC(),
+		parts:        make(map[int]*object),
+		storageClass: storageClass,
+	}
+	writeXML(w, initiateMultipartUploadResult{Xmlns: s3Namespace, Bucket: bucketName, Key: objectName, UploadID: uploadID})
+}
+
+func (s *Server) putObjectPart(w http.ResponseWriter, r *http.Request, bucketName, objectName string, body []byte) {
+	u, ok := s.getUpload(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	partNumber, e := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if e != nil || partNumber < 1 || partNumber > 10000 {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+	if err := verifyContentMD5(r, body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	part := newObject(body, "")
+	u.parts[partNumber] = part
+	w.Header().Set("ETag", "\""+part.etag+"\"")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, objectName string, body []byte) {
+	u, ok := s.getUpload(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	var complete completeMultipartUpload
+	if e := xml.Unmarshal(body, &complete); e != nil || len(complete.Parts) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	var data bytes.Buffer
+	var md5Sums []byte
+	for i, p := range complete.Parts {
+		if i > 0 && p.PartNumber <= complete.Parts[i-1].PartNumber {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+		part, ok := u.parts[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, "\"") != part.etag {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+		md5Sum, _ := hex.DecodeString(part.etag)
+		md5Sums = append(md5Sums, md5Sum...)
+		data.Write(part.data)
+	}
+	o := newObject(data.Bytes(), "application/octet-stream")
+	o.storageClass = u.storageClass
+	// multipart etags are md5 of concatenated part md5s followed by number of parts
+	md5Sum := md5.Sum(md5Sums)
+	o.etag = hex.EncodeToString(md5Sum[:]) + "-" + strconv.Itoa(len(complete.Parts))
+	s.buckets[bucketName].storeObject(objectName, o)
+	delete(s.uploads, r.URL.Query().Get("uploadId"))
+	writeXML(w, completeMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Location: s.URL + "/" + bucketName + "/" + objectName,
+		Bucket:   bucketName,
+		Key:      objectName,
+		ETag:     "\"" + o.etag + "\"",
+	})
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	if _, ok := s.getUpload(w, r, bucketName, objectName); !ok {
+		return
+	}
+	delete(s.uploads, r.URL.Query().Get("uploadId"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listObjectParts(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	u, ok := s.getUpload(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	maxParts := 1000
+	if query.Get("max-parts") != "" {
+		var e error
+		maxParts, e = strconv.Atoi(query.Get("max-parts"))
+		if e != nil || maxParts < 0 {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+	}
+	partNumberMarker, _ := strconv.Atoi(query.Get("part-number-marker"))
+	var partNumbers []int
+	for partNumber := range u.parts {
+		if partNumber > partNumberMarker {
+			partNumbers = append(partNumbers, partNumber)
+		}
+	}
+	sort.Ints(partNumbers)
+	response := listPartsResult{
+		Xmlns:            s3Namespace,
+		Bucket:           bucketName,
+		Key:              objectName,
+		UploadID:         query.Get("uploadId"),
+		Initiator:        defaultOwner,
+		Owner:            defaultOwner,
+		StorageClass:     storageClassName(u.storageClass),
+		PartNumberMarker: partNumberMarker,
+		MaxParts:         maxParts,
+	}
+	for i, partNumber := range partNumbers {
+		if i == maxParts {
+			response.IsTruncated = true
+			break
+		}
+		part := u.parts[partNumber]
+		response.Parts = append(response.Parts, partEntry{
+			PartNumber:   partNumber,
+			LastModified: part.lastModified,
+			ETag:         "\"" + part.etag + "\"",
+			Size:         int64(len(part.data)),
+		})
+		response.NextPartNumberMarker = partNumber
+	}
+	writeXML(w, response)
+}
+
+func (s *Server) listMultipartUploads(w http.ResponseWriter, r *http.Request, bucketName string) {
+	if _, ok := s.buckets[bucketName]; !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	var uploadIDs []string
+	for uploadID, u := range s.uploads {
+		if u.bucket == bucketName && strings.HasPrefix(u.object, prefix) {
+			uploadIDs = append(uploadIDs, uploadID)
+		}
+	}
+	sort.Strings(uploadIDs)
+	response := listMultipartUploadsResult{
+		Xmlns:      s3Namespace,
+		Bucket:     bucketName,
+		Prefix:     prefix,
+		MaxUploads: 1000,
+	}
+	for _, uploadID := range uploadIDs {
+		u := s.uploads[uploadID]
+		response.Uploads = append(response.Uploads, uploadEntry{
+			Key:          u.object,
+			UploadID:     uploadID,
+			Initiator:    defaultOwner,
+			Owner:        defaultOwner,
+			StorageClass: storageClassName(u.storageClass),
+			Initiated:    u.initiated,
+		})
+	}
+	writeXML(w, response)
+}
@@ -0,0 +1,366 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signV4Algorithm   = "AWS4-HMAC-SHA256"
+	iso8601DateFormat = "20060102T150405Z"
+	yyyymmdd          = "20060102"
+	unsignedPayload   = "UNSIGNED-PAYLOAD"
+)
+
+// resourceList is the sorted list of sub-resources which are part of a V2 canonical resource
+var resourceList = []string{
+	"acl",
+	"lifecycle",
+	"location",
+	"logging",
+	"notification",
+	"partNumber",
+	"policy",
+	"requestPayment",
+	"response-cache-control",
+	"response-content-disposition",
+	"response-content-encoding",
+	"response-content-language",
+	"response-content-type",
+	"response-expires",
+	"tagging",
+	"torrent",
+	"uploadId",
+	"uploads",
+	"versionId",
+	"versioning",
+	"versions",
+	"website",
+}
+
+func sum256(data []byte) []byte {
+	hash := sha256.New()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func sumHMAC(key []byte, data []byte) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func sumHMACSHA1(key []byte, data []byte) string {
+	hash := hmac.New(sha1.New, key)
+	hash.Write(data)
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// encodePath encode the strings from UTF-8 byte representations to HTML hex escape sequences
+func encodePath(pathName string) string {
+	var encodedPathname bytes.Buffer
+	for _, b := range []byte(pathName) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9':
+			encodedPathname.WriteByte(b)
+		case b == '-', b == '_', b == '.', b == '~', b == '/':
+			encodedPathname.WriteByte(b)
+		default:
+			encodedPathname.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+		}
+	}
+	return encodedPathname.String()
+}
+
+// signingKeyV4 derives the signature V4 signing key for a given day and region
+func (s *Server) signingKeyV4(t time.Time, region string) []byte {
+	date := sumHMAC([]byte("AWS4"+s.SecretAccessKey), []byte(t.Format(yyyymmdd)))
+	regionBytes := sumHMAC(date, []byte(region))
+	service := sumHMAC(regionBytes, []byte("s3"))
+	return sumHMAC(service, []byte("aws4_request"))
+}
+
+// credentialV4 is the parsed form of 'AccessKeyID/yyyymmdd/region/s3/aws4_request'
+type credentialV4 struct {
+	accessKeyID string
+	region      string
+	scope       string
+}
+
+func (s *Server) parseCredentialV4(credential string) (credentialV4, *apiError) {
+	splits := strings.SplitN(credential, "/", 2)
+	if len(splits) != 2 {
+		return credentialV4{}, errInvalidArgument
+	}
+	scopes := strings.Split(splits[1], "/")
+	if len(scopes) != 4 || scopes[2] != "s3" || scopes[3] != "aws4_request" {
+		return credentialV4{}, errInvalidArgument
+	}
+	if splits[0] != s.AccessKeyID {
+		return credentialV4{}, errInvalidAccessKeyID
+	}
+	return credentialV4{accessKeyID: splits[0], region: scopes[1], scope: splits[1]}, nil
+}
+
+// canonicalRequestV4 builds the V4 canonical request from an incoming request
+func canonicalRequestV4(r *http.Request, query url.Values, signedHeaders []string, hashedPayload string) string {
+	var headers bytes.Buffer
+	for _, k := range signedHeaders {
+		headers.WriteString(k)
+		headers.WriteByte(':')
+		switch {
+		case k == "host":
+			headers.WriteString(r.Host)
+		default:
+			headers.WriteString(strings.Join(r.Header[http.CanonicalHeaderKey(k)], ","))
+		}
+		headers.WriteByte('\n')
+	}
+	return strings.Join([]string{
+		r.Method,
+		encodePath(r.URL.Path),
+		strings.Replace(query.Encode(), "+", "%20", -1),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		hashedPayload,
+	}, "\n")
+}
+
+// verifySignatureV4 compares signature against one calculated from the canonical request
+func (s *Server) verifySignatureV4(canonicalRequest string, t time.Time, credential credentialV4, signature string) *apiError {
+	stringToSign := signV4Algorithm + "\n" + t.Format(iso8601DateFormat) + "\n"
+	stringToSign = stringToSign + credential.scope + "\n"
+	stringToSign = stringToSign + hex.EncodeToString(sum256([]byte(canonicalRequest)))
+	expected := hex.EncodeToString(sumHMAC(s.signingKeyV4(t, credential.region), []byte(stringToSign)))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// verifyHeaderV4 validates an 'Authorization: AWS4-HMAC-SHA256 ...' request
+func (s *Server) verifyHeaderV4(r *http.Request, body []byte) *apiError {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), signV4Algorithm), ",") {
+		splits := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(splits) != 2 {
+			return errInvalidArgument
+		}
+		fields[splits[0]] = splits[1]
+	}
+	credential, err := s.parseCredentialV4(fields["Credential"])
+	if err != nil {
+		return err
+	}
+	t, e := time.Parse(iso8601DateFormat, r.Header.Get("X-Amz-Date"))
+	if e != nil {
+		return errMissingDateHeader
+	}
+	hashedPayload := r.Header.Get("X-Amz-Content-Sha256")
+	if hashedPayload != unsignedPayload && hashedPayload != hex.EncodeToString(sum256(body)) {
+		return errContentSHA256Mismatch
+	}
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	canonicalRequest := canonicalRequestV4(r, r.URL.Query(), signedHeaders, hashedPayload)
+	return s.verifySignatureV4(canonicalRequest, t, credential, fields["Signature"])
+}
+
+// verifyPresignedV4 validates a V4 query string authenticated request
+func (s *Server) verifyPresignedV4(r *http.Request) *apiError {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return errInvalidArgument
+	}
+	credential, err := s.parseCredentialV4(query.Get("X-Amz-Credential"))
+	if err != nil {
+		return err
+	}
+	t, e := time.Parse(iso8601DateFormat, query.Get("X-Amz-Date"))
+	if e != nil {
+		return errMissingDateHeader
+	}
+	expires, e := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if e != nil {
+		return errInvalidArgument
+	}
+	if time.Now().UTC().After(t.Add(time.Duration(expires) * time.Second)) {
+		return errExpiredRequest
+	}
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+	signedHeaders := strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	canonicalRequest := canonicalRequestV4(r, query, signedHeaders, unsignedPayload)
+	return s.verifySignatureV4(canonicalRequest, t, credential, signature)
+}
+
+// canonicalResourceV2 builds the V2 canonical resource, path followed by sorted sub-resources
+func canonicalResourceV2(r *http.Request) string {
+	var buf bytes.Buffer
+	buf.WriteString(encodePath(r.URL.Path))
+	query := r.URL.Query()
+	var n int
+	for _, resource := range resourceList {
+		if vv, ok := query[resource]; ok && len(vv) > 0 {
+			n++
+			switch n {
+			case 1:
+				buf.WriteByte('?')
+			default:
+				buf.WriteByte('&')
+			}
+			buf.WriteString(resource)
+			if len(vv[0]) > 0 {
+				buf.WriteByte('=')
+				buf.WriteString(vv[0])
+			}
+		}
+	}
+	return buf.String()
+}
+
+// canonicalAmzHeadersV2 builds the sorted list of 'x-amz-*' headers
+func canonicalAmzHeadersV2(r *http.Request) string {
+	return canonicalAmzHeadersOfV2(r.Header)
+}
+
+// canonicalAmzHeadersOfV2 builds the sorted list of 'x-amz-*' headers of header
+func canonicalAmzHeadersOfV2(header http.Header) string {
+	var amzHeaders []string
+	vals := make(map[string][]string)
+	for k, vv := range header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz") {
+			amzHeaders = append(amzHeaders, lk)
+			vals[lk] = vv
+		}
+	}
+	sort.Strings(amzHeaders)
+	var buf bytes.Buffer
+	for _, k := range amzHeaders {
+		buf.WriteString(k)
+		buf.WriteByte(':')
+		buf.WriteString(strings.Join(vals[k], ","))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// verifyHeaderV2 validates an 'Authorization: AWS AccessKeyID:Signature' request
+func (s *Server) verifyHeaderV2(r *http.Request) *apiError {
+	splits := strings.SplitN(strings.TrimPrefix(r.Header.Get("Authorization"), "AWS "), ":", 2)
+	if len(splits) != 2 {
+		return errInvalidArgument
+	}
+	if splits[0] != s.AccessKeyID {
+		return errInvalidAccessKeyID
+	}
+	if r.Header.Get("Date") == "" {
+		return errMissingDateHeader
+	}
+	stringToSign := r.Method + "\n"
+	stringToSign = stringToSign + r.Header.Get("Content-MD5") + "\n"
+	stringToSign = stringToSign + r.Header.Get("Content-Type") + "\n"
+	stringToSign = stringToSign + r.Header.Get("Date") + "\n"
+	stringToSign = stringToSign + canonicalAmzHeadersV2(r)
+	stringToSign = stringToSign + canonicalResourceV2(r)
+	expected := sumHMACSHA1([]byte(s.SecretAccessKey), []byte(stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(splits[1])) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// verifyPresignedV2 validates a V2 query string authenticated request
+func (s *Server) verifyPresignedV2(r *http.Request) *apiError {
+	query := r.URL.Query()
+	if query.Get("AWSAccessKeyId") != s.AccessKeyID {
+		return errInvalidAccessKeyID
+	}
+	expires, e := strconv.ParseInt(query.Get("Expires"), 10, 64)
+	if e != nil {
+		return errInvalidArgument
+	}
+	if time.Now().UTC().Unix() > expires {
+		return errExpiredRequest
+	}
+	stringToSign := r.Method + "\n"
+	stringToSign = stringToSign + r.Header.Get("Content-MD5") + "\n"
+	stringToSign = stringToSign + r.Header.Get("Content-Type") + "\n"
+	stringToSign = stringToSign + query.Get("Expires") + "\n"
+	// a session token in the query is signed like an 'x-amz-security-token' header
+	header := r.Header
+	if token := query.Get("x-amz-security-token"); token != "" {
+		header = make(http.Header)
+		for k, vv := range r.Header {
+			header[k] = vv
+		}
+		header.Set("X-Amz-Security-Token", token)
+	}
+	stringToSign = stringToSign + canonicalAmzHeadersOfV2(header)
+	stringToSign = stringToSign + canonicalResourceV2(r)
+	expected := sumHMACSHA1([]byte(s.SecretAccessKey), []byte(stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(query.Get("Signature"))) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// verifyPostPolicySignature validates the signature of a browser based POST upload form
+func (s *Server) verifyPostPolicySignature(form map[string]string) *apiError {
+	policy := form["policy"]
+	if err := s.verifySessionToken(form["x-amz-security-token"]); err != nil {
+		return err
+	}
+	switch {
+	case form["x-amz-algorithm"] == signV4Algorithm:
+		credential, err := s.parseCredentialV4(form["x-amz-credential"])
+		if err != nil {
+			return err
+		}
+		t, e := time.Parse(iso8601DateFormat, form["x-amz-date"])
+		if e != nil {
+			return errMissingDateHeader
+		}
+		expected := hex.EncodeToString(sumHMAC(s.signingKeyV4(t, credential.region), []byte(policy)))
+		if !hmac.Equal([]byte(expected), []byte(form["x-amz-signature"])) {
+			return errSignatureDoesNotMatch
+		}
+		return nil
+	case form["awsaccesskeyid"] != "":
+		if form["awsaccesskeyid"] != s.AccessKeyID {
+			return errInvalidAccessKeyID
+		}
+		expected := sumHMACSHA1([]byte(s.SecretAccessKey), []byte(policy))
+		if !hmac.Equal([]byte(expected), []byte(form["signature"])) {
+			return errSignatureDoesNotMatch
+		}
+		return nil
+	}
+	return errAccessDenied
+}
@@ -0,0 +1,134 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"unicode/utf8"
+)
+
+// limits on object tags enforced by S3
+const (
+	maxObjectTags     = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+type tag struct {
+	Key   string
+	Value string
+}
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []tag    `xml:"TagSet>Tag"`
+}
+
+// newTagging returns the tag set of an object sorted by key
+func newTagging(tags map[string]string) tagging {
+	t := tagging{Xmlns: s3Namespace, TagSet: []tag{}}
+	for key, value := range tags {
+		t.TagSet = append(t.TagSet, tag{Key: key, Value: value})
+	}
+	sort.Sort(byTagKey(t.TagSet))
+	return t
+}
+
+type byTagKey []tag
+
+func (t byTagKey) Len() int           { return len(t) }
+func (t byTagKey) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byTagKey) Less(i, j int) bool { return t[i].Key < t[j].Key }
+
+// parseTagging validates a tag set, keys have to be unique
+func parseTagging(body []byte) (map[string]string, *apiError) {
+	var t tagging
+	if err := xml.Unmarshal(body, &t); err != nil {
+		return nil, errMalformedXML
+	}
+	if len(t.TagSet) > maxObjectTags {
+		return nil, errInvalidTag
+	}
+	tags := make(map[string]string)
+	for _, tag := range t.TagSet {
+		if tag.Key == "" || utf8.RuneCountInString(tag.Key) > maxTagKeyLength || utf8.RuneCountInString(tag.Value) > maxTagValueLength {
+			return nil, errInvalidTag
+		}
+		if _, ok := tags[tag.Key]; ok {
+			return nil, errInvalidTag
+		}
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// ObjectTags returns the tags of an object, if it exists
+func (s *Server) ObjectTags(bucketName, objectName string) (map[string]string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return nil, false
+	}
+	o, ok := b.objects[objectName]
+	if !ok {
+		return nil, false
+	}
+	tags := make(map[string]string)
+	for key, value := range o.tags {
+		tags[key] = value
+	}
+	return tags, true
+}
+
+func (s *Server) getObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	writeXML(w, newTagging(o.tags))
+}
+
+func (s *Server) putObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectName string, body []byte) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	if err := verifyContentMD5(r, body); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	tags, err := parseTagging(body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	o.tags = tags
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectName string) {
+	o, ok := s.getObjectFromBucket(w, r, bucketName, objectName)
+	if !ok {
+		return
+	}
+	o.tags = nil
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,256 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+// versionEntry is either a Version or a DeleteMarker, they are interleaved newest first for every key
+type versionEntry struct {
+	XMLName      xml.Name
+	Key          string
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified time.Time
+	ETag         string `xml:",omitempty"`
+	Size         int64  `xml:",omitempty"`
+	Owner        owner
+	StorageClass string `xml:",omitempty"`
+}
+
+type listVersionsResult struct {
+	XMLName         xml.Name `xml:"ListVersionsResult"`
+	Xmlns           string   `xml:"xmlns,attr"`
+	Name            string
+	Prefix          string
+	KeyMarker       string
+	VersionIDMarker string `xml:"VersionIdMarker"`
+	MaxKeys         int
+	Delimiter       string `xml:",omitempty"`
+	IsTruncated     bool
+	Entries         []versionEntry
+	CommonPrefixes  []commonPrefix
+}
+
+// history returns the versions of an object oldest first, an object stored
+// before versioning was enabled is its "null" version
+func (b *bucket) history(objectName string) []*object {
+	if versions, ok := b.versions[objectName]; ok {
+		return versions
+	}
+	if o, ok := b.objects[objectName]; ok {
+		o.versionID = "null"
+		return []*object{o}
+	}
+	return nil
+}
+
+// storeObject makes o the latest version of an object, o may be a delete marker
+func (b *bucket) storeObject(objectName string, o *object) {
+	if b.versioning == "" {
+		if o.deleteMarker {
+			delete(b.objects, objectName)
+			return
+		}
+		b.objects[objectName] = o
+		return
+	}
+	versions := b.history(objectName)
+	switch b.versioning {
+	case "Enabled":
+		b.nextVersionID++
+		o.versionID = fmt.Sprintf("%032x", b.nextVersionID)
+	default:
+		// suspended buckets overwrite the "null" version
+		o.versionID = "null"
+		versions = removeVersion(versions, "null")
+	}
+	b.versions[objectName] = append(versions, o)
+	b.updateLatest(objectName)
+}
+
+// deleteVersion permanently removes a single version of an object
+func (b *bucket) deleteVersion(objectName, versionID string) {
+	versions := b.history(objectName)
+	if versions == nil {
+		return
+	}
+	b.versions[objectName] = removeVersion(versions, versionID)
+	b.updateLatest(objectName)
+}
+
+// updateLatest makes the latest version current, unless it is a delete marker
+func (b *bucket) updateLatest(objectName string) {
+	versions := b.versions[objectName]
+	if len(versions) == 0 {
+		delete(b.versions, objectName)
+		delete(b.objects, objectName)
+		return
+	}
+	latest := versions[len(versions)-1]
+	if latest.deleteMarker {
+		delete(b.objects, objectName)
+		return
+	}
+	b.objects[objectName] = latest
+}
+
+// findVersion returns a specific version of an object, if it exists
+func (b *bucket) findVersion(objectName, versionID string) (*object, bool) {
+	for _, o := range b.history(objectName) {
+		if o.versionID == versionID {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+func removeVersion(versions []*object, versionID string) []*object {
+	var newVersions []*object
+	for _, o := range versions {
+		if o.versionID != versionID {
+			newVersions = append(newVersions, o)
+		}
+	}
+	return newVersions
+}
+
+// VersionCount returns the number of versions of an object including delete markers
+func (s *Server) VersionCount(bucketName, objectName string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		return 0
+	}
+	return len(b.history(objectName))
+}
+
+func (s *Server) getBucketVersioning(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	writeXML(w, versioningConfiguration{Xmlns: s3Namespace, Status: b.versioning})
+}
+
+func (s *Server) putBucketVersioning(w http.ResponseWriter, r *http.Request, bucketName string, body []byte) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	var config versioningConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil || (config.Status != "Enabled" && config.Status != "Suspended") {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	if b.versions == nil {
+		b.versions = make(map[string][]*object)
+	}
+	b.versioning = config.Status
+	w.WriteHeader(http.StatusOK)
+}
+
+// listObjectVersions lists every version and delete marker, newest first, without pagination
+func (s *Server) listObjectVersions(w http.ResponseWriter, r *http.Request, bucketName string) {
+	b, ok := s.buckets[bucketName]
+	if !ok {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+
+	keySet := make(map[string]bool)
+	for key := range b.objects {
+		keySet[key] = true
+	}
+	for key := range b.versions {
+		keySet[key] = true
+	}
+	var keys []string
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	response := listVersionsResult{
+		Xmlns:     s3Namespace,
+		Name:      bucketName,
+		Prefix:    prefix,
+		MaxKeys:   1000,
+		Delimiter: delimiter,
+	}
+	seenPrefixes := make(map[string]bool)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				keyPrefix := key[:len(prefix)+i+len(delimiter)]
+				if !seenPrefixes[keyPrefix] {
+					seenPrefixes[keyPrefix] = true
+					response.CommonPrefixes = append(response.CommonPrefixes, commonPrefix{Prefix: keyPrefix})
+				}
+				continue
+			}
+		}
+		versions := b.history(key)
+		for i := len(versions) - 1; i >= 0; i-- {
+			o := versions[i]
+			if o.deleteMarker {
+				response.Entries = append(response.Entries, versionEntry{
+					XMLName:      xml.Name{Local: "DeleteMarker"},
+					Key:          key,
+					VersionID:    o.versionID,
+					IsLatest:     i == len(versions)-1,
+					LastModified: o.lastModified,
+					Owner:        defaultOwner,
+				})
+				continue
+			}
+			response.Entries = append(response.Entries, versionEntry{
+				XMLName:      xml.Name{Local: "Version"},
+				Key:          key,
+				VersionID:    o.versionID,
+				IsLatest:     i == len(versions)-1,
+				LastModified: o.lastModified,
+				ETag:         "\"" + o.etag + "\"",
+				Size:         int64(len(o.data)),
+				Owner:        defaultOwner,
+				StorageClass: storageClassName(o.storageClass),
+			})
+		}
+	}
+	writeXML(w, response)
+}
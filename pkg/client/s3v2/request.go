@@ -0,0 +1,401 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3v2
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go-legacy"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// minio-go-legacy only implements bucket, object and presigned operations, every other
+// S3 API is sent through the signature V2 requests below.
+
+// expirationDateFormat of POST policies, same as minio-go-legacy
+const expirationDateFormat = "2006-01-02T15:04:05.999Z"
+
+// sub-resources which are part of the canonicalized resource, must be sorted
+var resourceList = []string{
+	"acl",
+	"lifecycle",
+	"location",
+	"logging",
+	"notification",
+	"partNumber",
+	"policy",
+	"requestPayment",
+	"response-cache-control",
+	"response-content-disposition",
+	"response-content-encoding",
+	"response-content-language",
+	"response-content-type",
+	"response-expires",
+	"tagging",
+	"torrent",
+	"uploadId",
+	"uploads",
+	"versionId",
+	"versioning",
+	"versions",
+	"website",
+}
+
+// encodePath percent encodes everything but the unreserved characters and '/'
+func encodePath(path string) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(path) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9':
+			buf.WriteByte(b)
+		case b == '-', b == '_', b == '.', b == '~', b == '/':
+			buf.WriteByte(b)
+		default:
+			buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+		}
+	}
+	return buf.String()
+}
+
+// isVirtualStyle requests carry the bucket name in the host
+func (c *s3Client) isVirtualStyle() bool {
+	match, _ := filepath.Match("*.s3*.amazonaws.com", c.hostURL.Host)
+	return match
+}
+
+// newRequest creates a new request for a bucket or an object, query values are the sub-resources
+func (c *s3Client) newRequest(method, bucket, object string, query url.Values, body []byte) (*http.Request, *probe.Error) {
+	path := "/"
+	if !c.isVirtualStyle() && bucket != "" {
+		path += bucket
+		if object != "" {
+			path += "/"
+		}
+	}
+	path += object
+	u := c.hostURL.Scheme + c.hostURL.SchemeSeparator + c.hostURL.Host + encodePath(path)
+	if len(query) > 0 {
+		u += "?" + strings.Replace(query.Encode(), "+", "%20", -1)
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, probe.NewError(err)
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("User-Agent", c.userAgent())
+	return req, nil
+}
+
+func (c *s3Client) userAgent() string {
+	userAgent := "Minio (" + c.config.AppName + "/" + c.config.AppVersion
+	if len(c.config.AppComments) > 0 {
+		userAgent += "; " + strings.Join(c.config.AppComments, "; ")
+	}
+	return userAgent + ")"
+}
+
+// canonicalResource - [ "/" + Bucket ] + <HTTP-Request-URI> + [ sub-resources ]
+func (c *s3Client) canonicalResource(req *http.Request) string {
+	var buf bytes.Buffer
+	if c.isVirtualStyle() {
+		buf.WriteString("/" + strings.SplitN(c.hostURL.Host, ".", 2)[0])
+	}
+	buf.WriteString(encodePath(req.URL.Path))
+	query := req.URL.Query()
+	n := 0
+	for _, resource := range resourceList {
+		values, ok := query[resource]
+		if !ok {
+			continue
+		}
+		if n == 0 {
+			buf.WriteByte('?')
+		} else {
+			buf.WriteByte('&')
+		}
+		n++
+		buf.WriteString(resource)
+		// sub-resource values are signed as is, without encoding
+		if len(values) > 0 && values[0] != "" {
+			buf.WriteString("=" + values[0])
+		}
+	}
+	return buf.String()
+}
+
+// stringToSign http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html
+func (c *s3Client) stringToSign(req *http.Request, date string) string {
+	var buf bytes.Buffer
+	buf.WriteString(req.Method + "\n")
+	buf.WriteString(req.Header.Get("Content-MD5") + "\n")
+	buf.WriteString(req.Header.Get("Content-Type") + "\n")
+	buf.WriteString(date + "\n")
+
+	var amzHeaders []string
+	values := make(map[string][]string)
+	for k, v := range req.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-amz-") {
+			amzHeaders = append(amzHeaders, lk)
+			values[lk] = v
+		}
+	}
+	sort.Strings(amzHeaders)
+	for _, k := range amzHeaders {
+		buf.WriteString(k + ":" + strings.Join(values[k], ",") + "\n")
+	}
+	buf.WriteString(c.canonicalResource(req))
+	return buf.String()
+}
+
+func (c *s3Client) signature(secretAccessKey, stringToSign string) string {
+	hm := hmac.New(sha1.New, []byte(secretAccessKey))
+	hm.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(hm.Sum(nil))
+}
+
+// isAnonymous requests are sent unsigned
+func (c *s3Client) isAnonymous() bool {
+	return c.config.AccessKeyID == "" || c.config.SecretAccessKey == ""
+}
+
+// isTemporary reports if requests carry a session token or credentials which
+// are renewed, minio-go-legacy can sign neither
+func (c *s3Client) isTemporary() bool {
+	return c.config.SessionToken != "" || c.config.Refresh != nil
+}
+
+// credentials returns the keys to sign with, renewed by the config if it can
+func (c *s3Client) credentials() (client.Credentials, *probe.Error) {
+	if c.config.Refresh != nil {
+		creds, err := c.config.Refresh()
+		if err != nil {
+			return client.Credentials{}, err.Trace()
+		}
+		return creds, nil
+	}
+	return client.Credentials{
+		AccessKeyID:     c.config.AccessKeyID,
+		SecretAccessKey: c.config.SecretAccessKey,
+		SessionToken:    c.config.SessionToken,
+	}, nil
+}
+
+// signRequest adds a signature V2 authorization header to the request
+func (c *s3Client) signRequest(req *http.Request) *probe.Error {
+	if c.isAnonymous() {
+		return nil
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return err.Trace()
+	}
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Date", date)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	req.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+c.signature(creds.SecretAccessKey, c.stringToSign(req, date)))
+	return nil
+}
+
+// presignRequest returns a query string signed URL for the request valid until expires
+func (c *s3Client) presignRequest(req *http.Request, expires time.Duration) (string, *probe.Error) {
+	expireSeconds := int64(expires / time.Second)
+	if expireSeconds < 1 || expireSeconds > 604800 {
+		return "", probe.NewError(client.InvalidArgument{})
+	}
+	if c.isAnonymous() {
+		return "", probe.NewError(errors.New("Presigning requires an access key and a secret key."))
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return "", err.Trace()
+	}
+	// the session token is signed as a header but sent in the query
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	// Expires takes the place of the date in the string to sign
+	epochExpires := strconv.FormatInt(time.Now().UTC().Unix()+expireSeconds, 10)
+	signature := c.signature(creds.SecretAccessKey, c.stringToSign(req, epochExpires))
+	query := req.URL.Query()
+	query.Set("AWSAccessKeyId", creds.AccessKeyID)
+	query.Set("Expires", epochExpires)
+	query.Set("Signature", signature)
+	if creds.SessionToken != "" {
+		query.Set("x-amz-security-token", creds.SessionToken)
+	}
+	req.URL.RawQuery = query.Encode()
+	return req.URL.String(), nil
+}
+
+// presignPostPolicy returns the form of a browser based upload signed with
+// temporary credentials, which minio-go-legacy can not add to the policy
+func (c *s3Client) presignPostPolicy(bucket, object string, recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+	if c.isAnonymous() {
+		return nil, probe.NewError(errors.New("Presigning requires an access key and a secret key."))
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	formData := map[string]string{
+		"bucket":         bucket,
+		"key":            object,
+		"AWSAccessKeyId": creds.AccessKeyID,
+	}
+	conditions := [][]string{{"eq", "$bucket", bucket}}
+	if recursive {
+		conditions = append(conditions, []string{"starts-with", "$key", object})
+	} else {
+		conditions = append(conditions, []string{"eq", "$key", object})
+	}
+	if contentType != "" {
+		formData["Content-Type"] = contentType
+		conditions = append(conditions, []string{"eq", "$Content-Type", contentType})
+	}
+	if creds.SessionToken != "" {
+		formData["x-amz-security-token"] = creds.SessionToken
+		conditions = append(conditions, []string{"eq", "$x-amz-security-token", creds.SessionToken})
+	}
+	policy, e := json.Marshal(struct {
+		Expiration string     `json:"expiration"`
+		Conditions [][]string `json:"conditions"`
+	}{time.Now().UTC().Add(expires).Format(expirationDateFormat), conditions})
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	formData["policy"] = base64.StdEncoding.EncodeToString(policy)
+	formData["signature"] = c.signature(creds.SecretAccessKey, formData["policy"])
+	return formData, nil
+}
+
+// sessionTokenTransport signs the requests of minio-go-legacy again with the
+// current credentials and their session token, unsigned requests are passed through
+type sessionTokenTransport struct {
+	client    *s3Client
+	transport http.RoundTripper
+}
+
+func (t sessionTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		return t.transport.RoundTrip(req)
+	}
+	req.Header.Del("Authorization")
+	if err := t.client.signRequest(req); err != nil {
+		return nil, err.ToGoError()
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// do signs and sends the request, responses other than 2xx are returned as minio.ErrorResponse
+func (c *s3Client) do(req *http.Request) (*http.Response, *probe.Error) {
+	if err := c.signRequest(req); err != nil {
+		return nil, err.Trace()
+	}
+	// RoundTrip directly like minio-go does, redirects are not followed
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, probe.NewError(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	errResponse := minio.ErrorResponse{}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil || xml.Unmarshal(data, &errResponse) != nil || errResponse.Code == "" {
+		errResponse.Code = http.StatusText(resp.StatusCode)
+		errResponse.Message = resp.Status
+		errResponse.Resource = req.URL.Path
+		errResponse.RequestID = resp.Header.Get("x-amz-request-id")
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			errResponse.Code = "NotFound"
+		case http.StatusNotImplemented:
+			errResponse.Code = "NotImplemented"
+		case http.StatusMethodNotAllowed:
+			errResponse.Code = "MethodNotAllowed"
+		}
+	}
+	return nil, probe.NewError(errResponse)
+}
+
+// accessControlPolicy - access control list of a bucket or an object
+type accessControlPolicy struct {
+	AccessControlList struct {
+		Grant []struct {
+			Grantee struct {
+				ID  string
+				URI string
+			}
+			Permission string
+		}
+	}
+}
+
+// cannedACL converts the grants back to a canned acl, same as minio-go does for buckets
+func (p accessControlPolicy) cannedACL() (string, bool) {
+	grants := p.AccessControlList.Grant
+	switch len(grants) {
+	case 1:
+		if grants[0].Grantee.URI == "" && grants[0].Permission == "FULL_CONTROL" {
+			return "private", true
+		}
+	case 2:
+		for _, g := range grants {
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AuthenticatedUsers" && g.Permission == "READ" {
+				return "authenticated-read", true
+			}
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "READ" {
+				return "public-read", true
+			}
+		}
+	case 3:
+		for _, g := range grants {
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "WRITE" {
+				return "public-read-write", true
+			}
+		}
+	}
+	return "", false
+}
+
+// isValidCannedACL - canned acls supported by mc
+func isValidCannedACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read":
+		return true
+	}
+	return false
+}
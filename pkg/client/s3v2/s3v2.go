@@ -17,9 +17,11 @@
 package s3v2
 
 import (
+	"encoding/xml"
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,12 +34,20 @@ import (
 )
 
 type s3Client struct {
-	api     minio.API
-	hostURL *client.URL
+	api       minio.API
+	hostURL   *client.URL
+	config    *client.Config
+	transport http.RoundTripper
 }
 
 // New returns an initialized s3Client structure. if debug use a internal trace transport
 func New(config *client.Config) (client.Client, *probe.Error) {
+	// object versions are only supported with signature V4
+	if i := strings.Index(config.HostURL, "?"); i >= 0 {
+		if query, e := url.ParseQuery(config.HostURL[i+1:]); e == nil && query.Get("versionId") != "" {
+			return nil, probe.NewError(client.APINotImplemented{API: "GetObjectVersion", APIType: "S3v2"})
+		}
+	}
 	u := client.NewURL(config.HostURL)
 	transport := http.DefaultTransport
 	if config.Debug == true {
@@ -54,11 +64,16 @@ func New(config *client.Config) (client.Client, *probe.Error) {
 	s3Conf.Transport = transport
 	s3Conf.SetUserAgent(config.AppName, config.AppVersion, config.AppComments...)
 	s3Conf.Endpoint = u.Scheme + u.SchemeSeparator + u.Host
+	c := &s3Client{hostURL: u, config: config, transport: transport}
+	if c.isTemporary() {
+		s3Conf.Transport = sessionTokenTransport{client: c, transport: transport}
+	}
 	api, err := minio.New(s3Conf)
 	if err != nil {
 		return nil, probe.NewError(err)
 	}
-	return &s3Client{api: api, hostURL: u}, nil
+	c.api = api
+	return c, nil
 }
 
 // URL get url
@@ -76,9 +91,17 @@ func (c *s3Client) GetObject(offset, length int64) (io.ReadCloser, int64, *probe
 	return reader, metadata.Size, nil
 }
 
-// Share - get a usable get object url to share
-func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
+// Share - get a usable get object url to share, reqParams override the response headers
+func (c *s3Client) ShareDownload(expires time.Duration, reqParams url.Values) (string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
+	if len(reqParams) > 0 || c.isTemporary() {
+		// minio-go can not sign response header overrides nor session tokens
+		req, err := c.newRequest("GET", bucket, object, reqParams, nil)
+		if err != nil {
+			return "", err.Trace()
+		}
+		return c.presignRequest(req, expires)
+	}
 	presignedURL, err := c.api.PresignedGetObject(bucket, object, expires)
 	if err != nil {
 		return "", probe.NewError(err)
@@ -88,6 +111,12 @@ func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
 
 func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
+	if c.isTemporary() {
+		if bucket == "" || strings.TrimSpace(object) == "" {
+			return nil, probe.NewError(client.InvalidArgument{})
+		}
+		return c.presignPostPolicy(bucket, object, recursive, expires, strings.TrimSpace(contentType))
+	}
 	p := minio.NewPostPolicy()
 	if err := p.SetExpires(time.Now().UTC().Add(expires)); err != nil {
 		return nil, probe.NewError(err)
@@ -112,12 +141,31 @@ func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentTyp
 	return m, probe.NewError(err)
 }
 
+// ShareUploadURL - get a presigned put object url to share, uploads have to send the signed content type
+func (c *s3Client) ShareUploadURL(expires time.Duration, contentType string) (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", probe.NewError(client.InvalidArgument{})
+	}
+	req, err := c.newRequest("PUT", bucket, object, nil, nil)
+	if err != nil {
+		return "", err.Trace()
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.presignRequest(req, expires)
+}
+
 // PutObject - put object
 func (c *s3Client) PutObject(size int64, data io.Reader) *probe.Error {
 	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
 	// for a multipart upload and there is no need to cross verify,
 	// invidual parts are properly verified
 	bucket, object := c.url2BucketAndObject()
+	if c.config.StorageClass != "" {
+		return probe.NewError(client.APINotImplemented{API: "StorageClass", APIType: "S3v2"})
+	}
 	err := c.api.PutObject(bucket, object, "application/octet-stream", size, data)
 	if err != nil {
 		errResponse := minio.ToErrorResponse(err)
@@ -131,6 +179,24 @@ func (c *s3Client) PutObject(size int64, data io.Reader) *probe.Error {
 	return nil
 }
 
+// Remove - remove an object, minio-go only accepts '200 OK' while S3 replies with '204 No Content'
+func (c *s3Client) Remove() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("DELETE", bucket, object, nil, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 // MakeBucket - make a new bucket
 func (c *s3Client) MakeBucket() *probe.Error {
 	bucket, object := c.url2BucketAndObject()
@@ -174,6 +240,112 @@ func (c *s3Client) SetBucketACL(acl string) *probe.Error {
 	return nil
 }
 
+// GetBucketPolicy bucket policies are not supported with signature V2
+func (c *s3Client) GetBucketPolicy() (policy string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: "S3v2"})
+}
+
+// SetBucketPolicy bucket policies are not supported with signature V2
+func (c *s3Client) SetBucketPolicy(policy string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: "S3v2"})
+}
+
+// RemoveBucketPolicy bucket policies are not supported with signature V2
+func (c *s3Client) RemoveBucketPolicy() *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RemoveBucketPolicy", APIType: "S3v2"})
+}
+
+// GetBucketLifecycle lifecycle configuration is not supported with signature V2
+func (c *s3Client) GetBucketLifecycle() (lifecycle string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketLifecycle", APIType: "S3v2"})
+}
+
+// SetBucketLifecycle lifecycle configuration is not supported with signature V2
+func (c *s3Client) SetBucketLifecycle(lifecycle string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketLifecycle", APIType: "S3v2"})
+}
+
+// RemoveBucketLifecycle lifecycle configuration is not supported with signature V2
+func (c *s3Client) RemoveBucketLifecycle() *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "RemoveBucketLifecycle", APIType: "S3v2"})
+}
+
+// GetBucketNotification notification is not supported with signature V2
+func (c *s3Client) GetBucketNotification() (notification string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketNotification", APIType: "S3v2"})
+}
+
+// SetBucketNotification notification is not supported with signature V2
+func (c *s3Client) SetBucketNotification(notification string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketNotification", APIType: "S3v2"})
+}
+
+// GetBucketVersioning versioning is not supported with signature V2
+func (c *s3Client) GetBucketVersioning() (status string, error *probe.Error) {
+	return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: "S3v2"})
+}
+
+// SetBucketVersioning versioning is not supported with signature V2
+func (c *s3Client) SetBucketVersioning(status string) *probe.Error {
+	return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: "S3v2"})
+}
+
+// ListVersions versioning is not supported with signature V2
+func (c *s3Client) ListVersions(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel, 1)
+	contentCh <- client.ContentOnChannel{Err: probe.NewError(client.APINotImplemented{API: "ListVersions", APIType: "S3v2"})}
+	close(contentCh)
+	return contentCh
+}
+
+// GetObjectACL get canned acl on an object
+func (c *s3Client) GetObjectACL() (acl string, error *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, object, url.Values{"acl": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err.Trace(bucket, object)
+	}
+	defer resp.Body.Close()
+	policy := accessControlPolicy{}
+	if e := xml.NewDecoder(resp.Body).Decode(&policy); e != nil {
+		return "", probe.NewError(e)
+	}
+	cannedACL, ok := policy.cannedACL()
+	if !ok {
+		return "", probe.NewError(client.InvalidACLType{ACL: "custom grants"})
+	}
+	return cannedACL, nil
+}
+
+// SetObjectACL set canned acl on an object
+func (c *s3Client) SetObjectACL(acl string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if !isValidCannedACL(acl) {
+		return probe.NewError(client.InvalidACLType{ACL: acl})
+	}
+	req, err := c.newRequest("PUT", bucket, object, url.Values{"acl": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	req.Header.Set("x-amz-acl", acl)
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object, acl)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 // Stat - send a 'HEAD' on a bucket or object to get its metadata
 func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 	objectMetadata := new(client.Content)
@@ -254,6 +426,11 @@ func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 
 /// Bucket API operations
 
+// Watch - cloud storage has no change stream, callers fall back to comparing listings
+func (c *s3Client) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventOnChannel, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "Watch", APIType: "S3v2"})
+}
+
 // List - list at delimited path, if not recursive
 func (c *s3Client) List(recursive bool) <-chan client.ContentOnChannel {
 	contentCh := make(chan client.ContentOnChannel)
@@ -298,6 +475,7 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 			content.Time = metadata.LastModified
 			content.Size = metadata.Size
 			content.Type = os.FileMode(0664)
+			content.Tags = c.listedTags(b, o)
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
@@ -326,6 +504,8 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 					content.Size = object.Stat.Size
 					content.Time = object.Stat.LastModified
 					content.Type = os.FileMode(0664)
+					content.Tags = c.listedTags(b, object.Stat.Key)
+					content.StorageClass = object.Stat.StorageClass
 				}
 				contentCh <- client.ContentOnChannel{
 					Content: content,
@@ -362,6 +542,8 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 				content.Size = object.Stat.Size
 				content.Time = object.Stat.LastModified
 				content.Type = os.FileMode(0664)
+				content.Tags = c.listedTags(bucket.Stat.Name, object.Stat.Key)
+				content.StorageClass = object.Stat.StorageClass
 				contentCh <- client.ContentOnChannel{
 					Content: content,
 					Err:     nil,
@@ -396,6 +578,8 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			content.Size = object.Stat.Size
 			content.Time = object.Stat.LastModified
 			content.Type = os.FileMode(0664)
+			content.Tags = c.listedTags(b, object.Stat.Key)
+			content.StorageClass = object.Stat.StorageClass
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
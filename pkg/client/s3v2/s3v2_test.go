@@ -20,13 +20,19 @@ package s3v2
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3test"
+	"github.com/minio/minio/pkg/probe"
 
 	. "gopkg.in/check.v1"
 )
@@ -196,3 +202,372 @@ func (s *MySuite) TestObjectOperations(c *C) {
 		c.Assert(buffer.Bytes(), DeepEquals, object.data)
 	}
 }
+
+func newServerClient(c *C, server *s3test.Server, path string) client.Client {
+	conf := new(client.Config)
+	conf.HostURL = server.URL + path
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	return s3c
+}
+
+func (s *MySuite) TestServerBucketOperations(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+
+	s3c := newServerClient(c, server, "/bucket")
+	c.Assert(s3c.MakeBucket(), IsNil)
+	c.Assert(s3c.MakeBucket(), Not(IsNil))
+
+	for _, acl := range []string{"public-read", "public-read-write", "authenticated-read", "private"} {
+		c.Assert(s3c.SetBucketACL(acl), IsNil)
+		bucketACL, err := s3c.GetBucketACL()
+		c.Assert(err, IsNil)
+		c.Assert(bucketACL, Equals, acl)
+	}
+
+	var buckets []string
+	for content := range newServerClient(c, server, "/").List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Type.IsDir(), Equals, true)
+		buckets = append(buckets, content.Content.Name)
+	}
+	c.Assert(buckets, DeepEquals, []string{"bucket"})
+
+	// signatures with a wrong secret are rejected
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/newbucket"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = "WRONGSECRET"
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	c.Assert(s3c.MakeBucket(), Not(IsNil))
+	_, ok := server.BucketACL("newbucket")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestServerListObjects(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+
+	server.MakeBucket("bucket", "private")
+	for _, object := range []string{"a/1", "a/2", "b", "c/d/e"} {
+		c.Assert(server.PutObject("bucket", object, []byte(object)), Equals, true)
+	}
+
+	var names []string
+	for content := range newServerClient(c, server, "/bucket").List(false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"b", "a/", "c/"})
+
+	names = nil
+	for content := range newServerClient(c, server, "/bucket/").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"a/1", "a/2", "b", "c/d/e"})
+}
+
+func (s *MySuite) TestServerObjectOperations(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	// 6MB goes through a multipart upload
+	data := bytes.Repeat([]byte("0123456789abcdef"), 6*1024*1024/16)
+	s3c := newServerClient(c, server, "/bucket/object")
+	c.Assert(s3c.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+
+	content, err := s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(data)))
+
+	reader, size, err := s3c.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	received, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(bytes.Equal(received, data), Equals, true)
+
+	// anonymous requests are only allowed on public buckets
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	anonymous, err := New(conf)
+	c.Assert(err, IsNil)
+	_, err = anonymous.Stat()
+	c.Assert(err, Not(IsNil))
+	c.Assert(newServerClient(c, server, "/bucket").SetBucketACL("public-read"), IsNil)
+	_, err = anonymous.Stat()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestServerObjectACL(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket/object")
+	acl, err := s3c.GetObjectACL()
+	c.Assert(err, IsNil)
+	c.Assert(acl, Equals, "private")
+
+	resp, e := http.Get(server.URL + "/bucket/object")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	for _, acl := range []string{"authenticated-read", "public-read-write", "public-read"} {
+		c.Assert(s3c.SetObjectACL(acl), IsNil)
+		objectACL, err := s3c.GetObjectACL()
+		c.Assert(err, IsNil)
+		c.Assert(objectACL, Equals, acl)
+		serverACL, ok := server.ObjectACL("bucket", "object")
+		c.Assert(ok, Equals, true)
+		c.Assert(serverACL, Equals, acl)
+	}
+
+	// public-read objects are readable without credentials
+	resp, e = http.Get(server.URL + "/bucket/object")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+
+	c.Assert(s3c.SetObjectACL("invalid"), Not(IsNil))
+	c.Assert(newServerClient(c, server, "/bucket/missing").SetObjectACL("private"), Not(IsNil))
+	_, err = newServerClient(c, server, "/bucket").GetObjectACL()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerShare(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	presignedURL, err := newServerClient(c, server, "/bucket/object").ShareDownload(time.Hour, nil)
+	c.Assert(err, IsNil)
+	resp, e := http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	received, e := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(e, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(string(received), Equals, "Hello, World")
+
+	// response header overrides are signed into the url
+	reqParams := make(url.Values)
+	reqParams.Set("response-content-disposition", `attachment; filename="hello world.txt"`)
+	reqParams.Set("response-content-type", "text/plain")
+	presignedURL, err = newServerClient(c, server, "/bucket/object").ShareDownload(time.Hour, reqParams)
+	c.Assert(err, IsNil)
+	resp, e = http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Content-Disposition"), Equals, `attachment; filename="hello world.txt"`)
+	c.Assert(resp.Header.Get("Content-Type"), Equals, "text/plain")
+	resp, e = http.Get(strings.Replace(presignedURL, "text%2Fplain", "text%2Fhtml", 1))
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	formData, err := newServerClient(c, server, "/bucket/uploads/").ShareUpload(true, time.Hour, "text/plain")
+	c.Assert(err, IsNil)
+	formData["key"] = formData["key"] + "file"
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e := writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// a key outside of the policy prefix is refused
+	formData["key"] = "elsewhere"
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e = writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+}
+
+func (s *MySuite) TestServerSharePut(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	presignedURL, err := newServerClient(c, server, "/bucket/uploads/file").ShareUploadURL(time.Hour, "text/plain")
+	c.Assert(err, IsNil)
+	req, e := http.NewRequest("PUT", presignedURL, bytes.NewReader([]byte("uploaded")))
+	c.Assert(e, IsNil)
+	req.Header.Set("Content-Type", "text/plain")
+	resp, e := http.DefaultClient.Do(req)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// the content type is part of the signature
+	req, e = http.NewRequest("PUT", presignedURL, bytes.NewReader([]byte("uploaded")))
+	c.Assert(e, IsNil)
+	req.Header.Set("Content-Type", "image/png")
+	resp, e = http.DefaultClient.Do(req)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	_, err = newServerClient(c, server, "/bucket/uploads/file").ShareUploadURL(8*24*time.Hour, "")
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerSessionToken(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	server.SessionToken = "TOKEN"
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	// requests without the session token are refused
+	_, err := newServerClient(c, server, "/bucket/object").Stat()
+	c.Assert(err, Not(IsNil))
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	conf.SessionToken = "TOKEN"
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	// minio-go requests are signed again with the token
+	c.Assert(s3c.PutObject(int64(len("Hello, World")), strings.NewReader("Hello, World")), IsNil)
+	content, err := s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len("Hello, World")))
+	_, err = s3c.GetObjectACL()
+	c.Assert(err, IsNil)
+
+	presignedURL, err := s3c.ShareDownload(time.Hour, nil)
+	c.Assert(err, IsNil)
+	resp, e := http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	resp, e = http.Get(strings.Replace(presignedURL, "TOKEN", "OTHER", 1))
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Not(Equals), http.StatusOK)
+
+	conf.HostURL = server.URL + "/bucket/uploads/"
+	uploads, err := New(conf)
+	c.Assert(err, IsNil)
+	formData, err := uploads.ShareUpload(true, time.Hour, "")
+	c.Assert(err, IsNil)
+	c.Assert(formData["x-amz-security-token"], Equals, "TOKEN")
+	formData["key"] = formData["key"] + "file"
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e := writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// renewed credentials are picked up before every request
+	var refreshed int
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.SessionToken = "EXPIRED"
+	conf.Refresh = func() (client.Credentials, *probe.Error) {
+		refreshed++
+		return client.Credentials{AccessKeyID: "ACCESSKEY", SecretAccessKey: "SECRETKEY", SessionToken: "TOKEN"}, nil
+	}
+	s3c, err = New(conf)
+	c.Assert(err, IsNil)
+	_, err = s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(refreshed > 0, Equals, true)
+	previous := refreshed
+	_, err = s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(refreshed > previous, Equals, true)
+}
+
+func (s *MySuite) TestServerObjectTags(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket/object")
+	c.Assert(s3c.SetObjectTags(map[string]string{"team": "storage"}), IsNil)
+	tags, err := s3c.GetObjectTags()
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"team": "storage"})
+
+	c.Assert(s3c.RemoveObjectTags(), IsNil)
+	tags, err = s3c.GetObjectTags()
+	c.Assert(err, IsNil)
+	c.Assert(tags, HasLen, 0)
+	_, ok := server.ObjectTags("bucket", "object")
+	c.Assert(ok, Equals, true)
+}
+
+func (s *MySuite) TestServerStorageClass(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	clnt, err := New(conf)
+	c.Assert(err, IsNil)
+	for content := range clnt.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.StorageClass, Equals, "STANDARD")
+	}
+
+	// uploads with a storage class need signature V4
+	conf.HostURL = server.URL + "/bucket/new"
+	conf.StorageClass = "REDUCED_REDUNDANCY"
+	clnt, err = New(conf)
+	c.Assert(err, IsNil)
+	err = clnt.PutObject(5, bytes.NewReader([]byte("hello")))
+	c.Assert(err, Not(IsNil))
+	_, ok := err.ToGoError().(client.APINotImplemented)
+	c.Assert(ok, Equals, true)
+}
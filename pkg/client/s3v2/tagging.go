@@ -0,0 +1,143 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3v2
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/url"
+	"sort"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+type tag struct {
+	Key   string
+	Value string
+}
+
+// tagging - tag set of an object
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	TagSet  []tag    `xml:"TagSet>Tag"`
+}
+
+// objectTags - get the tags of an object
+func (c *s3Client) objectTags(bucket, object string) (map[string]string, *probe.Error) {
+	req, err := c.newRequest("GET", bucket, object, url.Values{"tagging": {""}}, nil)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	defer resp.Body.Close()
+	// servers unaware of the tagging sub-resource reply with the object itself
+	t := tagging{}
+	if e := xml.NewDecoder(resp.Body).Decode(&t); e != nil {
+		return nil, probe.NewError(client.APINotImplemented{API: "GetObjectTags", APIType: "S3v2"})
+	}
+	tags := make(map[string]string)
+	for _, tag := range t.TagSet {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// listedTags - tags of a listed object, only fetched if requested. Tags are informational
+// while listing, objects whose tags can not be read are listed without them.
+func (c *s3Client) listedTags(bucket, object string) map[string]string {
+	if !c.config.ListTags {
+		return nil
+	}
+	tags, err := c.objectTags(bucket, object)
+	if err != nil || len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// GetObjectTags get the tags of an object
+func (c *s3Client) GetObjectTags() (map[string]string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return nil, probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	tags, err := c.objectTags(bucket, object)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	return tags, nil
+}
+
+// SetObjectTags replace the tags of an object
+func (c *s3Client) SetObjectTags(tags map[string]string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	t := tagging{TagSet: []tag{}}
+	for key, value := range tags {
+		t.TagSet = append(t.TagSet, tag{Key: key, Value: value})
+	}
+	sort.Sort(byTagKey(t.TagSet))
+	body, e := xml.Marshal(t)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req, err := c.newRequest("PUT", bucket, object, url.Values{"tagging": {""}}, body)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	// Content-MD5 is mandatory for this API
+	md5Sum := md5.Sum(body)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveObjectTags remove all tags of an object
+func (c *s3Client) RemoveObjectTags() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("DELETE", bucket, object, url.Values{"tagging": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type byTagKey []tag
+
+func (t byTagKey) Len() int           { return len(t) }
+func (t byTagKey) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byTagKey) Less(i, j int) bool { return t[i].Key < t[j].Key }
@@ -0,0 +1,463 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3v4
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// minio-go only implements bucket, object and presigned operations, every other
+// S3 API is sent through the signature V4 requests below.
+
+const (
+	signV4Algorithm   = "AWS4-HMAC-SHA256"
+	iso8601DateFormat = "20060102T150405Z"
+	yyyymmdd          = "20060102"
+
+	// expirationDateFormat of POST policies, same as minio-go
+	expirationDateFormat = "2006-01-02T15:04:05.999Z"
+)
+
+// regions s3 region map, same as the one used by minio-go
+var regions = map[string]string{
+	"s3-fips-us-gov-west-1.amazonaws.com": "us-gov-west-1",
+	"s3.amazonaws.com":                    "us-east-1",
+	"s3-external-1.amazonaws.com":         "us-east-1",
+	"s3-us-west-1.amazonaws.com":          "us-west-1",
+	"s3-us-west-2.amazonaws.com":          "us-west-2",
+	"s3-eu-west-1.amazonaws.com":          "eu-west-1",
+	"s3-eu-central-1.amazonaws.com":       "eu-central-1",
+	"s3-ap-southeast-1.amazonaws.com":     "ap-southeast-1",
+	"s3-ap-southeast-2.amazonaws.com":     "ap-southeast-2",
+	"s3-ap-northeast-1.amazonaws.com":     "ap-northeast-1",
+	"s3-sa-east-1.amazonaws.com":          "sa-east-1",
+	"s3.cn-north-1.amazonaws.com.cn":      "cn-north-1",
+}
+
+// getRegion returns a region based on its endpoint mapping, defaults to "milkyway" like minio-go
+func getRegion(host string) string {
+	if region, ok := regions[host]; ok {
+		return region
+	}
+	return "milkyway"
+}
+
+// headers which are never signed, see minio-go for the reasoning behind each
+var ignoredHeaders = map[string]bool{
+	"Authorization":  true,
+	"Content-Type":   true,
+	"Content-Length": true,
+	"User-Agent":     true,
+}
+
+func sumHMAC(key []byte, data []byte) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func sum256Hex(data []byte) string {
+	hash := sha256.New()
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// encodePath percent encodes everything but the unreserved characters and '/'
+func encodePath(path string) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(path) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9':
+			buf.WriteByte(b)
+		case b == '-', b == '_', b == '.', b == '~', b == '/':
+			buf.WriteByte(b)
+		default:
+			buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+		}
+	}
+	return buf.String()
+}
+
+// endpoint returns the host to send requests to, whether requests are virtual host styled and the region
+func (c *s3Client) endpoint() (host string, isVirtualStyle bool, region string) {
+	host = c.hostURL.Host
+	regionHost := host
+	if match, _ := filepath.Match("*.s3*.amazonaws.com", host); match {
+		isVirtualStyle = true
+		regionHost = strings.SplitN(host, ".", 2)[1]
+	}
+	return host, isVirtualStyle, getRegion(regionHost)
+}
+
+// newRequest creates a new request for a bucket or an object, query values are the sub-resources
+func (c *s3Client) newRequest(method, bucket, object string, query url.Values, body []byte) (*http.Request, *probe.Error) {
+	host, isVirtualStyle, _ := c.endpoint()
+	path := "/"
+	if !isVirtualStyle && bucket != "" {
+		path += bucket
+		if object != "" {
+			path += "/"
+		}
+	}
+	path += object
+	u := c.hostURL.Scheme + c.hostURL.SchemeSeparator + host + encodePath(path)
+	if len(query) > 0 {
+		u += "?" + strings.Replace(query.Encode(), "+", "%20", -1)
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, probe.NewError(err)
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("User-Agent", c.userAgent())
+	req.Header.Set("X-Amz-Content-Sha256", sum256Hex(body))
+	return req, nil
+}
+
+func (c *s3Client) userAgent() string {
+	userAgent := "Minio (" + c.config.AppName + "/" + c.config.AppVersion
+	if len(c.config.AppComments) > 0 {
+		userAgent += "; " + strings.Join(c.config.AppComments, "; ")
+	}
+	return userAgent + ")"
+}
+
+// canonicalRequest http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func canonicalRequest(req *http.Request, hashedPayload string) (canonical, signedHeaders string) {
+	var headers []string
+	values := make(map[string][]string)
+	for k, v := range req.Header {
+		if ignoredHeaders[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		headers = append(headers, strings.ToLower(k))
+		values[strings.ToLower(k)] = v
+	}
+	headers = append(headers, "host")
+	values["host"] = []string{req.URL.Host}
+	sort.Strings(headers)
+
+	var buf bytes.Buffer
+	for _, k := range headers {
+		buf.WriteString(k + ":" + strings.Join(values[k], ",") + "\n")
+	}
+	signedHeaders = strings.Join(headers, ";")
+	req.URL.RawQuery = strings.Replace(req.URL.Query().Encode(), "+", "%20", -1)
+	canonical = strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		req.URL.RawQuery,
+		buf.String(),
+		signedHeaders,
+		hashedPayload,
+	}, "\n")
+	return canonical, signedHeaders
+}
+
+// scope returns the credential scope of a signature computed at t
+func (c *s3Client) scope(t time.Time) string {
+	_, _, region := c.endpoint()
+	return strings.Join([]string{t.Format(yyyymmdd), region, "s3", "aws4_request"}, "/")
+}
+
+// signingKey derives the key of a day and region from the secret access key
+func (c *s3Client) signingKey(secretAccessKey string, t time.Time) []byte {
+	_, _, region := c.endpoint()
+	signingKey := sumHMAC([]byte("AWS4"+secretAccessKey), []byte(t.Format(yyyymmdd)))
+	signingKey = sumHMAC(signingKey, []byte(region))
+	signingKey = sumHMAC(signingKey, []byte("s3"))
+	return sumHMAC(signingKey, []byte("aws4_request"))
+}
+
+// signature computes the final signature and returns it along with the credential scope
+func (c *s3Client) signature(secretAccessKey, canonical string, t time.Time) (signature, scope string) {
+	scope = c.scope(t)
+	stringToSign := signV4Algorithm + "\n" + t.Format(iso8601DateFormat) + "\n" + scope + "\n" + sum256Hex([]byte(canonical))
+	return hex.EncodeToString(sumHMAC(c.signingKey(secretAccessKey, t), []byte(stringToSign))), scope
+}
+
+// isAnonymous requests are sent unsigned
+func (c *s3Client) isAnonymous() bool {
+	return c.config.AccessKeyID == "" || c.config.SecretAccessKey == ""
+}
+
+// isTemporary reports if requests carry a session token or credentials which
+// are renewed, minio-go can sign neither
+func (c *s3Client) isTemporary() bool {
+	return c.config.SessionToken != "" || c.config.Refresh != nil
+}
+
+// credentials returns the keys to sign with, renewed by the config if it can
+func (c *s3Client) credentials() (client.Credentials, *probe.Error) {
+	if c.config.Refresh != nil {
+		creds, err := c.config.Refresh()
+		if err != nil {
+			return client.Credentials{}, err.Trace()
+		}
+		return creds, nil
+	}
+	return client.Credentials{
+		AccessKeyID:     c.config.AccessKeyID,
+		SecretAccessKey: c.config.SecretAccessKey,
+		SessionToken:    c.config.SessionToken,
+	}, nil
+}
+
+// signRequest adds a signature V4 authorization header to the request
+func (c *s3Client) signRequest(req *http.Request) *probe.Error {
+	if c.isAnonymous() {
+		return nil
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return err.Trace()
+	}
+	t := time.Now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	canonical, signedHeaders := canonicalRequest(req, req.Header.Get("X-Amz-Content-Sha256"))
+	signature, scope := c.signature(creds.SecretAccessKey, canonical, t)
+	req.Header.Set("Authorization", signV4Algorithm+" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+// presignRequest returns a query string signed URL for the request valid until expires
+func (c *s3Client) presignRequest(req *http.Request, expires time.Duration) (string, *probe.Error) {
+	expireSeconds := int64(expires / time.Second)
+	if expireSeconds < 1 || expireSeconds > 604800 {
+		return "", probe.NewError(client.InvalidArgument{})
+	}
+	if c.isAnonymous() {
+		return "", probe.NewError(errors.New("Presigning requires an access key and a secret key."))
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return "", err.Trace()
+	}
+	req.Header.Del("X-Amz-Content-Sha256")
+	t := time.Now().UTC()
+	query := req.URL.Query()
+	query.Set("X-Amz-Algorithm", signV4Algorithm)
+	query.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(expireSeconds, 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	query.Set("X-Amz-Credential", creds.AccessKeyID+"/"+c.scope(t))
+	if creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	presigned := &http.Request{Method: req.Method, URL: req.URL, Header: make(http.Header)}
+	canonical, _ := canonicalRequest(presigned, "UNSIGNED-PAYLOAD")
+	signature, _ := c.signature(creds.SecretAccessKey, canonical, t)
+	return req.URL.String() + "&X-Amz-Signature=" + signature, nil
+}
+
+// presignPostPolicy returns the form of a browser based upload signed with
+// temporary credentials, which minio-go can not add to the policy
+func (c *s3Client) presignPostPolicy(bucket, object string, recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
+	if c.isAnonymous() {
+		return nil, probe.NewError(errors.New("Presigning requires an access key and a secret key."))
+	}
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	t := time.Now().UTC()
+	formData := map[string]string{
+		"bucket":           bucket,
+		"key":              object,
+		"x-amz-algorithm":  signV4Algorithm,
+		"x-amz-credential": creds.AccessKeyID + "/" + c.scope(t),
+		"x-amz-date":       t.Format(iso8601DateFormat),
+	}
+	conditions := [][]string{{"eq", "$bucket", bucket}}
+	if recursive {
+		conditions = append(conditions, []string{"starts-with", "$key", object})
+	} else {
+		conditions = append(conditions, []string{"eq", "$key", object})
+	}
+	if contentType != "" {
+		formData["Content-Type"] = contentType
+		conditions = append(conditions, []string{"eq", "$Content-Type", contentType})
+	}
+	if creds.SessionToken != "" {
+		formData["x-amz-security-token"] = creds.SessionToken
+		conditions = append(conditions, []string{"eq", "$x-amz-security-token", creds.SessionToken})
+	}
+	conditions = append(conditions,
+		[]string{"eq", "$x-amz-date", formData["x-amz-date"]},
+		[]string{"eq", "$x-amz-algorithm", signV4Algorithm},
+		[]string{"eq", "$x-amz-credential", formData["x-amz-credential"]})
+	policy, e := json.Marshal(struct {
+		Expiration string     `json:"expiration"`
+		Conditions [][]string `json:"conditions"`
+	}{t.Add(expires).Format(expirationDateFormat), conditions})
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	formData["policy"] = base64.StdEncoding.EncodeToString(policy)
+	formData["x-amz-signature"] = hex.EncodeToString(sumHMAC(c.signingKey(creds.SecretAccessKey, t), []byte(formData["policy"])))
+	return formData, nil
+}
+
+// sessionTokenTransport signs the requests of minio-go again with the current
+// credentials and their session token, unsigned requests are passed through
+type sessionTokenTransport struct {
+	client    *s3Client
+	transport http.RoundTripper
+}
+
+func (t sessionTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		return t.transport.RoundTrip(req)
+	}
+	req.Header.Del("Authorization")
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	}
+	if err := t.client.signRequest(req); err != nil {
+		return nil, err.ToGoError()
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// do signs and sends the request, responses other than 2xx are returned as minio.ErrorResponse
+func (c *s3Client) do(req *http.Request) (*http.Response, *probe.Error) {
+	if err := c.signRequest(req); err != nil {
+		return nil, err.Trace()
+	}
+	// RoundTrip directly like minio-go does, redirects are not followed
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, probe.NewError(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	errResponse := minio.ErrorResponse{}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil || xml.Unmarshal(data, &errResponse) != nil || errResponse.Code == "" {
+		errResponse.Code = http.StatusText(resp.StatusCode)
+		errResponse.Message = resp.Status
+		errResponse.Resource = req.URL.Path
+		errResponse.RequestID = resp.Header.Get("x-amz-request-id")
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			errResponse.Code = "NotFound"
+		case http.StatusNotImplemented:
+			errResponse.Code = "NotImplemented"
+		case http.StatusMethodNotAllowed:
+			errResponse.Code = "MethodNotAllowed"
+		}
+	}
+	return nil, probe.NewError(errResponse)
+}
+
+// isNotImplemented reports if the server does not support the requested sub-resource
+func isNotImplemented(err *probe.Error) bool {
+	errResponse := minio.ToErrorResponse(err.ToGoError())
+	if errResponse == nil {
+		return false
+	}
+	switch errResponse.Code {
+	case "NotImplemented", "MethodNotAllowed", "NotSupported", "InvalidRequest":
+		return true
+	}
+	return false
+}
+
+// errorCode returns the S3 error code of a failed request, empty if the error is not an S3 error
+func errorCode(err *probe.Error) string {
+	errResponse := minio.ToErrorResponse(err.ToGoError())
+	if errResponse == nil {
+		return ""
+	}
+	return errResponse.Code
+}
+
+// accessControlPolicy - access control list of a bucket or an object
+type accessControlPolicy struct {
+	AccessControlList struct {
+		Grant []struct {
+			Grantee struct {
+				ID  string
+				URI string
+			}
+			Permission string
+		}
+	}
+}
+
+// cannedACL converts the grants back to a canned acl, same as minio-go does for buckets
+func (p accessControlPolicy) cannedACL() (string, bool) {
+	grants := p.AccessControlList.Grant
+	switch len(grants) {
+	case 1:
+		if grants[0].Grantee.URI == "" && grants[0].Permission == "FULL_CONTROL" {
+			return "private", true
+		}
+	case 2:
+		for _, g := range grants {
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AuthenticatedUsers" && g.Permission == "READ" {
+				return "authenticated-read", true
+			}
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "READ" {
+				return "public-read", true
+			}
+		}
+	case 3:
+		for _, g := range grants {
+			if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "WRITE" {
+				return "public-read-write", true
+			}
+		}
+	}
+	return "", false
+}
+
+// isValidCannedACL - canned acls supported by mc
+func isValidCannedACL(acl string) bool {
+	switch acl {
+	case "private", "public-read", "public-read-write", "authenticated-read":
+		return true
+	}
+	return false
+}
@@ -17,9 +17,15 @@
 package s3v4
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,8 +38,11 @@ import (
 )
 
 type s3Client struct {
-	api     minio.API
-	hostURL *client.URL
+	api       minio.API
+	hostURL   *client.URL
+	config    *client.Config
+	transport http.RoundTripper
+	versionID string
 }
 
 // New returns an initialized s3Client structure. if debug use a internal trace transport
@@ -54,11 +63,16 @@ func New(config *client.Config) (client.Client, *probe.Error) {
 	s3Conf.Transport = transport
 	s3Conf.SetUserAgent(config.AppName, config.AppVersion, config.AppComments...)
 	s3Conf.Endpoint = u.Scheme + u.SchemeSeparator + u.Host
+	c := &s3Client{hostURL: u, config: config, transport: transport, versionID: versionID(config.HostURL)}
+	if c.isTemporary() {
+		s3Conf.Transport = sessionTokenTransport{client: c, transport: transport}
+	}
 	api, err := minio.New(s3Conf)
 	if err != nil {
 		return nil, probe.NewError(err)
 	}
-	return &s3Client{api: api, hostURL: u}, nil
+	c.api = api
+	return c, nil
 }
 
 // URL get url
@@ -68,6 +82,9 @@ func (c *s3Client) URL() *client.URL {
 
 // GetObject - get object
 func (c *s3Client) GetObject(offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+	if c.versionID != "" {
+		return c.getObjectVersion(offset, length)
+	}
 	bucket, object := c.url2BucketAndObject()
 	reader, metadata, err := c.api.GetPartialObject(bucket, object, offset, length)
 	if err != nil {
@@ -76,9 +93,17 @@ func (c *s3Client) GetObject(offset, length int64) (io.ReadCloser, int64, *probe
 	return reader, metadata.Size, nil
 }
 
-// Share - get a usable get object url to share
-func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
+// Share - get a usable get object url to share, reqParams override the response headers
+func (c *s3Client) ShareDownload(expires time.Duration, reqParams url.Values) (string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
+	if len(reqParams) > 0 || c.isTemporary() {
+		// minio-go can not sign response header overrides nor session tokens
+		req, err := c.newRequest("GET", bucket, object, reqParams, nil)
+		if err != nil {
+			return "", err.Trace()
+		}
+		return c.presignRequest(req, expires)
+	}
 	presignedURL, err := c.api.PresignedGetObject(bucket, object, expires)
 	if err != nil {
 		return "", probe.NewError(err)
@@ -88,6 +113,12 @@ func (c *s3Client) ShareDownload(expires time.Duration) (string, *probe.Error) {
 
 func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentType string) (map[string]string, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
+	if c.isTemporary() {
+		if bucket == "" || strings.TrimSpace(object) == "" {
+			return nil, probe.NewError(client.InvalidArgument{})
+		}
+		return c.presignPostPolicy(bucket, object, recursive, expires, strings.TrimSpace(contentType))
+	}
 	p := minio.NewPostPolicy()
 	if err := p.SetExpires(time.Now().UTC().Add(expires)); err != nil {
 		return nil, probe.NewError(err)
@@ -112,12 +143,28 @@ func (c *s3Client) ShareUpload(recursive bool, expires time.Duration, contentTyp
 	return m, probe.NewError(err)
 }
 
+// ShareUploadURL - get a presigned put object url to share, content type is left to the uploader
+func (c *s3Client) ShareUploadURL(expires time.Duration, contentType string) (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", probe.NewError(client.InvalidArgument{})
+	}
+	req, err := c.newRequest("PUT", bucket, object, nil, nil)
+	if err != nil {
+		return "", err.Trace()
+	}
+	return c.presignRequest(req, expires)
+}
+
 // PutObject - put object
 func (c *s3Client) PutObject(size int64, data io.Reader) *probe.Error {
 	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
 	// for a multipart upload and there is no need to cross verify,
 	// invidual parts are properly verified
 	bucket, object := c.url2BucketAndObject()
+	if c.config.StorageClass != "" {
+		return c.putObjectStorageClass(bucket, object, size, data).Trace(bucket, object)
+	}
 	err := c.api.PutObject(bucket, object, "application/octet-stream", size, data)
 	if err != nil {
 		errResponse := minio.ToErrorResponse(err)
@@ -131,6 +178,24 @@ func (c *s3Client) PutObject(size int64, data io.Reader) *probe.Error {
 	return nil
 }
 
+// Remove - remove an object, minio-go only accepts '200 OK' while S3 replies with '204 No Content'
+func (c *s3Client) Remove() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("DELETE", bucket, object, nil, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 // MakeBucket - make a new bucket
 func (c *s3Client) MakeBucket() *probe.Error {
 	bucket, object := c.url2BucketAndObject()
@@ -174,8 +239,276 @@ func (c *s3Client) SetBucketACL(acl string) *probe.Error {
 	return nil
 }
 
+// GetBucketPolicy get the bucket policy document, empty if the bucket has no policy
+func (c *s3Client) GetBucketPolicy() (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, "", url.Values{"policy": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		switch {
+		case errorCode(err) == "NoSuchBucketPolicy":
+			return "", nil
+		case isNotImplemented(err):
+			return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: c.apiType()})
+		}
+		return "", err.Trace(bucket)
+	}
+	defer resp.Body.Close()
+	policy, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	// servers unaware of the policy sub-resource reply with a bucket listing
+	if !json.Valid(policy) {
+		return "", probe.NewError(client.APINotImplemented{API: "GetBucketPolicy", APIType: c.apiType()})
+	}
+	return string(policy), nil
+}
+
+// SetBucketPolicy set a bucket policy document, replaces any existing policy
+func (c *s3Client) SetBucketPolicy(policy string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("PUT", bucket, "", url.Values{"policy": {""}}, []byte(policy))
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return probe.NewError(client.APINotImplemented{API: "SetBucketPolicy", APIType: c.apiType()})
+		}
+		return err.Trace(bucket)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveBucketPolicy remove the bucket policy document
+func (c *s3Client) RemoveBucketPolicy() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("DELETE", bucket, "", url.Values{"policy": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		switch {
+		case errorCode(err) == "NoSuchBucketPolicy":
+			return nil
+		case isNotImplemented(err):
+			return probe.NewError(client.APINotImplemented{API: "RemoveBucketPolicy", APIType: c.apiType()})
+		}
+		return err.Trace(bucket)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetBucketLifecycle get the bucket lifecycle configuration, empty if the bucket has none
+func (c *s3Client) GetBucketLifecycle() (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, "", url.Values{"lifecycle": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		switch {
+		case errorCode(err) == "NoSuchLifecycleConfiguration":
+			return "", nil
+		case isNotImplemented(err):
+			return "", probe.NewError(client.APINotImplemented{API: "GetBucketLifecycle", APIType: c.apiType()})
+		}
+		return "", err.Trace(bucket)
+	}
+	defer resp.Body.Close()
+	lifecycle, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	// servers unaware of the lifecycle sub-resource reply with a bucket listing
+	root := struct {
+		XMLName xml.Name
+	}{}
+	if xml.Unmarshal(lifecycle, &root) != nil || root.XMLName.Local != "LifecycleConfiguration" {
+		return "", probe.NewError(client.APINotImplemented{API: "GetBucketLifecycle", APIType: c.apiType()})
+	}
+	return string(lifecycle), nil
+}
+
+// SetBucketLifecycle set a bucket lifecycle configuration, replaces any existing configuration
+func (c *s3Client) SetBucketLifecycle(lifecycle string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("PUT", bucket, "", url.Values{"lifecycle": {""}}, []byte(lifecycle))
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	// Content-MD5 is mandatory for this API
+	md5Sum := md5.Sum([]byte(lifecycle))
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return probe.NewError(client.APINotImplemented{API: "SetBucketLifecycle", APIType: c.apiType()})
+		}
+		return err.Trace(bucket)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveBucketLifecycle remove the bucket lifecycle configuration
+func (c *s3Client) RemoveBucketLifecycle() *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("DELETE", bucket, "", url.Values{"lifecycle": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		switch {
+		case errorCode(err) == "NoSuchLifecycleConfiguration":
+			return nil
+		case isNotImplemented(err):
+			return probe.NewError(client.APINotImplemented{API: "RemoveBucketLifecycle", APIType: c.apiType()})
+		}
+		return err.Trace(bucket)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetBucketNotification get the bucket notification configuration
+func (c *s3Client) GetBucketNotification() (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, "", url.Values{"notification": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return "", probe.NewError(client.APINotImplemented{API: "GetBucketNotification", APIType: c.apiType()})
+		}
+		return "", err.Trace(bucket)
+	}
+	defer resp.Body.Close()
+	notification, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	// servers unaware of the notification sub-resource reply with a bucket listing
+	root := struct {
+		XMLName xml.Name
+	}{}
+	if xml.Unmarshal(notification, &root) != nil || root.XMLName.Local != "NotificationConfiguration" {
+		return "", probe.NewError(client.APINotImplemented{API: "GetBucketNotification", APIType: c.apiType()})
+	}
+	return string(notification), nil
+}
+
+// SetBucketNotification set a bucket notification configuration, replaces any existing
+// configuration. An empty configuration disables all notifications.
+func (c *s3Client) SetBucketNotification(notification string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("PUT", bucket, "", url.Values{"notification": {""}}, []byte(notification))
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return probe.NewError(client.APINotImplemented{API: "SetBucketNotification", APIType: c.apiType()})
+		}
+		return err.Trace(bucket)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObjectACL get canned acl on an object
+func (c *s3Client) GetObjectACL() (acl string, error *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, object, url.Values{"acl": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err.Trace(bucket, object)
+	}
+	defer resp.Body.Close()
+	policy := accessControlPolicy{}
+	if e := xml.NewDecoder(resp.Body).Decode(&policy); e != nil {
+		return "", probe.NewError(e)
+	}
+	cannedACL, ok := policy.cannedACL()
+	if !ok {
+		return "", probe.NewError(client.InvalidACLType{ACL: "custom grants"})
+	}
+	return cannedACL, nil
+}
+
+// SetObjectACL set canned acl on an object
+func (c *s3Client) SetObjectACL(acl string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if !isValidCannedACL(acl) {
+		return probe.NewError(client.InvalidACLType{ACL: acl})
+	}
+	req, err := c.newRequest("PUT", bucket, object, url.Values{"acl": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	req.Header.Set("x-amz-acl", acl)
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object, acl)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 // Stat - send a 'HEAD' on a bucket or object to get its metadata
 func (c *s3Client) Stat() (*client.Content, *probe.Error) {
+	if c.versionID != "" {
+		return c.statObjectVersion()
+	}
 	objectMetadata := new(client.Content)
 	bucket, object := c.url2BucketAndObject()
 	switch {
@@ -211,6 +544,7 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 		objectMetadata.Time = metadata.LastModified
 		objectMetadata.Size = metadata.Size
 		objectMetadata.Type = os.FileMode(0664)
+		objectMetadata.StorageClass = c.listedStorageClass(bucket, object)
 		return objectMetadata, nil
 	}
 	err := c.api.BucketExists(bucket)
@@ -223,6 +557,11 @@ func (c *s3Client) Stat() (*client.Content, *probe.Error) {
 	return bucketMetadata, nil
 }
 
+// apiType describes the server in typed errors
+func (c *s3Client) apiType() string {
+	return c.hostURL.Scheme + c.hostURL.SchemeSeparator + c.hostURL.Host
+}
+
 // url2BucketAndObject gives bucketName and objectName from URL path
 func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 	path := c.hostURL.Path
@@ -253,6 +592,11 @@ func (c *s3Client) url2BucketAndObject() (bucketName, objectName string) {
 
 /// Bucket API operations
 
+// Watch - cloud storage has no change stream, callers fall back to comparing listings
+func (c *s3Client) Watch(recursive bool, doneCh <-chan struct{}) (<-chan client.EventOnChannel, *probe.Error) {
+	return nil, probe.NewError(client.APINotImplemented{API: "Watch", APIType: c.apiType()})
+}
+
 // List - list at delimited path, if not recursive
 func (c *s3Client) List(recursive bool) <-chan client.ContentOnChannel {
 	contentCh := make(chan client.ContentOnChannel)
@@ -297,6 +641,8 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 			content.Time = metadata.LastModified
 			content.Size = metadata.Size
 			content.Type = os.FileMode(0664)
+			content.Tags = c.listedTags(b, o)
+			content.StorageClass = c.listedStorageClass(b, o)
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
@@ -325,6 +671,8 @@ func (c *s3Client) listInRoutine(contentCh chan client.ContentOnChannel) {
 					content.Size = object.Stat.Size
 					content.Time = object.Stat.LastModified
 					content.Type = os.FileMode(0664)
+					content.Tags = c.listedTags(b, object.Stat.Key)
+					content.StorageClass = object.Stat.StorageClass
 				}
 				contentCh <- client.ContentOnChannel{
 					Content: content,
@@ -361,6 +709,8 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 				content.Size = object.Stat.Size
 				content.Time = object.Stat.LastModified
 				content.Type = os.FileMode(0664)
+				content.Tags = c.listedTags(bucket.Stat.Name, object.Stat.Key)
+				content.StorageClass = object.Stat.StorageClass
 				contentCh <- client.ContentOnChannel{
 					Content: content,
 					Err:     nil,
@@ -395,6 +745,8 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan client.ContentOnChannel
 			content.Size = object.Stat.Size
 			content.Time = object.Stat.LastModified
 			content.Type = os.FileMode(0664)
+			content.Tags = c.listedTags(b, object.Stat.Key)
+			content.StorageClass = object.Stat.StorageClass
 			contentCh <- client.ContentOnChannel{
 				Content: content,
 				Err:     nil,
@@ -20,13 +20,19 @@ package s3v4
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/client/s3test"
+	"github.com/minio/minio/pkg/probe"
 
 	. "gopkg.in/check.v1"
 )
@@ -196,3 +202,655 @@ func (s *MySuite) TestObjectOperations(c *C) {
 		c.Assert(buffer.Bytes(), DeepEquals, object.data)
 	}
 }
+
+func newServerClient(c *C, server *s3test.Server, path string) client.Client {
+	conf := new(client.Config)
+	conf.HostURL = server.URL + path
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	return s3c
+}
+
+func (s *MySuite) TestServerBucketOperations(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+
+	s3c := newServerClient(c, server, "/bucket")
+	c.Assert(s3c.MakeBucket(), IsNil)
+	c.Assert(s3c.MakeBucket(), Not(IsNil))
+
+	for _, acl := range []string{"public-read", "public-read-write", "authenticated-read", "private"} {
+		c.Assert(s3c.SetBucketACL(acl), IsNil)
+		bucketACL, err := s3c.GetBucketACL()
+		c.Assert(err, IsNil)
+		c.Assert(bucketACL, Equals, acl)
+	}
+
+	var buckets []string
+	for content := range newServerClient(c, server, "/").List(false) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(content.Content.Type.IsDir(), Equals, true)
+		buckets = append(buckets, content.Content.Name)
+	}
+	c.Assert(buckets, DeepEquals, []string{"bucket"})
+
+	// signatures with a wrong secret are rejected
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/newbucket"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = "WRONGSECRET"
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	c.Assert(s3c.MakeBucket(), Not(IsNil))
+	_, ok := server.BucketACL("newbucket")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestServerListObjects(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+
+	server.MakeBucket("bucket", "private")
+	for _, object := range []string{"a/1", "a/2", "b", "c/d/e"} {
+		c.Assert(server.PutObject("bucket", object, []byte(object)), Equals, true)
+	}
+
+	var names []string
+	for content := range newServerClient(c, server, "/bucket").List(false) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"b", "a/", "c/"})
+
+	names = nil
+	for content := range newServerClient(c, server, "/bucket/").List(true) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, content.Content.Name)
+	}
+	c.Assert(names, DeepEquals, []string{"a/1", "a/2", "b", "c/d/e"})
+}
+
+func (s *MySuite) TestServerObjectOperations(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	// 6MB goes through a multipart upload
+	data := bytes.Repeat([]byte("0123456789abcdef"), 6*1024*1024/16)
+	s3c := newServerClient(c, server, "/bucket/object")
+	c.Assert(s3c.PutObject(int64(len(data)), bytes.NewReader(data)), IsNil)
+
+	content, err := s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len(data)))
+
+	reader, size, err := s3c.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(len(data)))
+	received, e := ioutil.ReadAll(reader)
+	c.Assert(e, IsNil)
+	c.Assert(bytes.Equal(received, data), Equals, true)
+
+	// anonymous requests are only allowed on public buckets
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	anonymous, err := New(conf)
+	c.Assert(err, IsNil)
+	_, err = anonymous.Stat()
+	c.Assert(err, Not(IsNil))
+	c.Assert(newServerClient(c, server, "/bucket").SetBucketACL("public-read"), IsNil)
+	_, err = anonymous.Stat()
+	c.Assert(err, IsNil)
+}
+
+func (s *MySuite) TestServerObjectACL(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket/object")
+	acl, err := s3c.GetObjectACL()
+	c.Assert(err, IsNil)
+	c.Assert(acl, Equals, "private")
+
+	resp, e := http.Get(server.URL + "/bucket/object")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	for _, acl := range []string{"authenticated-read", "public-read-write", "public-read"} {
+		c.Assert(s3c.SetObjectACL(acl), IsNil)
+		objectACL, err := s3c.GetObjectACL()
+		c.Assert(err, IsNil)
+		c.Assert(objectACL, Equals, acl)
+		serverACL, ok := server.ObjectACL("bucket", "object")
+		c.Assert(ok, Equals, true)
+		c.Assert(serverACL, Equals, acl)
+	}
+
+	// public-read objects are readable without credentials
+	resp, e = http.Get(server.URL + "/bucket/object")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+
+	c.Assert(s3c.SetObjectACL("invalid"), Not(IsNil))
+	c.Assert(newServerClient(c, server, "/bucket/missing").SetObjectACL("private"), Not(IsNil))
+	_, err = newServerClient(c, server, "/bucket").GetObjectACL()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerShare(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("Hello, World")), Equals, true)
+
+	presignedURL, err := newServerClient(c, server, "/bucket/object").ShareDownload(time.Hour, nil)
+	c.Assert(err, IsNil)
+	resp, e := http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	received, e := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.Assert(e, IsNil)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(string(received), Equals, "Hello, World")
+
+	// response header overrides are signed into the url
+	reqParams := make(url.Values)
+	reqParams.Set("response-content-disposition", `attachment; filename="hello world.txt"`)
+	reqParams.Set("response-content-type", "text/plain")
+	presignedURL, err = newServerClient(c, server, "/bucket/object").ShareDownload(time.Hour, reqParams)
+	c.Assert(err, IsNil)
+	resp, e = http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Content-Disposition"), Equals, `attachment; filename="hello world.txt"`)
+	c.Assert(resp.Header.Get("Content-Type"), Equals, "text/plain")
+	resp, e = http.Get(strings.Replace(presignedURL, "text%2Fplain", "text%2Fhtml", 1))
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	formData, err := newServerClient(c, server, "/bucket/uploads/").ShareUpload(true, time.Hour, "text/plain")
+	c.Assert(err, IsNil)
+	formData["key"] = formData["key"] + "file"
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e := writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// a key outside of the policy prefix is refused
+	formData["key"] = "elsewhere"
+	body.Reset()
+	writer = multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e = writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+}
+
+func (s *MySuite) TestServerSharePut(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	presignedURL, err := newServerClient(c, server, "/bucket/uploads/file").ShareUploadURL(time.Hour, "")
+	c.Assert(err, IsNil)
+	req, e := http.NewRequest("PUT", presignedURL, bytes.NewReader([]byte("uploaded")))
+	c.Assert(e, IsNil)
+	resp, e := http.DefaultClient.Do(req)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// the presigned URL is bound to its object
+	req, e = http.NewRequest("PUT", strings.Replace(presignedURL, "/uploads/file", "/uploads/other", 1), bytes.NewReader([]byte("uploaded")))
+	c.Assert(e, IsNil)
+	resp, e = http.DefaultClient.Do(req)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	_, err = newServerClient(c, server, "/bucket/uploads/file").ShareUploadURL(8*24*time.Hour, "")
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerSessionToken(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	server.SessionToken = "TOKEN"
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	// requests without the session token are refused
+	_, err := newServerClient(c, server, "/bucket/object").Stat()
+	c.Assert(err, Not(IsNil))
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	conf.SessionToken = "TOKEN"
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+	// minio-go requests are signed again with the token
+	c.Assert(s3c.PutObject(int64(len("Hello, World")), strings.NewReader("Hello, World")), IsNil)
+	content, err := s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len("Hello, World")))
+	_, err = s3c.GetObjectACL()
+	c.Assert(err, IsNil)
+
+	presignedURL, err := s3c.ShareDownload(time.Hour, nil)
+	c.Assert(err, IsNil)
+	resp, e := http.Get(presignedURL)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	resp, e = http.Get(strings.Replace(presignedURL, "TOKEN", "OTHER", 1))
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Not(Equals), http.StatusOK)
+
+	conf.HostURL = server.URL + "/bucket/uploads/"
+	uploads, err := New(conf)
+	c.Assert(err, IsNil)
+	formData, err := uploads.ShareUpload(true, time.Hour, "")
+	c.Assert(err, IsNil)
+	c.Assert(formData["x-amz-security-token"], Equals, "TOKEN")
+	formData["key"] = formData["key"] + "file"
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range formData {
+		c.Assert(writer.WriteField(k, v), IsNil)
+	}
+	file, e := writer.CreateFormFile("file", "file")
+	c.Assert(e, IsNil)
+	file.Write([]byte("uploaded"))
+	c.Assert(writer.Close(), IsNil)
+	resp, e = http.Post(server.URL+"/bucket", writer.FormDataContentType(), &body)
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+	uploaded, ok := server.GetObject("bucket", "uploads/file")
+	c.Assert(ok, Equals, true)
+	c.Assert(string(uploaded), Equals, "uploaded")
+
+	// renewed credentials are picked up before every request
+	var refreshed int
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.SessionToken = "EXPIRED"
+	conf.Refresh = func() (client.Credentials, *probe.Error) {
+		refreshed++
+		return client.Credentials{AccessKeyID: "ACCESSKEY", SecretAccessKey: "SECRETKEY", SessionToken: "TOKEN"}, nil
+	}
+	s3c, err = New(conf)
+	c.Assert(err, IsNil)
+	_, err = s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(refreshed > 0, Equals, true)
+	previous := refreshed
+	_, err = s3c.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(refreshed > previous, Equals, true)
+}
+
+func (s *MySuite) TestServerBucketPolicy(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "downloads/file", []byte("public")), Equals, true)
+	c.Assert(server.PutObject("bucket", "private/file", []byte("private")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket")
+	policy, err := s3c.GetBucketPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, "")
+
+	policy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/downloads/*"]}]}`
+	c.Assert(s3c.SetBucketPolicy(policy), IsNil)
+	bucketPolicy, err := s3c.GetBucketPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(bucketPolicy, Equals, policy)
+	c.Assert(s3c.SetBucketPolicy("not a policy"), Not(IsNil))
+
+	// anonymous reads are only allowed on the prefix
+	resp, e := http.Get(server.URL + "/bucket/downloads/file")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	resp, e = http.Get(server.URL + "/bucket/private/file")
+	c.Assert(e, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	c.Assert(s3c.RemoveBucketPolicy(), IsNil)
+	policy, err = s3c.GetBucketPolicy()
+	c.Assert(err, IsNil)
+	c.Assert(policy, Equals, "")
+
+	_, err = newServerClient(c, server, "/bucket/object").GetBucketPolicy()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestBucketPolicyNotImplemented(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer server.Close()
+
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket"
+	conf.AccessKeyID = "ACCESSKEY"
+	conf.SecretAccessKey = "SECRETKEY"
+	s3c, err := New(conf)
+	c.Assert(err, IsNil)
+
+	_, err = s3c.GetBucketPolicy()
+	c.Assert(err, Not(IsNil))
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "GetBucketPolicy", APIType: server.URL})
+	err = s3c.SetBucketPolicy("{}")
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "SetBucketPolicy", APIType: server.URL})
+	err = s3c.RemoveBucketPolicy()
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "RemoveBucketPolicy", APIType: server.URL})
+
+	_, err = s3c.GetBucketLifecycle()
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "GetBucketLifecycle", APIType: server.URL})
+	err = s3c.SetBucketLifecycle("<LifecycleConfiguration/>")
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "SetBucketLifecycle", APIType: server.URL})
+
+	_, err = s3c.GetBucketNotification()
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "GetBucketNotification", APIType: server.URL})
+	err = s3c.SetBucketNotification("<NotificationConfiguration/>")
+	c.Assert(err.ToGoError(), DeepEquals, client.APINotImplemented{API: "SetBucketNotification", APIType: server.URL})
+}
+
+func (s *MySuite) TestServerBucketLifecycle(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "logs/file", []byte("log")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket")
+	lifecycle, err := s3c.GetBucketLifecycle()
+	c.Assert(err, IsNil)
+	c.Assert(lifecycle, Equals, "")
+
+	lifecycle = `<LifecycleConfiguration><Rule><ID>logs</ID><Prefix>logs/</Prefix><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`
+	c.Assert(s3c.SetBucketLifecycle(lifecycle), IsNil)
+	bucketLifecycle, err := s3c.GetBucketLifecycle()
+	c.Assert(err, IsNil)
+	c.Assert(bucketLifecycle, Equals, lifecycle)
+	c.Assert(s3c.SetBucketLifecycle("<LifecycleConfiguration></LifecycleConfiguration>"), Not(IsNil))
+
+	c.Assert(s3c.RemoveBucketLifecycle(), IsNil)
+	lifecycle, err = s3c.GetBucketLifecycle()
+	c.Assert(err, IsNil)
+	c.Assert(lifecycle, Equals, "")
+
+	_, err = newServerClient(c, server, "/bucket/logs/file").GetBucketLifecycle()
+	c.Assert(err, Not(IsNil))
+
+	s3c = newServerClient(c, server, "/bucket/logs/file")
+	c.Assert(s3c.Remove(), IsNil)
+	_, ok := server.GetObject("bucket", "logs/file")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestServerVersioning(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "object", []byte("unversioned")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket")
+	status, err := s3c.GetBucketVersioning()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "")
+	c.Assert(s3c.SetBucketVersioning("Disabled"), Not(IsNil))
+	c.Assert(s3c.SetBucketVersioning("Enabled"), IsNil)
+	status, err = s3c.GetBucketVersioning()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "Enabled")
+
+	objectClnt := newServerClient(c, server, "/bucket/object")
+	c.Assert(objectClnt.PutObject(int64(len("version 1")), bytes.NewReader([]byte("version 1"))), IsNil)
+	c.Assert(objectClnt.Remove(), IsNil)
+	c.Assert(server.VersionCount("bucket", "object"), Equals, 3)
+
+	var versions []*client.Content
+	for content := range s3c.ListVersions(false) {
+		c.Assert(content.Err, IsNil)
+		versions = append(versions, content.Content)
+	}
+	c.Assert(versions, HasLen, 3)
+	c.Assert(versions[0].Name, Equals, "object")
+	c.Assert(versions[0].IsDeleteMarker, Equals, true)
+	c.Assert(versions[0].IsLatest, Equals, true)
+	c.Assert(versions[2].VersionID, Equals, "null")
+
+	// older versions are still readable
+	versionClnt := newServerClient(c, server, "/bucket/object?versionId="+versions[1].VersionID)
+	content, err := versionClnt.Stat()
+	c.Assert(err, IsNil)
+	c.Assert(content.Size, Equals, int64(len("version 1")))
+	c.Assert(content.VersionID, Equals, versions[1].VersionID)
+	reader, size, err := versionClnt.GetObject(0, 0)
+	c.Assert(err, IsNil)
+	data, e := ioutil.ReadAll(reader)
+	reader.Close()
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "version 1")
+	c.Assert(size, Equals, int64(len("version 1")))
+
+	reader, _, err = newServerClient(c, server, "/bucket/object?versionId=null").GetObject(2, 9)
+	c.Assert(err, IsNil)
+	data, e = ioutil.ReadAll(reader)
+	reader.Close()
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "versioned")
+
+	_, err = newServerClient(c, server, "/bucket/object?versionId="+versions[0].VersionID).Stat()
+	c.Assert(err, Not(IsNil))
+	_, err = newServerClient(c, server, "/bucket/object?versionId=unknown").Stat()
+	c.Assert(err, Not(IsNil))
+
+	c.Assert(s3c.SetBucketVersioning("Suspended"), IsNil)
+	status, err = s3c.GetBucketVersioning()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "Suspended")
+}
+
+func (s *MySuite) TestServerObjectTags(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+	c.Assert(server.PutObject("bucket", "logs/object", []byte("Hello, World")), Equals, true)
+
+	s3c := newServerClient(c, server, "/bucket/logs/object")
+	tags, err := s3c.GetObjectTags()
+	c.Assert(err, IsNil)
+	c.Assert(tags, HasLen, 0)
+
+	c.Assert(s3c.SetObjectTags(map[string]string{"team": "storage", "retention": "30d"}), IsNil)
+	tags, err = s3c.GetObjectTags()
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"team": "storage", "retention": "30d"})
+	serverTags, ok := server.ObjectTags("bucket", "logs/object")
+	c.Assert(ok, Equals, true)
+	c.Assert(serverTags, DeepEquals, tags)
+
+	// tags are only fetched while listing when requested
+	conf := new(client.Config)
+	conf.HostURL = server.URL + "/bucket/logs/"
+	conf.AccessKeyID = server.AccessKeyID
+	conf.SecretAccessKey = server.SecretAccessKey
+	for _, listTags := range []bool{false, true} {
+		conf.ListTags = listTags
+		listClnt, err := New(conf)
+		c.Assert(err, IsNil)
+		for _, recursive := range []bool{false, true} {
+			for content := range listClnt.List(recursive) {
+				c.Assert(content.Err, IsNil)
+				if listTags {
+					c.Assert(content.Content.Tags, DeepEquals, tags)
+				} else {
+					c.Assert(content.Content.Tags, IsNil)
+				}
+			}
+		}
+	}
+
+	c.Assert(s3c.RemoveObjectTags(), IsNil)
+	tags, err = s3c.GetObjectTags()
+	c.Assert(err, IsNil)
+	c.Assert(tags, HasLen, 0)
+
+	tooMany := make(map[string]string)
+	for i := 0; i < 11; i++ {
+		tooMany["key"+strconv.Itoa(i)] = "value"
+	}
+	c.Assert(s3c.SetObjectTags(tooMany), Not(IsNil))
+	c.Assert(newServerClient(c, server, "/bucket/missing").SetObjectTags(map[string]string{"k": "v"}), Not(IsNil))
+	_, err = newServerClient(c, server, "/bucket").GetObjectTags()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerBucketNotification(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	s3c := newServerClient(c, server, "/bucket")
+	notification, err := s3c.GetBucketNotification()
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(notification, "QueueConfiguration"), Equals, false)
+
+	notification = `<NotificationConfiguration><QueueConfiguration><Id>photos</Id><Queue>arn:aws:sqs:us-east-1:123456789012:photos</Queue><Event>s3:ObjectCreated:*</Event><Filter><S3Key><FilterRule><Name>prefix</Name><Value>photos/</Value></FilterRule><FilterRule><Name>suffix</Name><Value>.jpg</Value></FilterRule></S3Key></Filter></QueueConfiguration></NotificationConfiguration>`
+	c.Assert(s3c.SetBucketNotification(notification), IsNil)
+	bucketNotification, err := s3c.GetBucketNotification()
+	c.Assert(err, IsNil)
+	c.Assert(bucketNotification, Equals, notification)
+
+	for _, invalid := range []string{
+		// queue ARN used for a topic
+		`<NotificationConfiguration><TopicConfiguration><Topic>arn:aws:sqs:us-east-1:123456789012:photos</Topic><Event>s3:ObjectCreated:*</Event></TopicConfiguration></NotificationConfiguration>`,
+		// unknown event type
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:aws:sqs:us-east-1:123456789012:photos</Queue><Event>s3:ObjectCreated:Rename</Event></QueueConfiguration></NotificationConfiguration>`,
+		// overlapping prefixes for the same event type
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:aws:sqs:us-east-1:123456789012:a</Queue><Event>s3:ObjectCreated:*</Event></QueueConfiguration><QueueConfiguration><Queue>arn:aws:sqs:us-east-1:123456789012:b</Queue><Event>s3:ObjectCreated:Put</Event></QueueConfiguration></NotificationConfiguration>`,
+		// repeated filter rule
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:aws:sqs:us-east-1:123456789012:a</Queue><Event>s3:ObjectCreated:*</Event><Filter><S3Key><FilterRule><Name>prefix</Name><Value>a</Value></FilterRule><FilterRule><Name>prefix</Name><Value>b</Value></FilterRule></S3Key></Filter></QueueConfiguration></NotificationConfiguration>`,
+	} {
+		c.Assert(s3c.SetBucketNotification(invalid), Not(IsNil))
+	}
+
+	c.Assert(s3c.SetBucketNotification("<NotificationConfiguration></NotificationConfiguration>"), IsNil)
+	notification, err = s3c.GetBucketNotification()
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(notification, "QueueConfiguration"), Equals, false)
+
+	_, err = newServerClient(c, server, "/bucket/object").GetBucketNotification()
+	c.Assert(err, Not(IsNil))
+}
+
+func (s *MySuite) TestServerStorageClass(c *C) {
+	server := s3test.NewServer("ACCESSKEY", "SECRETKEY")
+	defer server.Close()
+	server.MakeBucket("bucket", "private")
+
+	partSize := minimumPartSize
+	minimumPartSize = 1024
+	defer func() { minimumPartSize = partSize }()
+
+	newClient := func(path, storageClass string) client.Client {
+		conf := new(client.Config)
+		conf.HostURL = server.URL + path
+		conf.AccessKeyID = server.AccessKeyID
+		conf.SecretAccessKey = server.SecretAccessKey
+		conf.StorageClass = storageClass
+		clnt, err := New(conf)
+		c.Assert(err, IsNil)
+		return clnt
+	}
+
+	// single part, multipart and multipart of unknown size
+	data := bytes.Repeat([]byte("0123456789"), 250)
+	for _, tc := range []struct {
+		object string
+		size   int64
+		data   []byte
+	}{
+		{"small", 10, data[:10]},
+		{"large", int64(len(data)), data},
+		{"stream", 0, data},
+		{"empty", 0, nil},
+	} {
+		clnt := newClient("/bucket/"+tc.object, "REDUCED_REDUNDANCY")
+		c.Assert(clnt.PutObject(tc.size, bytes.NewReader(tc.data)), IsNil)
+		storedData, ok := server.GetObject("bucket", tc.object)
+		c.Assert(ok, Equals, true)
+		c.Assert(string(storedData), Equals, string(tc.data))
+		storageClass, ok := server.ObjectStorageClass("bucket", tc.object)
+		c.Assert(ok, Equals, true)
+		c.Assert(storageClass, Equals, "REDUCED_REDUNDANCY")
+
+		content, err := clnt.Stat()
+		c.Assert(err, IsNil)
+		c.Assert(content.StorageClass, Equals, "REDUCED_REDUNDANCY")
+	}
+
+	// short reads abort the upload
+	clnt := newClient("/bucket/short", "STANDARD_IA")
+	c.Assert(clnt.PutObject(int64(len(data)+1), bytes.NewReader(data)), Not(IsNil))
+	_, ok := server.GetObject("bucket", "short")
+	c.Assert(ok, Equals, false)
+
+	clnt = newClient("/bucket/invalid", "INVALID")
+	c.Assert(clnt.PutObject(10, bytes.NewReader(data[:10])), Not(IsNil))
+
+	// uploads without a storage class are STANDARD
+	clnt = newClient("/bucket/standard", "")
+	c.Assert(clnt.PutObject(10, bytes.NewReader(data[:10])), IsNil)
+	storageClass, _ := server.ObjectStorageClass("bucket", "standard")
+	c.Assert(storageClass, Equals, "STANDARD")
+
+	for _, recursive := range []bool{false, true} {
+		for content := range newClient("/bucket/", "").List(recursive) {
+			c.Assert(content.Err, IsNil)
+			switch content.Content.Name {
+			case "standard":
+				c.Assert(content.Content.StorageClass, Equals, "STANDARD")
+			default:
+				c.Assert(content.Content.StorageClass, Equals, "REDUCED_REDUNDANCY")
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3v4
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/probe"
+)
+
+const storageClassHeader = "X-Amz-Storage-Class"
+
+// parts of a multipart upload other than the last are at least minimumPartSize, at most maxParts are uploaded
+var minimumPartSize int64 = 1024 * 1024 * 5
+
+const maxParts = 10000
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+// storageClassListing - keys and storage classes of a bucket listing
+type storageClassListing struct {
+	Contents []struct {
+		Key          string
+		StorageClass string
+	}
+}
+
+// partSize - part size for an object of size, unknown sizes are zero
+func partSize(size int64) int64 {
+	if size/(maxParts-1) > minimumPartSize {
+		return size / (maxParts - 1)
+	}
+	return minimumPartSize
+}
+
+// listedStorageClass - storage class of an object as reported by listing it, empty if unknown.
+// Only listings carry the storage class, S3 omits it from HEAD replies for STANDARD objects.
+func (c *s3Client) listedStorageClass(bucket, object string) string {
+	req, err := c.newRequest("GET", bucket, "", url.Values{"prefix": {object}, "max-keys": {"1"}}, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	listing := storageClassListing{}
+	if e := xml.NewDecoder(resp.Body).Decode(&listing); e != nil || len(listing.Contents) == 0 {
+		return ""
+	}
+	if listing.Contents[0].Key != object {
+		return ""
+	}
+	return listing.Contents[0].StorageClass
+}
+
+// putObjectStorageClass - upload an object with the configured storage class, minio-go can not send
+// the x-amz-storage-class header. Objects smaller than a part are sent with a single PUT, size 0 reads
+// until EOF.
+func (c *s3Client) putObjectStorageClass(bucket, object string, size int64, data io.Reader) *probe.Error {
+	if size > 0 {
+		data = io.LimitReader(data, size)
+	}
+	buf := make([]byte, partSize(size))
+	n, e := io.ReadFull(data, buf)
+	switch e {
+	case nil:
+		return c.putMultipartStorageClass(bucket, object, size, buf, data).Trace(bucket, object)
+	case io.EOF, io.ErrUnexpectedEOF:
+		if size > 0 && int64(n) != size {
+			return probe.NewError(io.ErrUnexpectedEOF)
+		}
+	default:
+		return probe.NewError(e)
+	}
+	req, err := c.newRequest("PUT", bucket, object, nil, buf[:n])
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	md5Sum := md5.Sum(buf[:n])
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	req.Header.Set(storageClassHeader, c.config.StorageClass)
+	resp, err := c.do(req)
+	if err != nil {
+		if errorCode(err) == "MethodNotAllowed" {
+			return probe.NewError(client.ObjectAlreadyExists{Object: object})
+		}
+		return err.Trace(bucket, object)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// putMultipartStorageClass - multipart upload starting with the full part in buf, the remaining parts are
+// read from data. Incomplete uploads are aborted.
+func (c *s3Client) putMultipartStorageClass(bucket, object string, size int64, buf []byte, data io.Reader) *probe.Error {
+	req, err := c.newRequest("POST", bucket, object, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(storageClassHeader, c.config.StorageClass)
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	defer resp.Body.Close()
+	initiated := initiateMultipartUploadResult{}
+	if e := xml.NewDecoder(resp.Body).Decode(&initiated); e != nil {
+		return probe.NewError(e)
+	}
+	if err := c.uploadParts(bucket, object, initiated.UploadID, size, buf, data); err != nil {
+		// best effort, servers eventually remove abandoned uploads on their own
+		if req, aerr := c.newRequest("DELETE", bucket, object, url.Values{"uploadId": {initiated.UploadID}}, nil); aerr == nil {
+			if resp, aerr := c.do(req); aerr == nil {
+				resp.Body.Close()
+			}
+		}
+		return err.Trace(bucket, object)
+	}
+	return nil
+}
+
+// uploadParts - upload all parts and complete the multipart upload
+func (c *s3Client) uploadParts(bucket, object, uploadID string, size int64, buf []byte, data io.Reader) *probe.Error {
+	complete := completeMultipartUpload{}
+	var total int64
+	n := len(buf)
+	for partNumber := 1; n > 0; partNumber++ {
+		if partNumber > maxParts {
+			return probe.NewError(minio.ErrorResponse{
+				Code:     "EntityTooLarge",
+				Message:  "Upload exceeds " + strconv.Itoa(maxParts) + " parts.",
+				Resource: "/" + bucket + "/" + object,
+			})
+		}
+		query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+		req, err := c.newRequest("PUT", bucket, object, query, buf[:n])
+		if err != nil {
+			return err.Trace(bucket, object)
+		}
+		md5Sum := md5.Sum(buf[:n])
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+		resp, err := c.do(req)
+		if err != nil {
+			return err.Trace(bucket, object)
+		}
+		resp.Body.Close()
+		complete.Parts = append(complete.Parts, completePart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+		total += int64(n)
+
+		var e error
+		n, e = io.ReadFull(data, buf)
+		if e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+			return probe.NewError(e)
+		}
+	}
+	if size > 0 && total != size {
+		return probe.NewError(io.ErrUnexpectedEOF)
+	}
+	body, e := xml.Marshal(complete)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req, err := c.newRequest("POST", bucket, object, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	defer resp.Body.Close()
+	// S3 may report a failed completion in the body of a 200 OK reply
+	reply, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	errResponse := minio.ErrorResponse{}
+	if xml.Unmarshal(reply, &errResponse) == nil && errResponse.Code != "" {
+		return probe.NewError(errResponse)
+	}
+	return nil
+}
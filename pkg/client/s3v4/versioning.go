@@ -0,0 +1,258 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3v4
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// versionID - a specific object version is selected with a '?versionId=' suffix on the URL
+func versionID(hostURL string) string {
+	i := strings.Index(hostURL, "?")
+	if i < 0 {
+		return ""
+	}
+	query, err := url.ParseQuery(hostURL[i+1:])
+	if err != nil {
+		return ""
+	}
+	return query.Get("versionId")
+}
+
+// versioningConfiguration - bucket versioning status, empty if versioning was never enabled
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+// GetBucketVersioning get the bucket versioning status, empty if versioning was never enabled
+func (c *s3Client) GetBucketVersioning() (string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return "", probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("GET", bucket, "", url.Values{"versioning": {""}}, nil)
+	if err != nil {
+		return "", err.Trace(bucket)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: c.apiType()})
+		}
+		return "", err.Trace(bucket)
+	}
+	defer resp.Body.Close()
+	// servers unaware of the versioning sub-resource reply with a bucket listing
+	config := versioningConfiguration{}
+	if e := xml.NewDecoder(resp.Body).Decode(&config); e != nil {
+		return "", probe.NewError(client.APINotImplemented{API: "GetBucketVersioning", APIType: c.apiType()})
+	}
+	return config.Status, nil
+}
+
+// SetBucketVersioning enable or suspend versioning on a bucket, versioning can not be disabled once enabled
+func (c *s3Client) SetBucketVersioning(status string) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object != "" || bucket == "" {
+		return probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	if status != "Enabled" && status != "Suspended" {
+		return probe.NewError(client.InvalidVersioningStatus{Status: status})
+	}
+	body, e := xml.Marshal(versioningConfiguration{Status: status})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req, err := c.newRequest("PUT", bucket, "", url.Values{"versioning": {""}}, body)
+	if err != nil {
+		return err.Trace(bucket)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		if isNotImplemented(err) {
+			return probe.NewError(client.APINotImplemented{API: "SetBucketVersioning", APIType: c.apiType()})
+		}
+		return err.Trace(bucket, status)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// getObjectVersion - get a specific version of an object, length 0 reads till the end
+func (c *s3Client) getObjectVersion(offset, length int64) (io.ReadCloser, int64, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	req, err := c.newRequest("GET", bucket, object, url.Values{"versionId": {c.versionID}}, nil)
+	if err != nil {
+		return nil, 0, err.Trace(bucket, object)
+	}
+	switch {
+	case length > 0:
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	case offset > 0:
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, length, err.Trace(bucket, object, c.versionID)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// statObjectVersion - metadata of a specific version of an object
+func (c *s3Client) statObjectVersion() (*client.Content, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return nil, probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})
+	}
+	req, err := c.newRequest("HEAD", bucket, object, url.Values{"versionId": {c.versionID}}, nil)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		// HEAD on a delete marker is not allowed
+		if errorCode(err) == "MethodNotAllowed" {
+			return nil, probe.NewError(client.ObjectNotFound{Bucket: bucket, Object: object})
+		}
+		return nil, err.Trace(bucket, object, c.versionID)
+	}
+	resp.Body.Close()
+	content := new(client.Content)
+	content.Name = object
+	content.Size = resp.ContentLength
+	content.Time, _ = time.Parse(http.TimeFormat, resp.Header.Get("Last-Modified"))
+	content.Type = os.FileMode(0664)
+	content.VersionID = c.versionID
+	return content, nil
+}
+
+// listVersionsResult - container for ListObjectVersions response, versions and delete markers
+// are interleaved newest first for every key, they are collected in document order
+type listVersionsResult struct {
+	Name                string
+	Prefix              string
+	KeyMarker           string
+	VersionIDMarker     string `xml:"VersionIdMarker"`
+	NextKeyMarker       string
+	NextVersionIDMarker string `xml:"NextVersionIdMarker"`
+	MaxKeys             int
+	Delimiter           string
+	EncodingType        string
+	IsTruncated         bool
+	CommonPrefixes      []struct {
+		Prefix string
+	}
+	Entries []versionEntry `xml:",any"`
+}
+
+// versionEntry - either a Version or a DeleteMarker element
+type versionEntry struct {
+	XMLName      xml.Name
+	Key          string
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified time.Time
+	Size         int64
+	StorageClass string
+}
+
+// ListVersions - list all versions and delete markers at delimited path, if not recursive
+func (c *s3Client) ListVersions(recursive bool) <-chan client.ContentOnChannel {
+	contentCh := make(chan client.ContentOnChannel)
+	go c.listVersionsInRoutine(recursive, contentCh)
+	return contentCh
+}
+
+func (c *s3Client) listVersionsInRoutine(recursive bool, contentCh chan client.ContentOnChannel) {
+	defer close(contentCh)
+	bucket, prefix := c.url2BucketAndObject()
+	if bucket == "" {
+		contentCh <- client.ContentOnChannel{Err: probe.NewError(client.InvalidQueryURL{URL: c.hostURL.String()})}
+		return
+	}
+	separator := string(c.hostURL.Separator)
+	// names are relative to the delimited prefix, like List does
+	stripPrefix := prefix[:strings.LastIndex(prefix, separator)+1]
+	query := url.Values{"versions": {""}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if !recursive {
+		query.Set("delimiter", separator)
+	}
+	for {
+		req, err := c.newRequest("GET", bucket, "", query, nil)
+		if err != nil {
+			contentCh <- client.ContentOnChannel{Err: err.Trace(bucket)}
+			return
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			if isNotImplemented(err) {
+				err = probe.NewError(client.APINotImplemented{API: "ListVersions", APIType: c.apiType()})
+			}
+			contentCh <- client.ContentOnChannel{Err: err.Trace(bucket)}
+			return
+		}
+		result := listVersionsResult{}
+		e := xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if e != nil {
+			contentCh <- client.ContentOnChannel{Err: probe.NewError(e)}
+			return
+		}
+		for _, prefix := range result.CommonPrefixes {
+			content := new(client.Content)
+			content.Name = strings.TrimPrefix(prefix.Prefix, stripPrefix)
+			content.Time = time.Now()
+			content.Type = os.ModeDir
+			contentCh <- client.ContentOnChannel{Content: content}
+		}
+		for _, version := range result.Entries {
+			if version.XMLName.Local != "Version" && version.XMLName.Local != "DeleteMarker" {
+				continue
+			}
+			content := new(client.Content)
+			content.Name = strings.TrimPrefix(version.Key, stripPrefix)
+			content.Time = version.LastModified
+			content.Size = version.Size
+			content.Type = os.FileMode(0664)
+			content.VersionID = version.VersionID
+			content.IsLatest = version.IsLatest
+			content.IsDeleteMarker = version.XMLName.Local == "DeleteMarker"
+			content.StorageClass = version.StorageClass
+			contentCh <- client.ContentOnChannel{Content: content}
+		}
+		if !result.IsTruncated {
+			return
+		}
+		query.Set("key-marker", result.NextKeyMarker)
+		query.Set("version-id-marker", result.NextVersionIDMarker)
+	}
+}
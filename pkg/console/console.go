@@ -41,6 +41,9 @@ var IsExited = false
 // IsError sets this boolean value if Error is called when IsTesting is enabled
 var IsError = false
 
+// ExitStatus sets this value to the status passed to Exit when IsTesting is enabled
+var ExitStatus = 0
+
 // Palette default map
 var Palette = map[string]*color.Color{}
 
@@ -87,6 +90,14 @@ var (
 		}()
 	}
 
+	// Exit terminates with the given status, without printing a message
+	Exit = func(status int) {
+		if !IsTesting {
+			os.Exit(status)
+		}
+		ExitStatus = status
+	}
+
 	// Fatal print a error message and exit
 	Fatal = func(data ...interface{}) {
 		consolePrint("Fatal", Palette["Fatal"], data...)
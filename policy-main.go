@@ -0,0 +1,285 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Manage bucket policies.
+var policyCmd = cli.Command{
+	Name:   "policy",
+	Usage:  "Set, get, remove or list anonymous access policies on a bucket or prefix.",
+	Action: mainPolicy,
+	CustomHelpTemplate: `Name:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set PERMISSION TARGET [TARGET ...]
+   mc {{.Name}} get TARGET [TARGET ...]
+   mc {{.Name}} remove TARGET [TARGET ...]
+   mc {{.Name}} list TARGET [TARGET ...]
+
+   PERMISSION = none | readonly | writeonly | readwrite {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}
+
+EXAMPLES:
+
+   1. Allow anonymous downloads from prefix "downloads/" on Amazon S3 cloud storage.
+      $ mc {{.Name}} set readonly https://s3.amazonaws.com/shared/downloads/
+
+   2. Allow anonymous uploads to prefix "incoming/" on Minio cloud storage.
+      $ mc {{.Name}} set writeonly https://play.minio.io:9000/dropbox/incoming/
+
+   3. Get the policy on prefix "downloads/".
+      $ mc {{.Name}} get https://s3.amazonaws.com/shared/downloads/
+
+   4. Remove the policy on prefix "incoming/".
+      $ mc {{.Name}} remove https://play.minio.io:9000/dropbox/incoming/
+
+   5. List the policies of every prefix in a bucket.
+      $ mc {{.Name}} list https://s3.amazonaws.com/shared
+`,
+}
+
+// PolicyMessage is container for policy command on bucket success and failure messages
+type PolicyMessage struct {
+	Operation string      `json:"operation"`
+	Status    string      `json:"status"`
+	URL       string      `json:"url"`
+	Perms     policyPerms `json:"permission"`
+}
+
+// String colorized policy message
+func (s PolicyMessage) String() string {
+	switch {
+	case s.Status == "dry-run" && s.Operation == "set":
+		return console.Colorize("Policy", "Policy ‘"+string(s.Perms)+"’ would be set for ‘"+s.URL+"’ (dry run)")
+	case s.Status == "dry-run" && s.Operation == "remove":
+		return console.Colorize("Policy", "Policy would be removed for ‘"+s.URL+"’ (dry run)")
+	case s.Operation == "set":
+		return console.Colorize("Policy", "Policy ‘"+string(s.Perms)+"’ set successfully for ‘"+s.URL+"’")
+	case s.Operation == "remove":
+		return console.Colorize("Policy", "Policy removed successfully for ‘"+s.URL+"’")
+	case s.Operation == "get":
+		return console.Colorize("Policy", "Policy for ‘"+s.URL+"’ is ‘"+string(s.Perms)+"’")
+	case s.Operation == "list":
+		return console.Colorize("Policy", s.URL+" => "+string(s.Perms))
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified policy message
+func (s PolicyMessage) JSON() string {
+	policyJSONBytes, err := json.Marshal(s)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(policyJSONBytes)
+}
+
+func checkPolicySyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code
+	}
+	if len(ctx.Args()) < 2 {
+		cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code
+	}
+	targets := ctx.Args().Tail()
+	switch ctx.Args().Get(0) {
+	case "set":
+		if len(ctx.Args().Tail()) < 2 {
+			cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code
+		}
+		perms := policyPerms(ctx.Args().Tail().Get(0))
+		if !perms.isValidPolicyPERM() {
+			fatalIf(errDummy().Trace(),
+				"Unrecognized permission ‘"+string(perms)+"’. Allowed values are [none, readonly, writeonly, readwrite].")
+		}
+		targets = ctx.Args().Tail().Tail()
+	case "get", "remove", "list":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "policy", 1) // last argument is exit code
+	}
+	for _, arg := range targets {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+func setPolicyPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Policy": color.New(color.FgGreen, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Policy": color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainPolicy(ctx *cli.Context) {
+	checkPolicySyntax(ctx)
+
+	setPolicyPalette(ctx.GlobalString("colors"))
+
+	config := mustGetMcConfig()
+
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	switch ctx.Args().Get(0) {
+	case "set":
+		perms := policyPerms(ctx.Args().Tail().Get(0))
+		for _, arg := range ctx.Args().Tail().Tail() {
+			targetURL := getAliasURL(arg, config.Aliases)
+			fatalIf(doSetPolicy(targetURL, perms).Trace(targetURL, string(perms)), "Unable to set policy ‘"+string(perms)+"’ for ‘"+targetURL+"’.")
+
+			Prints("%s\n", PolicyMessage{
+				Operation: "set",
+				Status:    status,
+				URL:       targetURL,
+				Perms:     perms,
+			})
+		}
+	case "remove":
+		for _, arg := range ctx.Args().Tail() {
+			targetURL := getAliasURL(arg, config.Aliases)
+			fatalIf(doSetPolicy(targetURL, policyNone).Trace(targetURL), "Unable to remove policy for ‘"+targetURL+"’.")
+
+			Prints("%s\n", PolicyMessage{
+				Operation: "remove",
+				Status:    status,
+				URL:       targetURL,
+				Perms:     policyNone,
+			})
+		}
+	case "get":
+		for _, arg := range ctx.Args().Tail() {
+			targetURL := getAliasURL(arg, config.Aliases)
+			perms, err := doGetPolicy(targetURL)
+			fatalIf(err.Trace(targetURL), "Unable to get policy for ‘"+targetURL+"’.")
+
+			Prints("%s\n", PolicyMessage{
+				Operation: "get",
+				Status:    "success",
+				URL:       targetURL,
+				Perms:     perms,
+			})
+		}
+	case "list":
+		for _, arg := range ctx.Args().Tail() {
+			targetURL := getAliasURL(arg, config.Aliases)
+			policies, err := doListPolicies(targetURL)
+			fatalIf(err.Trace(targetURL), "Unable to list policies for ‘"+targetURL+"’.")
+
+			bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+			var prefixes []string
+			for prefix := range policies {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			for _, prefix := range prefixes {
+				Prints("%s\n", PolicyMessage{
+					Operation: "list",
+					Status:    "success",
+					URL:       strings.TrimSuffix(bucketURL, "/") + "/" + prefix + "*",
+					Perms:     policies[prefix],
+				})
+			}
+		}
+	}
+}
+
+// getBucketPolicy - fetch and parse the policy of the bucket targetURL belongs to
+func getBucketPolicy(targetURL string) (policy bucketPolicy, bucket, prefix string, err *probe.Error) {
+	bucketURL, bucket, prefix := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return bucketPolicy{}, "", "", err.Trace(bucketURL)
+	}
+	policyStr, err := clnt.GetBucketPolicy()
+	if err != nil {
+		return bucketPolicy{}, "", "", err.Trace(bucketURL)
+	}
+	policy, err = parseBucketPolicy(policyStr)
+	if err != nil {
+		return bucketPolicy{}, "", "", err.Trace(bucketURL)
+	}
+	return policy, bucket, prefix, nil
+}
+
+// doSetPolicy - set perms on the prefix of targetURL, preserving the rest of the bucket policy
+func doSetPolicy(targetURL string, perms policyPerms) *probe.Error {
+	policy, bucket, prefix, err := getBucketPolicy(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if globalDryRunFlag {
+		return nil
+	}
+	bucketURL, _, _ := url2BucketAndPrefix(targetURL)
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return err.Trace(bucketURL)
+	}
+	newPolicy := policy.setPrefixPolicy(bucket, prefix, perms)
+	if len(newPolicy.Statement) == 0 {
+		return clnt.RemoveBucketPolicy().Trace(bucketURL)
+	}
+	if err = clnt.SetBucketPolicy(newPolicy.String()); err != nil {
+		return err.Trace(bucketURL, string(perms))
+	}
+	return nil
+}
+
+// doGetPolicy - get perms on the prefix of targetURL
+func doGetPolicy(targetURL string) (policyPerms, *probe.Error) {
+	policy, bucket, prefix, err := getBucketPolicy(targetURL)
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	if perms, ok := policy.prefixPolicies(bucket)[prefix]; ok {
+		return perms, nil
+	}
+	return policyNone, nil
+}
+
+// doListPolicies - get perms of every prefix in the bucket of targetURL
+func doListPolicies(targetURL string) (map[string]policyPerms, *probe.Error) {
+	policy, bucket, _, err := getBucketPolicy(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	return policy.prefixPolicies(bucket), nil
+}
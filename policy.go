@@ -0,0 +1,330 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// policyPerms - anonymous access granted on a prefix by the bucket policy
+type policyPerms string
+
+// different types of policies currently supported for prefixes
+const (
+	policyNone      = policyPerms("none")
+	policyReadOnly  = policyPerms("readonly")
+	policyWriteOnly = policyPerms("writeonly")
+	policyReadWrite = policyPerms("readwrite")
+)
+
+// isValidPolicyPERM - is provided policy string supported
+func (p policyPerms) isValidPolicyPERM() bool {
+	switch p {
+	case policyNone, policyReadOnly, policyWriteOnly, policyReadWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p policyPerms) canRead() bool {
+	return p == policyReadOnly || p == policyReadWrite
+}
+
+func (p policyPerms) canWrite() bool {
+	return p == policyWriteOnly || p == policyReadWrite
+}
+
+// policy statement actions managed by mc
+var (
+	commonBucketActions = []string{"s3:GetBucketLocation"}
+	readBucketActions   = []string{"s3:ListBucket"}
+	writeBucketActions  = []string{"s3:ListBucketMultipartUploads"}
+	readObjectActions   = []string{"s3:GetObject"}
+	writeObjectActions  = []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:ListMultipartUploadParts", "s3:PutObject"}
+)
+
+const policyVersion = "2012-10-17"
+
+// policyStringSet - a policy element which is either a single string or a list of strings
+type policyStringSet []string
+
+// UnmarshalJSON accepts both "value" and ["value", ...]
+func (s *policyStringSet) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = policyStringSet{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = policyStringSet(list)
+	return nil
+}
+
+func (s policyStringSet) contains(value string) bool {
+	for _, v := range s {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// policyPrincipal - either "*" or {"AWS": ...}
+type policyPrincipal struct {
+	AWS policyStringSet `json:"AWS"`
+}
+
+// UnmarshalJSON accepts both "*" and {"AWS": ...}
+func (p *policyPrincipal) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		p.AWS = policyStringSet{single}
+		return nil
+	}
+	var principal struct {
+		AWS policyStringSet
+	}
+	if err := json.Unmarshal(data, &principal); err != nil {
+		return err
+	}
+	p.AWS = principal.AWS
+	return nil
+}
+
+// policyStatement - a single bucket policy statement
+type policyStatement struct {
+	Sid       string                                `json:"Sid,omitempty"`
+	Effect    string                                `json:"Effect"`
+	Principal policyPrincipal                       `json:"Principal"`
+	Action    policyStringSet                       `json:"Action"`
+	Resource  policyStringSet                       `json:"Resource"`
+	Condition map[string]map[string]policyStringSet `json:"Condition,omitempty"`
+}
+
+// bucketPolicy - S3 bucket policy document
+type bucketPolicy struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+// parseBucketPolicy - parse a bucket policy document, an empty document is an empty policy
+func parseBucketPolicy(data string) (bucketPolicy, *probe.Error) {
+	policy := bucketPolicy{Version: policyVersion}
+	if strings.TrimSpace(data) == "" {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		return bucketPolicy{}, probe.NewError(err)
+	}
+	return policy, nil
+}
+
+// String - policy document in JSON
+func (p bucketPolicy) String() string {
+	policyBytes, err := json.Marshal(p)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+	return string(policyBytes)
+}
+
+func bucketARN(bucket string) string {
+	return "arn:aws:s3:::" + bucket
+}
+
+func isSubset(values policyStringSet, allowed ...[]string) bool {
+	for _, v := range values {
+		found := false
+		for _, list := range allowed {
+			if policyStringSet(list).contains(v) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// isManagedStatement - statements granting anonymous access which mc generates itself,
+// everything else is preserved as is
+func isManagedStatement(st policyStatement, bucket string) bool {
+	if st.Effect != "Allow" || len(st.Principal.AWS) != 1 || st.Principal.AWS[0] != "*" {
+		return false
+	}
+	if !isSubset(st.Action, commonBucketActions, readBucketActions, writeBucketActions, readObjectActions, writeObjectActions) {
+		return false
+	}
+	for _, resource := range st.Resource {
+		if resource != bucketARN(bucket) && !(strings.HasPrefix(resource, bucketARN(bucket)+"/") && strings.HasSuffix(resource, "*")) {
+			return false
+		}
+	}
+	for operator := range st.Condition {
+		if operator != "StringLike" && operator != "StringEquals" {
+			return false
+		}
+	}
+	return true
+}
+
+// prefixPolicies - anonymous permissions for every prefix of a bucket
+func (p bucketPolicy) prefixPolicies(bucket string) map[string]policyPerms {
+	canRead := make(map[string]bool)
+	canWrite := make(map[string]bool)
+	for _, st := range p.Statement {
+		if !isManagedStatement(st, bucket) {
+			continue
+		}
+		for _, resource := range st.Resource {
+			if resource == bucketARN(bucket) {
+				continue
+			}
+			prefix := strings.TrimSuffix(strings.TrimPrefix(resource, bucketARN(bucket)+"/"), "*")
+			for _, action := range st.Action {
+				if policyStringSet(readObjectActions).contains(action) {
+					canRead[prefix] = true
+				}
+				if policyStringSet(writeObjectActions).contains(action) {
+					canWrite[prefix] = true
+				}
+			}
+		}
+	}
+	policies := make(map[string]policyPerms)
+	for prefix := range canRead {
+		policies[prefix] = policyReadOnly
+	}
+	for prefix := range canWrite {
+		if canRead[prefix] {
+			policies[prefix] = policyReadWrite
+			continue
+		}
+		policies[prefix] = policyWriteOnly
+	}
+	return policies
+}
+
+// setPrefixPolicy - returns a new policy with perms set on the prefix, all other prefixes and
+// unmanaged statements are left untouched
+func (p bucketPolicy) setPrefixPolicy(bucket, prefix string, perms policyPerms) bucketPolicy {
+	policies := p.prefixPolicies(bucket)
+	delete(policies, prefix)
+	if perms != policyNone {
+		policies[prefix] = perms
+	}
+
+	newPolicy := bucketPolicy{Version: p.Version}
+	if newPolicy.Version == "" {
+		newPolicy.Version = policyVersion
+	}
+	for _, st := range p.Statement {
+		if !isManagedStatement(st, bucket) {
+			newPolicy.Statement = append(newPolicy.Statement, st)
+		}
+	}
+	if len(policies) == 0 {
+		return newPolicy
+	}
+
+	var prefixes, readPrefixes []string
+	for prefix := range policies {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	anyRead, anyWrite, readAll := false, false, false
+	for _, prefix := range prefixes {
+		if policies[prefix].canRead() {
+			anyRead = true
+			readAll = readAll || prefix == ""
+			readPrefixes = append(readPrefixes, prefix+"*")
+		}
+		anyWrite = anyWrite || policies[prefix].canWrite()
+	}
+
+	anonymous := policyPrincipal{AWS: policyStringSet{"*"}}
+	bucketActions := append([]string{}, commonBucketActions...)
+	if anyWrite {
+		bucketActions = append(bucketActions, writeBucketActions...)
+	}
+	newPolicy.Statement = append(newPolicy.Statement, policyStatement{
+		Effect:    "Allow",
+		Principal: anonymous,
+		Action:    bucketActions,
+		Resource:  policyStringSet{bucketARN(bucket)},
+	})
+	if anyRead {
+		listStatement := policyStatement{
+			Effect:    "Allow",
+			Principal: anonymous,
+			Action:    readBucketActions,
+			Resource:  policyStringSet{bucketARN(bucket)},
+		}
+		// listing is restricted to the readable prefixes
+		if !readAll {
+			listStatement.Condition = map[string]map[string]policyStringSet{
+				"StringLike": {"s3:prefix": readPrefixes},
+			}
+		}
+		newPolicy.Statement = append(newPolicy.Statement, listStatement)
+	}
+	for _, prefix := range prefixes {
+		var actions []string
+		if policies[prefix].canRead() {
+			actions = append(actions, readObjectActions...)
+		}
+		if policies[prefix].canWrite() {
+			actions = append(actions, writeObjectActions...)
+		}
+		newPolicy.Statement = append(newPolicy.Statement, policyStatement{
+			Effect:    "Allow",
+			Principal: anonymous,
+			Action:    actions,
+			Resource:  policyStringSet{bucketARN(bucket) + "/" + prefix + "*"},
+		})
+	}
+	return newPolicy
+}
+
+// url2BucketAndPrefix - split a cloud storage URL into the URL of its bucket, the bucket name and the object prefix
+func url2BucketAndPrefix(targetURL string) (bucketURL, bucket, prefix string) {
+	u := client.NewURL(targetURL)
+	if u.Type != client.Object {
+		return targetURL, "", ""
+	}
+	separator := string(u.Separator)
+	if match, _ := filepath.Match("*.s3*.amazonaws.com", u.Host); match {
+		bucket = strings.SplitN(u.Host, ".", 2)[0]
+		prefix = strings.TrimPrefix(u.Path, separator)
+		return u.Scheme + u.SchemeSeparator + u.Host + separator, bucket, prefix
+	}
+	splits := strings.SplitN(strings.TrimPrefix(u.Path, separator), separator, 2)
+	bucket = splits[0]
+	if len(splits) == 2 {
+		prefix = splits[1]
+	}
+	return u.Scheme + u.SchemeSeparator + u.Host + separator + bucket, bucket, prefix
+}
@@ -0,0 +1,145 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestBucketPolicyPrefixes(c *C) {
+	policy, perr := parseBucketPolicy("")
+	c.Assert(perr, IsNil)
+	c.Assert(policy.prefixPolicies("bucket"), HasLen, 0)
+
+	policy = policy.setPrefixPolicy("bucket", "downloads/", policyReadOnly)
+	policy = policy.setPrefixPolicy("bucket", "uploads/", policyWriteOnly)
+	policy = policy.setPrefixPolicy("bucket", "", policyReadWrite)
+
+	// round trip through JSON
+	policy, perr = parseBucketPolicy(policy.String())
+	c.Assert(perr, IsNil)
+	c.Assert(policy.prefixPolicies("bucket"), DeepEquals, map[string]policyPerms{
+		"downloads/": policyReadOnly,
+		"uploads/":   policyWriteOnly,
+		"":           policyReadWrite,
+	})
+
+	policy = policy.setPrefixPolicy("bucket", "", policyNone)
+	policy = policy.setPrefixPolicy("bucket", "uploads/", policyNone)
+	c.Assert(policy.prefixPolicies("bucket"), DeepEquals, map[string]policyPerms{"downloads/": policyReadOnly})
+
+	// statements not generated by mc are preserved
+	policy, perr = parseBucketPolicy(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111122223333:root"},"Action":"s3:*","Resource":"arn:aws:s3:::bucket/*"}]}`)
+	c.Assert(perr, IsNil)
+	c.Assert(policy.prefixPolicies("bucket"), HasLen, 0)
+	policy = policy.setPrefixPolicy("bucket", "downloads/", policyReadOnly)
+	policy = policy.setPrefixPolicy("bucket", "downloads/", policyNone)
+	c.Assert(policy.Statement, HasLen, 1)
+	c.Assert(policy.Statement[0].Principal.AWS, DeepEquals, policyStringSet{"arn:aws:iam::111122223333:root"})
+
+	_, perr = parseBucketPolicy("invalid")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestURL2BucketAndPrefix(c *C) {
+	bucketURL, bucket, prefix := url2BucketAndPrefix("https://s3.amazonaws.com/bucket/downloads/")
+	c.Assert(bucketURL, Equals, "https://s3.amazonaws.com/bucket")
+	c.Assert(bucket, Equals, "bucket")
+	c.Assert(prefix, Equals, "downloads/")
+
+	bucketURL, bucket, prefix = url2BucketAndPrefix("https://bucket.s3.amazonaws.com/downloads/")
+	c.Assert(bucketURL, Equals, "https://bucket.s3.amazonaws.com/")
+	c.Assert(bucket, Equals, "bucket")
+	c.Assert(prefix, Equals, "downloads/")
+
+	_, bucket, prefix = url2BucketAndPrefix("http://localhost:9000/bucket")
+	c.Assert(bucket, Equals, "bucket")
+	c.Assert(prefix, Equals, "")
+}
+
+func (s *TestSuite) TestPolicyServer(c *C) {
+	s3v4Server.MakeBucket("policy-bucket", "private")
+	c.Assert(s3v4Server.PutObject("policy-bucket", "downloads/file", []byte("public")), Equals, true)
+	c.Assert(s3v4Server.PutObject("policy-bucket", "private/file", []byte("private")), Equals, true)
+
+	perr := doSetPolicy(s3v4Server.URL+"/policy-bucket/downloads/", policyReadOnly)
+	c.Assert(perr, IsNil)
+	perr = doSetPolicy(s3v4Server.URL+"/policy-bucket/incoming/", policyWriteOnly)
+	c.Assert(perr, IsNil)
+
+	perms, perr := doGetPolicy(s3v4Server.URL + "/policy-bucket/downloads/")
+	c.Assert(perr, IsNil)
+	c.Assert(perms, Equals, policyReadOnly)
+	perms, perr = doGetPolicy(s3v4Server.URL + "/policy-bucket/private/")
+	c.Assert(perr, IsNil)
+	c.Assert(perms, Equals, policyNone)
+
+	policies, perr := doListPolicies(s3v4Server.URL + "/policy-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(policies, DeepEquals, map[string]policyPerms{"downloads/": policyReadOnly, "incoming/": policyWriteOnly})
+
+	resp, err := http.Get(s3v4Server.URL + "/policy-bucket/downloads/file")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	resp, err = http.Get(s3v4Server.URL + "/policy-bucket/private/file")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+	perr = doSetPolicy(s3v4Server.URL+"/policy-bucket/downloads/", policyNone)
+	c.Assert(perr, IsNil)
+	perr = doSetPolicy(s3v4Server.URL+"/policy-bucket/incoming/", policyNone)
+	c.Assert(perr, IsNil)
+	policies, perr = doListPolicies(s3v4Server.URL + "/policy-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(policies, HasLen, 0)
+
+	// signature V2 and filesystem targets do not support policies
+	perr = doSetPolicy(s3v2Server.URL+"/policy-bucket/downloads/", policyReadOnly)
+	c.Assert(perr, Not(IsNil))
+	_, perr = doGetPolicy(c.MkDir())
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestPolicyContext(c *C) {
+	console.IsExited = false
+
+	s3v4Server.MakeBucket("policy-context", "private")
+	err := app.Run([]string{os.Args[0], "policy", "set", "readonly", s3v4Server.URL + "/policy-context/downloads/"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "policy", "list", s3v4Server.URL + "/policy-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "policy", "remove", s3v4Server.URL + "/policy-context/downloads/"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "policy", "set", "invalid", s3v4Server.URL + "/policy-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}
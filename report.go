@@ -0,0 +1,175 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Exit status of a run which finished with one or more failed objects,
+// fatal errors exit with 1.
+const exitStatusPartial = 2
+
+// RunReportMessage container for the summary of a cp, mirror or rm run,
+// its duration is in seconds and its rate in bytes per second.
+type RunReportMessage struct {
+	Command        string  `json:"command"`
+	Status         string  `json:"status"`
+	SessionID      string  `json:"session-id,omitempty"`
+	TotalObjects   int     `json:"total-objects"`
+	TotalBytes     int64   `json:"total-bytes"`
+	DoneObjects    int     `json:"done-objects"`
+	DoneBytes      int64   `json:"done-bytes"`
+	SkippedObjects int     `json:"skipped-objects"`
+	FailedObjects  int     `json:"failed-objects"`
+	Duration       float64 `json:"duration"`
+	Rate           int64   `json:"rate"`
+
+	start time.Time
+}
+
+// newRunReport starts the report of a run of session.
+func newRunReport(session *sessionV2) *RunReportMessage {
+	return &RunReportMessage{
+		Command:      session.Header.CommandType,
+		SessionID:    session.SessionID,
+		TotalObjects: session.Header.TotalObjects,
+		TotalBytes:   session.Header.TotalBytes,
+		start:        time.Now(),
+	}
+}
+
+// Done accounts an object transferred by this run.
+func (r *RunReportMessage) Done(size int64) {
+	r.DoneObjects++
+	r.DoneBytes += size
+}
+
+// Skipped accounts an object already done by a previous run of the session.
+func (r *RunReportMessage) Skipped() {
+	r.SkippedObjects++
+}
+
+// Failed accounts an object this run failed to transfer.
+func (r *RunReportMessage) Failed() {
+	r.FailedObjects++
+}
+
+// Finish stops the clock of the run and computes its average rate in bytes per second.
+func (r *RunReportMessage) Finish() RunReportMessage {
+	r.Duration = time.Since(r.start).Seconds()
+	if r.Duration > 0 {
+		r.Rate = int64(float64(r.DoneBytes) / r.Duration)
+	}
+	r.Status = "success"
+	if r.FailedObjects > 0 {
+		r.Status = "partial"
+	} else {
+		// Successful sessions are removed, there is nothing to refer to.
+		r.SessionID = ""
+	}
+	return *r
+}
+
+// elapsed is the duration of the run.
+func (r RunReportMessage) elapsed() time.Duration {
+	return time.Duration(r.Duration * float64(time.Second))
+}
+
+// String colorized run report message
+func (r RunReportMessage) String() string {
+	var done string
+	switch r.Command {
+	case "mirror":
+		done = "Mirrored"
+	case "rm":
+		done = "Removed"
+	default:
+		done = "Copied"
+	}
+	message := console.Colorize("Report", fmt.Sprintf("%-10s %d object(s), %s\n", done+":", r.DoneObjects, humanize.IBytes(uint64(r.DoneBytes))))
+	message += console.Colorize("Report", fmt.Sprintf("%-10s %d object(s)\n", "Skipped:", r.SkippedObjects))
+	if r.FailedObjects > 0 {
+		message += console.Colorize("ReportFailed", fmt.Sprintf("%-10s %d object(s)\n", "Failed:", r.FailedObjects))
+	} else {
+		message += console.Colorize("Report", fmt.Sprintf("%-10s %d object(s)\n", "Failed:", r.FailedObjects))
+	}
+	message += console.Colorize("Report", fmt.Sprintf("%-10s %d object(s), %s\n", "Total:", r.TotalObjects, humanize.IBytes(uint64(r.TotalBytes))))
+	message += console.Colorize("Report", fmt.Sprintf("%-10s %s\n", "Duration:", timeDurationToHumanizedTime(r.elapsed())))
+	message += console.Colorize("Report", fmt.Sprintf("%-10s %s/s", "Rate:", humanize.IBytes(uint64(r.Rate))))
+	return message
+}
+
+// JSON jsonified run report message
+func (r RunReportMessage) JSON() string {
+	runReportMessageBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(runReportMessageBytes)
+}
+
+// checkReportSyntax rejects ‘--report’ along with ‘--dry-run’, a dry run transfers nothing to report.
+func checkReportSyntax(ctx *cli.Context) {
+	if ctx.String("report") != "" && globalDryRunFlag {
+		fatalIf(errInvalidArgument().Trace(ctx.String("report")), "‘--report’ cannot be used with ‘--dry-run’.")
+	}
+}
+
+// printRunReport prints the summary of a run, writes it to reportFile if set
+// and exits with exitStatusPartial if any object failed.
+func printRunReport(report RunReportMessage, reportFile string) {
+	// Dry runs only print their plan, see checkReportSyntax.
+	if globalDryRunFlag {
+		return
+	}
+	Prints("%s\n", report)
+	if reportFile != "" {
+		e := ioutil.WriteFile(reportFile, []byte(report.JSON()+"\n"), 0644)
+		fatalIf(probe.NewError(e), "Unable to write report ‘"+reportFile+"’.")
+	}
+	if report.FailedObjects > 0 {
+		console.Exit(exitStatusPartial)
+	}
+}
+
+func setReportPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Report":       color.New(color.FgGreen),
+		"ReportFailed": color.New(color.FgRed, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Report":       color.New(color.FgWhite),
+			"ReportFailed": color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	/// Add more styles here
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
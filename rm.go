@@ -0,0 +1,260 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// RemoveMessage container for removed objects
+type RemoveMessage struct {
+	Name string `json:"name"`
+}
+
+// String colorized remove message
+func (r RemoveMessage) String() string {
+	return console.Colorize("Remove", fmt.Sprintf("Removed ‘%s’.", r.Name))
+}
+
+// JSON jsonified remove message
+func (r RemoveMessage) JSON() string {
+	removeMessageBytes, e := json.Marshal(r)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(removeMessageBytes)
+}
+
+// prepareRemoveURLs lists the objects to remove, targets ending with ‘...’ are
+// removed recursively. Removed objects are sent as source of copyURLs.
+func prepareRemoveURLs(targetURLs []string) <-chan copyURLs {
+	rmURLsCh := make(chan copyURLs)
+	go func() {
+		defer close(rmURLsCh)
+		for _, targetURL := range targetURLs {
+			recursive := isURLRecursive(targetURL)
+			targetURL = stripRecursiveURL(targetURL)
+			clnt, content, err := url2Stat(targetURL)
+			if err != nil {
+				rmURLsCh <- copyURLs{Error: err.Trace(targetURL)}
+				continue
+			}
+			if !content.Type.IsDir() {
+				content.Name = targetURL
+				rmURLsCh <- copyURLs{SourceContent: content}
+				continue
+			}
+			if !recursive {
+				rmURLsCh <- copyURLs{Error: errSourceNotRecursive(targetURL).Trace(targetURL)}
+				continue
+			}
+			// list below the folder, so that names are relative to it.
+			separator := string(clnt.URL().Separator)
+			folder := strings.TrimSuffix(targetURL, separator) + separator
+			if clnt, err = url2Client(folder); err != nil {
+				rmURLsCh <- copyURLs{Error: err.Trace(folder)}
+				continue
+			}
+			for entry := range clnt.List(true) {
+				if entry.Err != nil {
+					rmURLsCh <- copyURLs{Error: entry.Err.Trace(folder)}
+					continue
+				}
+				if !entry.Content.Type.IsRegular() {
+					continue
+				}
+				entry.Content.Name = folder + entry.Content.Name
+				rmURLsCh <- copyURLs{SourceContent: entry.Content}
+			}
+		}
+	}()
+	return rmURLsCh
+}
+
+// doPrepareRemoveURLs scans the targets and prepares a list of objects for removal.
+func doPrepareRemoveURLs(session *sessionV2, trapCh <-chan bool) *probe.Error {
+	var totalBytes int64
+	var totalObjects int
+
+	// Create a session data file to store the processed URLs.
+	dataFP := session.NewDataWriter()
+
+	URLsCh := prepareRemoveURLs(session.Header.CommandArgs)
+	done := false
+	for done == false {
+		select {
+		case rmURLs, ok := <-URLsCh:
+			if !ok { // Done with URL prepration
+				done = true
+				break
+			}
+			if rmURLs.Error != nil {
+				errorIf(rmURLs.Error.Trace(), "Unable to prepare URL for removal.")
+				break
+			}
+			if session.Header.IsFiltered(rmURLs.SourceContent.Name) {
+				break
+			}
+			jsonData, e := json.Marshal(rmURLs)
+			if e != nil {
+				session.Delete()
+				return probe.NewError(e)
+			}
+			fmt.Fprintln(dataFP, string(jsonData))
+
+			totalBytes += rmURLs.SourceContent.Size
+			totalObjects++
+		case <-trapCh:
+			session.Delete() // If we are interrupted during the URL scanning, we drop the session.
+			os.Exit(0)
+		}
+	}
+	session.Header.TotalBytes = totalBytes
+	session.Header.TotalObjects = totalObjects
+	return session.Save().Trace(session.SessionID)
+}
+
+// doRemove - remove a single object.
+func doRemove(rmURLs copyURLs, rmQueue <-chan bool, wg *sync.WaitGroup, statusCh chan<- copyURLs) {
+	defer wg.Done() // Notify that this remove routine is done.
+	defer func() {
+		<-rmQueue
+	}()
+
+	var clnt client.Client
+	clnt, rmURLs.Error = url2Client(rmURLs.SourceContent.Name)
+	if rmURLs.Error == nil {
+		rmURLs.Error = clnt.Remove()
+	}
+	if rmURLs.Error != nil {
+		rmURLs.Error = rmURLs.Error.Trace(rmURLs.SourceContent.Name)
+	}
+	statusCh <- rmURLs
+}
+
+// doRemoveSession removes the objects of a session, sessions of this type are run by batch jobs.
+func doRemoveSession(session *sessionV2) (RunReportMessage, *probe.Error) {
+	trapCh := signalTrap(os.Interrupt, os.Kill)
+
+	if !session.HasData() {
+		if err := doPrepareRemoveURLs(session, trapCh); err != nil {
+			return RunReportMessage{}, err.Trace(session.SessionID)
+		}
+	}
+
+	scanner := bufio.NewScanner(session.NewDataReader())
+	isRemoved, err := session.NewSkipFilter()
+	if err != nil {
+		return RunReportMessage{}, err.Trace(session.SessionID)
+	}
+	report := newRunReport(session)
+
+	// Only print the removal plan, do not remove anything.
+	if globalDryRunFlag {
+		var totalBytes int64
+		var totalObjects int
+		for index := 0; scanner.Scan(); index++ {
+			var rmURLs copyURLs
+			json.Unmarshal([]byte(scanner.Text()), &rmURLs)
+			if isRemoved(index, rmURLs.SourceContent.Name) {
+				continue
+			}
+			Prints("%s\n", RemoveMessage{Name: rmURLs.SourceContent.Name})
+			totalBytes += rmURLs.SourceContent.Size
+			totalObjects++
+		}
+		Prints("%s\n", DryRunMessage{
+			Operation:    "rm",
+			TotalObjects: totalObjects,
+			TotalBytes:   totalBytes,
+		})
+		return report.Finish(), nil
+	}
+
+	wg := new(sync.WaitGroup)
+	// Limit number of remove routines, sessions run by a batch share the limit.
+	rmQueue := session.TransferQueue()
+	// Status channel for receiveing remove return status.
+	statusCh := make(chan copyURLs)
+
+	// Go routine to monitor doRemove status and signal traps.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case rmURLs, ok := <-statusCh: // Receive status.
+				if !ok { // We are done here. Top level function has returned.
+					return
+				}
+				errorIf(session.SetObjectStatus(rmURLs.index, rmURLs.SourceContent.Name, rmURLs.SourceContent.Size, rmURLs.Error).Trace(), "Unable to record status of ‘"+rmURLs.SourceContent.Name+"’.")
+				if rmURLs.Error == nil {
+					report.Done(rmURLs.SourceContent.Size)
+					Prints("%s\n", RemoveMessage{Name: rmURLs.SourceContent.Name})
+					session.Save()
+					continue
+				}
+				errorIf(rmURLs.Error.Trace(), fmt.Sprintf("Failed to remove ‘%s’.", rmURLs.SourceContent.Name))
+				report.Failed()
+				if session.batch { // other sessions of the batch keep running.
+					continue
+				}
+				switch rmURLs.Error.ToGoError().(type) {
+				case *net.OpError:
+					gracefulSessionSave(session)
+				case net.Error:
+					gracefulSessionSave(session)
+				}
+			case <-trapCh: // Receive interrupt notification.
+				gracefulSessionSave(session)
+			}
+		}
+	}()
+
+	// Go routine to perform concurrent removal.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rmWg := new(sync.WaitGroup)
+		defer close(statusCh)
+
+		for index := 0; scanner.Scan(); index++ {
+			var rmURLs copyURLs
+			json.Unmarshal([]byte(scanner.Text()), &rmURLs)
+			rmURLs.index = index
+			if isRemoved(index, rmURLs.SourceContent.Name) {
+				report.Skipped()
+				continue
+			}
+			rmQueue <- true
+			rmWg.Add(1)
+			go doRemove(rmURLs, rmQueue, rmWg, statusCh)
+		}
+		rmWg.Wait()
+	}()
+	wg.Wait()
+	return report.Finish(), nil
+}
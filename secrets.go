@@ -0,0 +1,323 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/pkg/probe"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// Secrets of hosts can be kept out of config.json, the host then holds a
+// reference in ‘secretRef’ instead of its ‘secretAccessKey’:
+//
+//	store:NAME    secret NAME of the encrypted secret store, unlocked with a master passphrase.
+//	command:CMD   output of command CMD, e.g. ‘command:pass show minio/play’.
+const (
+	secretRefStore   = "store:"
+	secretRefCommand = "command:"
+)
+
+// Secret store key derivation parameters, recommended by scrypt for interactive logins.
+const (
+	secretStoreVersion = "1"
+	secretStoreN       = 1 << 15
+	secretStoreR       = 8
+	secretStoreP       = 1
+	secretStoreKeyLen  = 32
+	secretStoreCheck   = "mc"
+)
+
+// secretStoreV1 holds secrets encrypted with AES-256-GCM, the key is derived
+// from the master passphrase with scrypt.
+type secretStoreV1 struct {
+	Version string `json:"version"`
+	KDF     struct {
+		Name string `json:"name"`
+		Salt []byte `json:"salt"`
+		N    int    `json:"N"`
+		R    int    `json:"r"`
+		P    int    `json:"p"`
+	} `json:"kdf"`
+	// Check is a known value encrypted with the key, to verify the passphrase
+	// before anything is added to the store.
+	Check   []byte            `json:"check"`
+	Secrets map[string][]byte `json:"secrets"`
+}
+
+// Unlocked key of the secret store and resolved secret references, the
+// passphrase is asked and commands are run once per process.
+var (
+	secretStoreKey   []byte
+	secretsCache     = make(map[string]string)
+	secretsCacheLock = &sync.Mutex{}
+)
+
+// getSecretStorePath - construct secret store path.
+func getSecretStorePath() (string, *probe.Error) {
+	dir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(dir, globalSecretsFile), nil
+}
+
+// loadSecretStore loads the secret store, a new store is returned if there is none yet.
+func loadSecretStore() (*secretStoreV1, *probe.Error) {
+	storePath, err := getSecretStorePath()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	store := &secretStoreV1{Version: secretStoreVersion, Secrets: make(map[string][]byte)}
+	data, e := ioutil.ReadFile(storePath)
+	if os.IsNotExist(e) {
+		store.KDF.Name = "scrypt"
+		store.KDF.N, store.KDF.R, store.KDF.P = secretStoreN, secretStoreR, secretStoreP
+		store.KDF.Salt = make([]byte, 32)
+		if _, e = io.ReadFull(rand.Reader, store.KDF.Salt); e != nil {
+			return nil, probe.NewError(e)
+		}
+		return store, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, store); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if store.Version != secretStoreVersion || store.KDF.Name != "scrypt" {
+		return nil, probe.NewError(errors.New("Unsupported secret store version ‘" + store.Version + "’."))
+	}
+	if store.Secrets == nil {
+		store.Secrets = make(map[string][]byte)
+	}
+	return store, nil
+}
+
+// saveSecretStore writes the store, readable only by its owner.
+func saveSecretStore(store *secretStoreV1) *probe.Error {
+	if err := createMcConfigDir(); err != nil {
+		return err.Trace()
+	}
+	storePath, err := getSecretStorePath()
+	if err != nil {
+		return err.Trace()
+	}
+	data, e := json.MarshalIndent(store, "", "\t")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	// Replace atomically, a partially written store loses all secrets.
+	tmpFile, e := ioutil.TempFile(filepath.Dir(storePath), globalSecretsFile+".")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, e = tmpFile.Write(data); e != nil {
+		tmpFile.Close()
+		return probe.NewError(e)
+	}
+	if e = tmpFile.Close(); e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.Chmod(tmpFile.Name(), 0600); e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.Rename(tmpFile.Name(), storePath); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// getMasterPassphrase reads the master passphrase from MC_SECRETS_PASSPHRASE, or prompts
+// for it without echo if stdin is a terminal. Other stdin is left alone, it may carry data.
+func getMasterPassphrase() (string, *probe.Error) {
+	if passphrase := os.Getenv("MC_SECRETS_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", probe.NewError(errors.New("Master passphrase of secret store is not set, set MC_SECRETS_PASSPHRASE or use ‘--secret-command’ instead."))
+	}
+	os.Stderr.WriteString("Master passphrase of secret store: ")
+	passphrase, e := term.ReadPassword(fd)
+	os.Stderr.WriteString("\n")
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	if len(passphrase) == 0 {
+		return "", probe.NewError(errors.New("Master passphrase of secret store cannot be empty."))
+	}
+	return string(passphrase), nil
+}
+
+// sealSecret encrypts secret name, the name is authenticated so that secrets cannot be swapped.
+func sealSecret(key []byte, name string, secret []byte) ([]byte, *probe.Error) {
+	block, e := aes.NewCipher(key)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	gcm, e := cipher.NewGCM(block)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, e = io.ReadFull(rand.Reader, nonce); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return gcm.Seal(nonce, nonce, secret, []byte(name)), nil
+}
+
+// openSecret decrypts secret name sealed by sealSecret.
+func openSecret(key []byte, name string, sealed []byte) ([]byte, *probe.Error) {
+	block, e := aes.NewCipher(key)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	gcm, e := cipher.NewGCM(block)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, probe.NewError(errors.New("Secret ‘" + name + "’ is corrupted."))
+	}
+	secret, e := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if e != nil {
+		return nil, probe.NewError(errors.New("Unable to decrypt secret ‘" + name + "’, wrong master passphrase."))
+	}
+	return secret, nil
+}
+
+// unlockSecretStore derives the key of store from the master passphrase and
+// verifies it. A new store is locked with the passphrase given first.
+func unlockSecretStore(store *secretStoreV1) ([]byte, *probe.Error) {
+	if secretStoreKey != nil {
+		return secretStoreKey, nil
+	}
+	passphrase, err := getMasterPassphrase()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	key, e := scrypt.Key([]byte(passphrase), store.KDF.Salt, store.KDF.N, store.KDF.R, store.KDF.P, secretStoreKeyLen)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if store.Check == nil {
+		if store.Check, err = sealSecret(key, secretStoreCheck, []byte(secretStoreCheck)); err != nil {
+			return nil, err.Trace()
+		}
+	} else if _, err = openSecret(key, secretStoreCheck, store.Check); err != nil {
+		return nil, probe.NewError(errors.New("Wrong master passphrase of secret store."))
+	}
+	secretStoreKey = key
+	return key, nil
+}
+
+// putStoreSecret encrypts secret into the store as name and returns its reference.
+func putStoreSecret(name, secret string) (string, *probe.Error) {
+	secretsCacheLock.Lock()
+	defer secretsCacheLock.Unlock()
+
+	store, err := loadSecretStore()
+	if err != nil {
+		return "", err.Trace()
+	}
+	key, err := unlockSecretStore(store)
+	if err != nil {
+		return "", err.Trace()
+	}
+	if store.Secrets[name], err = sealSecret(key, name, []byte(secret)); err != nil {
+		return "", err.Trace(name)
+	}
+	if err = saveSecretStore(store); err != nil {
+		return "", err.Trace()
+	}
+	ref := secretRefStore + name
+	secretsCache[ref] = secret
+	return ref, nil
+}
+
+// removeStoreSecret removes the secret of reference ref from the store, other references are left alone.
+func removeStoreSecret(ref string) *probe.Error {
+	if !strings.HasPrefix(ref, secretRefStore) {
+		return nil
+	}
+	secretsCacheLock.Lock()
+	defer secretsCacheLock.Unlock()
+
+	store, err := loadSecretStore()
+	if err != nil {
+		return err.Trace()
+	}
+	delete(store.Secrets, strings.TrimPrefix(ref, secretRefStore))
+	delete(secretsCache, ref)
+	return saveSecretStore(store).Trace()
+}
+
+// resolveSecretRef returns the secret reference ref refers to.
+func resolveSecretRef(ref string) (string, *probe.Error) {
+	secretsCacheLock.Lock()
+	defer secretsCacheLock.Unlock()
+	if secret, ok := secretsCache[ref]; ok {
+		return secret, nil
+	}
+
+	var secret string
+	switch {
+	case strings.HasPrefix(ref, secretRefStore):
+		name := strings.TrimPrefix(ref, secretRefStore)
+		store, err := loadSecretStore()
+		if err != nil {
+			return "", err.Trace()
+		}
+		sealed, ok := store.Secrets[name]
+		if !ok {
+			return "", probe.NewError(errors.New("Secret ‘" + name + "’ not found in secret store."))
+		}
+		key, err := unlockSecretStore(store)
+		if err != nil {
+			return "", err.Trace()
+		}
+		plaintext, err := openSecret(key, name, sealed)
+		if err != nil {
+			return "", err.Trace()
+		}
+		secret = string(plaintext)
+	case strings.HasPrefix(ref, secretRefCommand):
+		output, err := runShellCommand(strings.TrimPrefix(ref, secretRefCommand))
+		if err != nil {
+			return "", err.Trace(ref)
+		}
+		secret = strings.TrimRight(string(output), "\r\n")
+	default:
+		return "", probe.NewError(errors.New("Unsupported secret reference ‘" + ref + "’."))
+	}
+	secretsCache[ref] = secret
+	return secret, nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// sortedListOrphanAge is the age after which sorted lists of older versions, which
+// do not name their owner, are considered orphaned.
+const sortedListOrphanAge = 24 * time.Hour
+
+// parseAge parses ages like ‘7d’, ‘36h’ or ‘90m’.
+func parseAge(age string) (time.Duration, *probe.Error) {
+	if strings.HasSuffix(age, "d") {
+		days, e := strconv.ParseUint(strings.TrimSuffix(age, "d"), 10, 32)
+		if e != nil {
+			return 0, probe.NewError(errors.New("Invalid age ‘" + age + "’, expected NN[d|h|m|s]."))
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, e := time.ParseDuration(age)
+	if e != nil || duration < 0 {
+		return 0, probe.NewError(errors.New("Invalid age ‘" + age + "’, expected NN[d|h|m|s]."))
+	}
+	return duration, nil
+}
+
+// checkSessionExpirySyntax validates ‘--session-expiry’, which is optional.
+func checkSessionExpirySyntax(ctx *cli.Context) {
+	if expiry := ctx.String("session-expiry"); expiry != "" {
+		age, err := parseAge(expiry)
+		fatalIf(err.Trace(expiry), "Unable to parse ‘--session-expiry’.")
+		if age == 0 {
+			fatalIf(errInvalidArgument().Trace(expiry), "‘--session-expiry’ expects an age larger than 0.")
+		}
+	}
+}
+
+// getSessionModTime provides the last time session sid was saved.
+func getSessionModTime(sid string) (time.Time, *probe.Error) {
+	sessionFile, err := getSessionFile(sid)
+	if err != nil {
+		return time.Time{}, err.Trace(sid)
+	}
+	st, e := os.Stat(sessionFile)
+	if e != nil {
+		return time.Time{}, probe.NewError(e)
+	}
+	return st.ModTime(), nil
+}
+
+// getExpiredSessionIDs provides the sessions which are not running and were saved more than age ago.
+func getExpiredSessionIDs(age time.Duration) (sids []string) {
+	for _, sid := range getSessionIDs() {
+		modTime, err := getSessionModTime(sid)
+		if err != nil || time.Since(modTime) < age {
+			continue
+		}
+		if sessionOwner(sid) != 0 {
+			continue
+		}
+		sids = append(sids, sid)
+	}
+	return sids
+}
+
+// sweepSessionFiles removes data, status and lock files of sessions which
+// have no session file and are not running, as left behind by crashes.
+func sweepSessionFiles() *probe.Error {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return err.Trace()
+	}
+	for _, ext := range []string{".data", ".status", ".lock"} {
+		files, e := filepath.Glob(filepath.Join(sessionDir, "*"+ext))
+		if e != nil {
+			return probe.NewError(e)
+		}
+		for _, file := range files {
+			sid := strings.TrimSuffix(filepath.Base(file), ext)
+			if isSession(sid) || sessionOwner(sid) != 0 {
+				continue
+			}
+			if e := os.Remove(file); e != nil && !os.IsNotExist(e) {
+				return probe.NewError(e)
+			}
+		}
+	}
+	return nil
+}
+
+// newSortedListID provides a prefix for sorted list files, naming the process owning them.
+func newSortedListID() string {
+	return strconv.Itoa(os.Getpid()) + "-" + newRandomID(8)
+}
+
+// sweepSortedLists removes sorted lists whose process is gone, as left behind by crashed mirrors.
+func sweepSortedLists() *probe.Error {
+	sortedListDir, err := getSortedListDir()
+	if err != nil {
+		return err.Trace()
+	}
+	files, e := filepath.Glob(filepath.Join(sortedListDir, "*"))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		if i := strings.Index(name, "-"); i > 0 {
+			if pid, e := strconv.Atoi(name[:i]); e == nil && isProcessAlive(pid) {
+				continue
+			}
+		} else if st, e := os.Stat(file); e != nil || time.Since(st.ModTime()) < sortedListOrphanAge {
+			continue
+		}
+		if e := os.Remove(file); e != nil && !os.IsNotExist(e) {
+			return probe.NewError(e)
+		}
+	}
+	return nil
+}
+
+// sweepSessions removes files left behind by crashed runs.
+func sweepSessions() *probe.Error {
+	if err := sweepSessionFiles(); err != nil {
+		return err.Trace()
+	}
+	return sweepSortedLists().Trace()
+}
+
+// expireSessions removes sessions which were abandoned for longer than expiry along
+// with files left behind by crashed runs, nothing is removed if expiry is not set.
+func expireSessions(expiry string) *probe.Error {
+	if expiry == "" || !isSessionDirExists() {
+		return nil
+	}
+	age, err := parseAge(expiry)
+	if err != nil {
+		return err.Trace(expiry)
+	}
+	if age == 0 {
+		return errInvalidArgument().Trace(expiry)
+	}
+
+	for _, sid := range getExpiredSessionIDs(age) {
+		session, err := loadSessionV2(sid)
+		if err == nil {
+			err = session.Delete()
+		}
+		errorIf(err.Trace(sid), "Unable to expire session ‘"+sid+"’.")
+	}
+	return sweepSessions().Trace()
+}
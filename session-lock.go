@@ -0,0 +1,125 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio/pkg/probe"
+)
+
+// sessionLock is held by the process running a session. The lock file records the
+// PID of its owner, on platforms with file locks it is locked by the operating system
+// as well, so that locks of crashed processes are released by the operating system.
+type sessionLock struct {
+	file *os.File
+}
+
+// readLockPID provides the PID recorded in a lock file, 0 if there is none.
+func readLockPID(file *os.File) int {
+	if _, e := file.Seek(0, os.SEEK_SET); e != nil {
+		return 0
+	}
+	pidBytes, e := ioutil.ReadAll(file)
+	if e != nil {
+		return 0
+	}
+	pid, e := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if e != nil {
+		return 0
+	}
+	return pid
+}
+
+// lockSession locks session sid for this process, sessions locked by live
+// processes fail with errSessionInUse.
+func lockSession(sid string) (*sessionLock, *probe.Error) {
+	lockFile, err := getSessionLockFile(sid)
+	if err != nil {
+		return nil, err.Trace(sid)
+	}
+	file, e := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0600)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = lockFileExclusive(file); e != nil {
+		pid := readLockPID(file)
+		file.Close()
+		return nil, errSessionInUse(sid, pid).Trace(lockFile)
+	}
+	// Without file locks a stale lock is only told apart by the liveness of its owner.
+	if pid := readLockPID(file); !hasFileLocks && pid != 0 && pid != os.Getpid() && isProcessAlive(pid) {
+		file.Close()
+		return nil, errSessionInUse(sid, pid).Trace(lockFile)
+	}
+	if e = file.Truncate(0); e == nil {
+		_, e = file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if e != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, probe.NewError(e)
+	}
+	return &sessionLock{file: file}, nil
+}
+
+// Unlock releases the session lock, the lock file is left behind for
+// processes which are waiting for it.
+func (l *sessionLock) Unlock() *probe.Error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	defer func() {
+		l.file.Close()
+		l.file = nil
+	}()
+	if e := l.file.Truncate(0); e != nil {
+		return probe.NewError(e)
+	}
+	if e := unlockFile(l.file); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// sessionOwner provides the PID of the live process holding the lock of session sid, 0 if it is not active.
+func sessionOwner(sid string) int {
+	lockFile, err := getSessionLockFile(sid)
+	if err != nil {
+		return 0
+	}
+	file, e := os.Open(lockFile)
+	if e != nil {
+		return 0
+	}
+	defer file.Close()
+
+	pid := readLockPID(file)
+	if hasFileLocks {
+		if isFileLocked(file) {
+			return pid
+		}
+		return 0
+	}
+	if pid != 0 && isProcessAlive(pid) {
+		return pid
+	}
+	return 0
+}
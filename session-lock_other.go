@@ -0,0 +1,51 @@
+// +build !darwin,!dragonfly,!freebsd,!illumos,!linux,!netbsd,!openbsd,!windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// hasFileLocks is set on platforms where lock files are locked by the operating system.
+const hasFileLocks = false
+
+// lockFileExclusive is a no-op, sessions are locked by the PID of their owner only.
+func lockFileExclusive(file *os.File) error {
+	return nil
+}
+
+// isFileLocked is always false, the PID of the owner tells whether a session is locked.
+func isFileLocked(file *os.File) bool {
+	return false
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
+
+// isProcessAlive reports whether process pid exists.
+func isProcessAlive(pid int) bool {
+	process, e := os.FindProcess(pid)
+	if e != nil {
+		return false
+	}
+	defer process.Release()
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,52 @@
+// +build darwin dragonfly freebsd illumos linux netbsd openbsd
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// hasFileLocks is set on platforms where lock files are locked by the operating system.
+const hasFileLocks = true
+
+// lockFileExclusive locks file without waiting for other holders.
+func lockFileExclusive(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// isFileLocked reports whether file is locked exclusively by another holder, a shared
+// lock is enough to find out so that processes inspecting sessions never conflict.
+func isFileLocked(file *os.File) bool {
+	if syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB) != nil {
+		return true
+	}
+	unlockFile(file)
+	return false
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// isProcessAlive reports whether process pid exists, processes of other users included.
+func isProcessAlive(pid int) bool {
+	e := syscall.Kill(pid, syscall.Signal(0))
+	return e == nil || e == syscall.EPERM
+}
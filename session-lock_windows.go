@@ -0,0 +1,63 @@
+// +build windows
+
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// hasFileLocks is set on platforms where lock files are locked by the operating system.
+const hasFileLocks = true
+
+// lockRange provides the region of a lock file which is locked. Windows locks are
+// mandatory, a byte beyond the recorded PID is locked so that the PID stays readable.
+func lockRange() *windows.Overlapped {
+	return &windows.Overlapped{OffsetHigh: 1}
+}
+
+// lockFileExclusive locks file without waiting for other holders.
+func lockFileExclusive(file *os.File) error {
+	return windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, lockRange())
+}
+
+// isFileLocked reports whether file is locked exclusively by another holder, a shared
+// lock is enough to find out so that processes inspecting sessions never conflict.
+func isFileLocked(file *os.File) bool {
+	if windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, lockRange()) != nil {
+		return true
+	}
+	unlockFile(file)
+	return false
+}
+
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, lockRange())
+}
+
+// isProcessAlive reports whether process pid exists.
+func isProcessAlive(pid int) bool {
+	process, e := os.FindProcess(pid)
+	if e != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
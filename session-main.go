@@ -18,9 +18,11 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
@@ -33,26 +35,62 @@ var sessionCmd = cli.Command{
 	Name:   "session",
 	Usage:  "Manage sessions for cp and mirror.",
 	Action: mainSession,
+	Flags:  []cli.Flag{failedOnlyFlag, reportFlag, olderThanFlag, sessionExpiryFlag},
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
    mc {{.Name}} list
-   mc {{.Name}} resume SESSION-ID
+   mc {{.Name}} info SESSION-ID
+   mc {{.Name}} pause SESSION-ID
+   mc {{.Name}} resume SESSION-ID [--failed-only] [--report FILE]
+   mc {{.Name}} retry SESSION-ID [--failed-only] [--report FILE]
+   mc {{.Name}} export SESSION-ID
    mc {{.Name}} clear SESSION-ID
-
-   SESSION-ID = $SESSION | all
-
+   mc {{.Name}} clear --older-than AGE
+
+   SESSION-ID = $SESSION | all (clear only)
+   AGE = NN[d|h|m|s]
+
+   ‘pause’ interrupts the mc process running a session, which saves the session the same way as
+   on Ctrl-C. Sessions still listing their objects are cleared instead. Pausing is not supported
+   on Windows.
+   ‘resume’ and ‘retry’ transfer every object of a session which is not done yet, failed objects
+   included. With ‘--failed-only’ only the failed objects are transferred again.
+   cp, mirror and session clear sessions which were not saved for longer than ‘--session-expiry’
+   before starting, sessions are kept forever unless it is given. Files left behind by crashed
+   sessions are removed along with expired sessions and by ‘clear’.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. List sessions
       $ mc {{.Name}} list
       ygVIpSJs -> [2015-08-29 15:25:12 PDT] cp /usr/bin... test
+      kRPvnTaW -> [2015-08-29 16:02:40 PDT] mirror photos play/photos (active, PID 4242)
 
    2. Resume session
       $ mc {{.Name}} resume ygVIpSJs
 
    3. Clear session
       $ mc {{.Name}} clear ygVIpSJs
+
+   4. Show progress and failed objects of a session
+      $ mc {{.Name}} info ygVIpSJs
+
+   5. Transfer only the failed objects of a session again
+      $ mc {{.Name}} retry ygVIpSJs --failed-only
+
+   6. Export the plan and status of a session as JSON
+      $ mc {{.Name}} export ygVIpSJs > ygVIpSJs.json
+
+   7. Clear sessions which were abandoned for a week, e.g. from cron
+      $ mc {{.Name}} clear --older-than 7d
+
+   8. Pause a running session and resume it later
+      $ mc {{.Name}} pause kRPvnTaW
+      $ mc {{.Name}} resume kRPvnTaW
 `,
 }
 
@@ -70,6 +108,7 @@ func listSessions() *probe.Error {
 		if err != nil {
 			return err.Trace()
 		}
+		s.activePID = sessionOwner(sid)
 		bySessions = append(bySessions, s)
 	}
 	// sort sessions based on time
@@ -99,19 +138,30 @@ func (c ClearSessionMessage) JSON() string {
 	return string(clearSessionJSONBytes)
 }
 
-func clearSession(sid string) {
-	if sid == "all" {
-		for _, sid := range getSessionIDs() {
+// clearSession removes session sid, with ‘all’ every session which is not running
+// or with olderThan every session which was not saved for at least olderThan.
+func clearSession(sid string, olderThan time.Duration) {
+	if sid == "all" || olderThan > 0 {
+		sids := getSessionIDs()
+		if olderThan > 0 {
+			sids = getExpiredSessionIDs(olderThan)
+		}
+		for _, sid := range sids {
 			session, err := loadSessionV2(sid)
 			fatalIf(err.Trace(sid), "Unable to load session ‘"+sid+"’.")
 
-			fatalIf(session.Delete().Trace(sid), "Unable to load session ‘"+sid+"’.")
+			// sessions running in other processes are left alone.
+			if err = session.Delete(); err != nil {
+				errorIf(err.Trace(sid), "Unable to clear session ‘"+sid+"’.")
+				continue
+			}
 
 			Prints("%s\n", ClearSessionMessage{
 				Status:    "success",
 				SessionID: sid,
 			})
 		}
+		fatalIf(sweepSessions().Trace(), "Unable to remove files of crashed sessions.")
 		return
 	}
 
@@ -129,15 +179,106 @@ func clearSession(sid string) {
 			SessionID: sid,
 		})
 	}
+	fatalIf(sweepSessions().Trace(), "Unable to remove files of crashed sessions.")
 }
 
-func sessionExecute(s *sessionV2) {
+// PauseSessionMessage container for pausing session messages
+type PauseSessionMessage struct {
+	Status    string `json:"status"`
+	SessionID string `json:"sessionId"`
+	PID       int    `json:"pid"`
+}
+
+// String colorized pause session message
+func (p PauseSessionMessage) String() string {
+	return console.Colorize("ClearSession", fmt.Sprintf("Session ‘%s’ of process %d paused successfully", p.SessionID, p.PID))
+}
+
+// JSON jsonified pause session message
+func (p PauseSessionMessage) JSON() string {
+	pauseSessionJSONBytes, err := json.Marshal(p)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(pauseSessionJSONBytes)
+}
+
+// pauseSession interrupts the process running session sid, which saves the
+// session so that it can be resumed later.
+func pauseSession(sid string) {
+	if !isSession(sid) {
+		fatalIf(errDummy().Trace(), "Session ‘"+sid+"’ not found.")
+	}
+	pid := sessionOwner(sid)
+	if pid == 0 {
+		fatalIf(errDummy().Trace(sid), "Session ‘"+sid+"’ is not running.")
+		return
+	}
+	process, e := os.FindProcess(pid)
+	fatalIf(probe.NewError(e).Trace(sid), "Unable to find process of session ‘"+sid+"’.")
+	e = process.Signal(os.Interrupt)
+	fatalIf(probe.NewError(e).Trace(sid), "Unable to pause session ‘"+sid+"’.")
+
+	Prints("%s\n", PauseSessionMessage{
+		Status:    "success",
+		SessionID: sid,
+		PID:       pid,
+	})
+}
+
+// sessionExecute runs session s by its command type.
+func sessionExecute(s *sessionV2) (RunReportMessage, *probe.Error) {
 	switch s.Header.CommandType {
 	case "cp":
-		doCopySession(s)
+		return doCopySession(s)
 	case "mirror":
-		doMirrorSession(s)
+		return doMirrorSession(s)
+	case "rm":
+		return doRemoveSession(s)
+	}
+	return newRunReport(s).Finish(), nil
+}
+
+// resumeSession continues session sid with all objects which are not done yet,
+// or with failedOnly just the failed objects. The summary is written to reportFile if set.
+func resumeSession(sid string, failedOnly bool, reportFile string) {
+	if !isSession(sid) {
+		fatalIf(errDummy().Trace(), "Session ‘"+sid+"’ not found.")
+	}
+
+	// Lock before loading, so that the session is not changed by its previous owner meanwhile.
+	lock, err := lockSession(sid)
+	fatalIf(err.Trace(sid), "Unable to resume session ‘"+sid+"’.")
+
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		lock.Unlock()
+		fatalIf(err.Trace(sid), "Unable to load session.")
+	}
+
+	// extra check for testing purposes
+	if s == nil {
+		return
 	}
+	s.lock = lock
+	s.failedOnly = failedOnly
+
+	savedCwd, e := os.Getwd()
+	fatalIf(probe.NewError(e), "Unable to verify your current working folder.")
+
+	if s.Header.RootPath != "" {
+		// chdir to RootPath
+		e = os.Chdir(s.Header.RootPath)
+		fatalIf(probe.NewError(e), "Unable to change our folder to root path while resuming session.")
+	}
+	report, err := sessionExecute(s)
+	fatalIf(err.Trace(sid), "Unable to resume session ‘"+sid+"’.")
+	err = s.Finish()
+	fatalIf(err.Trace(), "Unable to finish session properly.")
+
+	// chdir back to saved path
+	e = os.Chdir(savedCwd)
+	fatalIf(probe.NewError(e), "Unable to change our folder to saved path ‘"+savedCwd+"’.")
+	printRunReport(report, reportFile)
 }
 
 func checkSessionSyntax(ctx *cli.Context) {
@@ -148,14 +289,29 @@ func checkSessionSyntax(ctx *cli.Context) {
 		cli.ShowCommandHelpAndExit(ctx, "session", 1) // last argument is exit code
 	}
 
+	checkSessionExpirySyntax(ctx)
+
 	switch strings.TrimSpace(ctx.Args().First()) {
 	case "list":
-	case "resume":
+	case "pause", "resume", "retry", "info", "export":
 		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
 			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
 		}
+		checkReportSyntax(ctx)
 	case "clear":
-		if strings.TrimSpace(ctx.Args().Tail().First()) == "" {
+		sid := strings.TrimSpace(ctx.Args().Tail().First())
+		if age := ctx.String("older-than"); age != "" {
+			olderThan, err := parseAge(age)
+			fatalIf(err.Trace(age), "Unable to parse ‘--older-than’.")
+			if olderThan == 0 {
+				fatalIf(errInvalidArgument().Trace(age), "‘--older-than’ expects an age larger than 0.")
+			}
+			if sid != "" && sid != "all" {
+				fatalIf(errInvalidArgument().Trace(sid), "‘--older-than’ clears all sessions older than the given age, no session ID is expected.")
+			}
+			break
+		}
+		if sid == "" {
 			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
 		}
 	default:
@@ -165,17 +321,23 @@ func checkSessionSyntax(ctx *cli.Context) {
 
 func setSessionPalette(style string) {
 	console.SetCustomPalette(map[string]*color.Color{
-		"Command":      color.New(color.FgWhite, color.Bold),
-		"SessionID":    color.New(color.FgYellow, color.Bold),
-		"SessionTime":  color.New(color.FgGreen),
-		"ClearSession": color.New(color.FgGreen, color.Bold),
+		"Command":       color.New(color.FgWhite, color.Bold),
+		"SessionID":     color.New(color.FgYellow, color.Bold),
+		"SessionTime":   color.New(color.FgGreen),
+		"ClearSession":  color.New(color.FgGreen, color.Bold),
+		"SessionFailed": color.New(color.FgRed, color.Bold),
+		"SessionActive": color.New(color.FgCyan, color.Bold),
+		"Remove":        color.New(color.FgGreen, color.Bold),
 	})
 	if style == "light" {
 		console.SetCustomPalette(map[string]*color.Color{
-			"Command":      color.New(color.FgWhite, color.Bold),
-			"SessionID":    color.New(color.FgWhite, color.Bold),
-			"SessionTime":  color.New(color.FgWhite, color.Bold),
-			"ClearSession": color.New(color.FgWhite, color.Bold),
+			"Command":       color.New(color.FgWhite, color.Bold),
+			"SessionID":     color.New(color.FgWhite, color.Bold),
+			"SessionTime":   color.New(color.FgWhite, color.Bold),
+			"ClearSession":  color.New(color.FgWhite, color.Bold),
+			"SessionFailed": color.New(color.FgWhite, color.Bold),
+			"SessionActive": color.New(color.FgWhite, color.Bold),
+			"Remove":        color.New(color.FgWhite, color.Bold),
 		})
 		return
 	}
@@ -190,50 +352,43 @@ func mainSession(ctx *cli.Context) {
 	checkSessionSyntax(ctx)
 
 	setSessionPalette(ctx.GlobalString("colors"))
+	setReportPalette(ctx.GlobalString("colors"))
 
 	if !isSessionDirExists() {
 		fatalIf(createSessionDir().Trace(), "Unable to create session folder.")
 	}
+	errorIf(expireSessions(ctx.String("session-expiry")).Trace(), "Unable to expire abandoned sessions.")
 
 	switch strings.TrimSpace(ctx.Args().First()) {
 	// list resumable sessions
 	case "list":
 		fatalIf(listSessions().Trace(), "Unable to list sessions.")
 	case "resume":
+		resumeSession(strings.TrimSpace(ctx.Args().Tail().First()), ctx.Bool("failed-only"), ctx.String("report"))
+	case "retry":
+		resumeSession(strings.TrimSpace(ctx.Args().Tail().First()), ctx.Bool("failed-only"), ctx.String("report"))
+	case "pause":
+		pauseSession(strings.TrimSpace(ctx.Args().Tail().First()))
+	case "info":
 		sid := strings.TrimSpace(ctx.Args().Tail().First())
 		if !isSession(sid) {
 			fatalIf(errDummy().Trace(), "Session ‘"+sid+"’ not found.")
 		}
-
-		s, err := loadSessionV2(sid)
-		fatalIf(err.Trace(sid), "Unable to load session.")
-
-		// extra check for testing purposes
-		if s == nil {
-			return
-		}
-
-		savedCwd, e := os.Getwd()
-		fatalIf(probe.NewError(e), "Unable to verify your current working folder.")
-
-		if s.Header.RootPath != "" {
-			// chdir to RootPath
-			e = os.Chdir(s.Header.RootPath)
-			fatalIf(probe.NewError(e), "Unable to change our folder to root path while resuming session.")
+		info, err := sessionInfo(sid)
+		fatalIf(err.Trace(sid), "Unable to load session ‘"+sid+"’.")
+		Prints("%s\n", info)
+	case "export":
+		sid := strings.TrimSpace(ctx.Args().Tail().First())
+		if !isSession(sid) {
+			fatalIf(errDummy().Trace(), "Session ‘"+sid+"’ not found.")
 		}
-		sessionExecute(s)
-		err = s.Close()
-		fatalIf(err.Trace(), "Unable to close session file properly.")
-
-		err = s.Delete()
-		fatalIf(err.Trace(), "Unable to clear session files properly.")
-
-		// chdir back to saved path
-		e = os.Chdir(savedCwd)
-		fatalIf(probe.NewError(e), "Unable to change our folder to saved path ‘"+savedCwd+"’.")
-
+		export, err := sessionExport(sid)
+		fatalIf(err.Trace(sid), "Unable to load session ‘"+sid+"’.")
+		Prints("%s\n", export)
 	// purge a requested pending session, if "all" purge everything
 	case "clear":
-		clearSession(strings.TrimSpace(ctx.Args().Tail().First()))
+		// Age is validated by checkSessionSyntax, it is 0 if not set.
+		olderThan, _ := parseAge(ctx.String("older-than"))
+		clearSession(strings.TrimSpace(ctx.Args().Tail().First()), olderThan)
 	}
 }
@@ -0,0 +1,295 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// status of objects in a session
+const (
+	sessionObjectPending = "pending"
+	sessionObjectDone    = "done"
+	sessionObjectFailed  = "failed"
+)
+
+// sessionObjectStatus is appended to the session status file whenever an object
+// of a session is done or failed, later entries for an index override earlier ones.
+// The status file is the completion log resumed sessions skip done objects by.
+// Entries are keyed by the line index in the session data file since the same
+// source may be planned more than once, e.g. copied to several targets.
+type sessionObjectStatus struct {
+	Index  int    `json:"index"`
+	Source string `json:"source"`
+	Size   int64  `json:"size"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SetObjectStatus records the outcome of transferring source, planned at line index of the session data.
+func (s *sessionV2) SetObjectStatus(index int, source string, size int64, err *probe.Error) *probe.Error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.StatusFP == nil {
+		sessionStatusFile, err := getSessionStatusFile(s.SessionID)
+		if err != nil {
+			return err.Trace(s.SessionID)
+		}
+		statusFile, e := os.OpenFile(sessionStatusFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if e != nil {
+			return probe.NewError(e)
+		}
+		s.StatusFP = &sessionDataFP{false, statusFile}
+	}
+	objectStatus := sessionObjectStatus{Index: index, Source: source, Size: size, Status: sessionObjectDone}
+	if err != nil {
+		objectStatus.Status = sessionObjectFailed
+		objectStatus.Error = err.ToGoError().Error()
+	}
+	statusBytes, e := json.Marshal(objectStatus)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if _, e = fmt.Fprintln(s.StatusFP, string(statusBytes)); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// ObjectStatus provides the latest recorded status of each object of this session by line index.
+func (s *sessionV2) ObjectStatus() (map[int]sessionObjectStatus, *probe.Error) {
+	sessionStatusFile, err := getSessionStatusFile(s.SessionID)
+	if err != nil {
+		return nil, err.Trace(s.SessionID)
+	}
+	statuses := make(map[int]sessionObjectStatus)
+	statusFile, e := os.Open(sessionStatusFile)
+	if os.IsNotExist(e) {
+		return statuses, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	defer statusFile.Close()
+
+	scanner := bufio.NewScanner(statusFile)
+	for scanner.Scan() {
+		var objectStatus sessionObjectStatus
+		// an interrupted write leaves a partial last line, that object is pending.
+		if json.Unmarshal(scanner.Bytes(), &objectStatus) != nil {
+			continue
+		}
+		statuses[objectStatus.Index] = objectStatus
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return statuses, nil
+}
+
+// Failures provides the number of objects whose last transfer failed.
+func (s *sessionV2) Failures() (int, *probe.Error) {
+	statuses, err := s.ObjectStatus()
+	if err != nil {
+		return 0, err.Trace(s.SessionID)
+	}
+	failures := 0
+	for _, objectStatus := range statuses {
+		if objectStatus.Status == sessionObjectFailed {
+			failures++
+		}
+	}
+	return failures, nil
+}
+
+// sessionObject is an object of the session plan along with its status.
+type sessionObject struct {
+	Source  string   `json:"source"`
+	Targets []string `json:"targets"`
+	Size    int64    `json:"size"`
+	Status  string   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Objects provides the planned objects of this session in scan order along with their status.
+func (s *sessionV2) Objects() ([]sessionObject, *probe.Error) {
+	statuses, err := s.ObjectStatus()
+	if err != nil {
+		return nil, err.Trace(s.SessionID)
+	}
+	var objects []sessionObject
+	scanner := bufio.NewScanner(s.NewDataReader())
+	for index := 0; scanner.Scan(); index++ {
+		// session data holds either copyURLs or mirrorURLs.
+		var sURLs struct {
+			copyURLs
+			TargetContents []*struct{ Name string }
+		}
+		if e := json.Unmarshal(scanner.Bytes(), &sURLs); e != nil {
+			return nil, probe.NewError(e)
+		}
+		if sURLs.SourceContent == nil {
+			continue
+		}
+		object := sessionObject{
+			Source: sURLs.SourceContent.Name,
+			Size:   sURLs.SourceContent.Size,
+			Status: sessionObjectPending,
+		}
+		if sURLs.TargetContent != nil {
+			object.Targets = append(object.Targets, sURLs.TargetContent.Name)
+		}
+		for _, targetContent := range sURLs.TargetContents {
+			object.Targets = append(object.Targets, targetContent.Name)
+		}
+		if objectStatus, ok := statuses[index]; ok {
+			object.Status = objectStatus.Status
+			object.Error = objectStatus.Error
+		}
+		objects = append(objects, object)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return objects, nil
+}
+
+// SessionInfoMessage container for session progress and failures
+type SessionInfoMessage struct {
+	SessionID      string          `json:"sessionid"`
+	Time           time.Time       `json:"time"`
+	CommandType    string          `json:"command-type"`
+	CommandArgs    []string        `json:"command-args"`
+	TotalObjects   int             `json:"total-objects"`
+	TotalBytes     int64           `json:"total-bytes"`
+	DoneObjects    int             `json:"done-objects"`
+	DoneBytes      int64           `json:"done-bytes"`
+	FailedObjects  int             `json:"failed-objects"`
+	PendingObjects int             `json:"pending-objects"`
+	Failures       []sessionObject `json:"failures,omitempty"`
+}
+
+// String colorized session info message
+func (s SessionInfoMessage) String() string {
+	message := console.Colorize("SessionID", fmt.Sprintf("%s -> ", s.SessionID))
+	message += console.Colorize("SessionTime", fmt.Sprintf("[%s]", s.Time.Local().Format(printDate)))
+	message += console.Colorize("Command", fmt.Sprintf(" %s %s\n", s.CommandType, strings.Join(s.CommandArgs, " ")))
+	progress := 0.0
+	if s.TotalBytes > 0 {
+		progress = float64(s.DoneBytes) * 100 / float64(s.TotalBytes)
+	}
+	message += fmt.Sprintf("%-10s: %d/%d object(s), %s/%s (%.1f%%)\n", "Done", s.DoneObjects, s.TotalObjects,
+		humanize.IBytes(uint64(s.DoneBytes)), humanize.IBytes(uint64(s.TotalBytes)), progress)
+	message += fmt.Sprintf("%-10s: %d object(s)\n", "Pending", s.PendingObjects)
+	message += console.Colorize("SessionFailed", fmt.Sprintf("%-10s: %d object(s)", "Failed", s.FailedObjects))
+	for _, failure := range s.Failures {
+		message += "\n" + console.Colorize("SessionFailed", fmt.Sprintf("  %s: %s", failure.Source, failure.Error))
+	}
+	return message
+}
+
+// JSON jsonified session info message
+func (s SessionInfoMessage) JSON() string {
+	sessionInfoBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(sessionInfoBytes)
+}
+
+// SessionExportMessage container for the plan and status of a session
+type SessionExportMessage struct {
+	SessionID string           `json:"sessionid"`
+	Header    *sessionV2Header `json:"header"`
+	Objects   []sessionObject  `json:"objects"`
+}
+
+// String session export is always JSON
+func (s SessionExportMessage) String() string {
+	return s.JSON()
+}
+
+// JSON jsonified session export message
+func (s SessionExportMessage) JSON() string {
+	sessionExportBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(sessionExportBytes)
+}
+
+// sessionInfo summarizes progress and failures of session sid.
+func sessionInfo(sid string) (SessionInfoMessage, *probe.Error) {
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		return SessionInfoMessage{}, err.Trace(sid)
+	}
+	defer s.Close()
+
+	objects, err := s.Objects()
+	if err != nil {
+		return SessionInfoMessage{}, err.Trace(sid)
+	}
+	info := SessionInfoMessage{
+		SessionID:    s.SessionID,
+		Time:         s.Header.When,
+		CommandType:  s.Header.CommandType,
+		CommandArgs:  s.Header.CommandArgs,
+		TotalObjects: s.Header.TotalObjects,
+		TotalBytes:   s.Header.TotalBytes,
+	}
+	for _, object := range objects {
+		switch object.Status {
+		case sessionObjectDone:
+			info.DoneObjects++
+			info.DoneBytes += object.Size
+		case sessionObjectFailed:
+			info.FailedObjects++
+			info.Failures = append(info.Failures, object)
+		default:
+			info.PendingObjects++
+		}
+	}
+	return info, nil
+}
+
+// sessionExport provides the plan and status of session sid.
+func sessionExport(sid string) (SessionExportMessage, *probe.Error) {
+	s, err := loadSessionV2(sid)
+	if err != nil {
+		return SessionExportMessage{}, err.Trace(sid)
+	}
+	defer s.Close()
+
+	objects, err := s.Objects()
+	if err != nil {
+		return SessionExportMessage{}, err.Trace(sid)
+	}
+	return SessionExportMessage{
+		SessionID: s.SessionID,
+		Header:    s.Header,
+		Objects:   objects,
+	}, nil
+}
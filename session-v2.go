@@ -22,7 +22,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -51,9 +53,16 @@ type sessionV2Header struct {
 	RootPath     string    `json:"working-folder"`
 	CommandType  string    `json:"command-type"`
 	CommandArgs  []string  `json:"cmd-args"`
-	LastCopied   string    `json:"last-copied"`
+	Tags         string    `json:"tags,omitempty"`
+	StorageClass string    `json:"storage-class,omitempty"`
+	Include      []string  `json:"include,omitempty"`
+	Exclude      []string  `json:"exclude,omitempty"`
+	FromFile     string    `json:"from-file,omitempty"`
 	TotalBytes   int64     `json:"total-bytes"`
 	TotalObjects int       `json:"total-objects"`
+
+	// LastCopied is only set by older versions, progress is tracked in the status file.
+	LastCopied string `json:"last-copied,omitempty"`
 }
 
 // SessionMessage container for session messages
@@ -62,6 +71,8 @@ type SessionMessage struct {
 	Time        time.Time `json:"time"`
 	CommandType string    `json:"command-type"`
 	CommandArgs []string  `json:"command-args"`
+	Active      bool      `json:"active"`
+	PID         int       `json:"pid,omitempty"`
 }
 
 // sessionV2
@@ -70,7 +81,20 @@ type sessionV2 struct {
 	SessionID string
 	mutex     *sync.Mutex
 	DataFP    *sessionDataFP
+	StatusFP  *sessionDataFP
 	sigCh     bool
+	lock      *sessionLock
+
+	// activePID is the process running this session, as listed by ‘session list’.
+	activePID int
+
+	// failedOnly transfers only objects which failed before.
+	failedOnly bool
+	// queue limits concurrent transfers, it is shared by the sessions of a batch.
+	queue chan bool
+	// batch marks sessions run next to each other by a batch, they show no progress
+	// and do not stop the process when their transfers fail.
+	batch bool
 }
 
 type sessionDataFP struct {
@@ -88,6 +112,9 @@ func (s sessionV2) String() string {
 	message := console.Colorize("SessionID", fmt.Sprintf("%s -> ", s.SessionID))
 	message = message + console.Colorize("SessionTime", fmt.Sprintf("[%s]", s.Header.When.Local().Format(printDate)))
 	message = message + console.Colorize("Command", fmt.Sprintf(" %s %s", s.Header.CommandType, strings.Join(s.Header.CommandArgs, " ")))
+	if s.activePID != 0 {
+		message = message + console.Colorize("SessionActive", fmt.Sprintf(" (active, PID %d)", s.activePID))
+	}
 	return message
 }
 
@@ -98,6 +125,8 @@ func (s sessionV2) JSON() string {
 		Time:        s.Header.When.Local(),
 		CommandType: s.Header.CommandType,
 		CommandArgs: s.Header.CommandArgs,
+		Active:      s.activePID != 0,
+		PID:         s.activePID,
 	}
 	sessionBytes, e := json.Marshal(sessionMesage)
 	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
@@ -116,6 +145,10 @@ func newSessionV2() *sessionV2 {
 	s.mutex = new(sync.Mutex)
 	s.SessionID = newRandomID(8)
 
+	var perr *probe.Error
+	s.lock, perr = lockSession(s.SessionID)
+	fatalIf(perr.Trace(s.SessionID), "Unable to lock session ‘"+s.SessionID+"’.")
+
 	sessionDataFile, perr := getSessionDataFile(s.SessionID)
 	fatalIf(perr.Trace(s.SessionID), "Unable to create session data file \""+sessionDataFile+"\".")
 
@@ -139,12 +172,88 @@ func gracefulSessionSave(session *sessionV2) {
 
 // HasData provides true if this is a session resume, false otherwise.
 func (s sessionV2) HasData() bool {
-	if s.Header.LastCopied == "" {
+	if s.Header.LastCopied == "" && s.Header.TotalObjects == 0 {
 		return false
 	}
 	return true
 }
 
+// NewSkipFilter provides a function reporting whether an object is skipped on this run.
+// Objects recorded as done in the status file are skipped, no matter in which order
+// concurrent transfers completed. Objects are identified by their line index in the
+// session data file. If only failed objects are retried everything else is skipped.
+func (s *sessionV2) NewSkipFilter() (func(index int, sourceURL string) bool, *probe.Error) {
+	statuses, err := s.ObjectStatus()
+	if err != nil {
+		return nil, err.Trace(s.SessionID)
+	}
+	// Sessions saved by older versions only know the last copied object.
+	if len(statuses) == 0 && s.Header.LastCopied != "" {
+		isCopied := isCopiedFactory(s.Header.LastCopied)
+		return func(index int, sourceURL string) bool {
+			return isCopied(sourceURL)
+		}, nil
+	}
+	return func(index int, sourceURL string) bool {
+		objectStatus, ok := statuses[index]
+		if s.failedOnly {
+			return !ok || objectStatus.Status != sessionObjectFailed
+		}
+		return ok && objectStatus.Status == sessionObjectDone
+	}, nil
+}
+
+// TransferQueue provides the queue limiting concurrent transfers of this session.
+func (s *sessionV2) TransferQueue() chan bool {
+	if s.queue == nil {
+		// Limit number of transfer routines based on available CPU resources.
+		s.queue = make(chan bool, int(math.Max(float64(runtime.NumCPU())-1, 1)))
+	}
+	return s.queue
+}
+
+// showProgress reports whether progress bars are shown while the session runs.
+func (s *sessionV2) showProgress() bool {
+	return !s.batch && !globalQuietFlag && !globalJSONFlag
+}
+
+// IsFiltered reports whether the object at objectURL is left out by the include and
+// exclude patterns of this session. Patterns are matched against the object name
+// relative to the command argument it was found below.
+func (h sessionV2Header) IsFiltered(objectURL string) bool {
+	if len(h.Include) == 0 && len(h.Exclude) == 0 {
+		return false
+	}
+	name, root := objectURL, ""
+	for _, arg := range h.CommandArgs {
+		arg = stripRecursiveURL(arg)
+		if len(arg) > len(root) && strings.HasPrefix(objectURL, arg) {
+			root = arg
+			name = strings.TrimLeft(strings.TrimPrefix(objectURL, arg), "/\\")
+		}
+	}
+	return !matchFilters(name, h.Include, h.Exclude)
+}
+
+// Finish removes the session once it is complete, sessions with failed objects are kept for a retry.
+func (s *sessionV2) Finish() *probe.Error {
+	failures, err := s.Failures()
+	if err != nil {
+		return err.Trace(s.SessionID)
+	}
+	if failures == 0 {
+		return s.Delete().Trace(s.SessionID)
+	}
+	if err = s.Close(); err != nil {
+		return err.Trace(s.SessionID)
+	}
+	// Batches list the sessions to retry along with their jobs.
+	if !s.batch {
+		console.Infof("%d object(s) failed. To retry them ‘mc session retry %s’\n", failures, s.SessionID)
+	}
+	return nil
+}
+
 // NewDataReader provides reader interface to session data file.
 func (s *sessionV2) NewDataReader() io.Reader {
 	// DataFP is always intitialized, either via new or load functions.
@@ -170,6 +279,12 @@ func (s *sessionV2) Save() *probe.Error {
 		}
 		s.DataFP.dirty = false
 	}
+	if s.StatusFP != nil && s.StatusFP.dirty {
+		if err := s.StatusFP.Sync(); err != nil {
+			return probe.NewError(err)
+		}
+		s.StatusFP.dirty = false
+	}
 
 	qs, err := quick.New(s.Header)
 	if err != nil {
@@ -191,6 +306,16 @@ func (s *sessionV2) Close() *probe.Error {
 	if err := s.DataFP.Close(); err != nil {
 		return probe.NewError(err)
 	}
+	if s.StatusFP != nil {
+		if err := s.StatusFP.Close(); err != nil {
+			return probe.NewError(err)
+		}
+		s.StatusFP = nil
+	}
+	// Sessions loaded for inspection are not locked, only their owner saves them.
+	if s.lock == nil {
+		return nil
+	}
 
 	qs, err := quick.New(s.Header)
 	if err != nil {
@@ -201,14 +326,31 @@ func (s *sessionV2) Close() *probe.Error {
 	if err != nil {
 		return err.Trace(s.SessionID)
 	}
-	return qs.Save(sessionFile).Trace()
+	if err := qs.Save(sessionFile); err != nil {
+		return err.Trace(s.SessionID)
+	}
+	err = s.lock.Unlock()
+	s.lock = nil
+	return err
 }
 
-// Delete removes all the session files.
+// Delete removes all the session files, sessions running in other processes are not removed.
 func (s *sessionV2) Delete() *probe.Error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.lock == nil {
+		lock, err := lockSession(s.SessionID)
+		if err != nil {
+			return err.Trace(s.SessionID)
+		}
+		s.lock = lock
+	}
+	defer func() {
+		s.lock.Unlock()
+		s.lock = nil
+	}()
+
 	if s.DataFP != nil {
 		name := s.DataFP.Name()
 		// close file pro-actively before deleting
@@ -222,6 +364,18 @@ func (s *sessionV2) Delete() *probe.Error {
 		}
 	}
 
+	if s.StatusFP != nil {
+		s.StatusFP.Close()
+		s.StatusFP = nil
+	}
+	sessionStatusFile, err := getSessionStatusFile(s.SessionID)
+	if err != nil {
+		return err.Trace(s.SessionID)
+	}
+	if err := os.Remove(sessionStatusFile); err != nil && !os.IsNotExist(err) {
+		return probe.NewError(err)
+	}
+
 	sessionFile, err := getSessionFile(s.SessionID)
 	if err != nil {
 		return err.Trace(s.SessionID)
@@ -231,6 +385,13 @@ func (s *sessionV2) Delete() *probe.Error {
 		return probe.NewError(err)
 	}
 
+	sessionLockFile, err := getSessionLockFile(s.SessionID)
+	if err != nil {
+		return err.Trace(s.SessionID)
+	}
+	if err := os.Remove(sessionLockFile); err != nil && !os.IsNotExist(err) {
+		return probe.NewError(err)
+	}
 	return nil
 }
 
@@ -279,7 +440,8 @@ func loadSessionV2(sid string) (*sessionV2, *probe.Error) {
 }
 
 // Create a factory function to simplify checking if an
-// object has been copied or not.
+// object has been copied or not, for sessions saved by
+// older versions.
 // isCopied(URL) -> true or false
 func isCopiedFactory(lastCopied string) func(string) bool {
 	copied := true // closure
@@ -92,6 +92,26 @@ func getSessionDataFile(sid string) (string, *probe.Error) {
 	return sessionDataFile, nil
 }
 
+func getSessionStatusFile(sid string) (string, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+
+	sessionStatusFile := filepath.Join(sessionDir, sid+".status")
+	return sessionStatusFile, nil
+}
+
+func getSessionLockFile(sid string) (string, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+
+	sessionLockFile := filepath.Join(sessionDir, sid+".lock")
+	return sessionLockFile, nil
+}
+
 func getSessionIDs() (sids []string) {
 	sessionDir, err := getSessionDir()
 	fatalIf(err.Trace(), "Unable to access session folder.")
@@ -17,10 +17,19 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"time"
 
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
 	. "gopkg.in/check.v1"
 )
 
@@ -106,3 +115,304 @@ func (s *TestSuite) TestSessionContext(c *C) {
 	// reset back
 	console.IsExited = false
 }
+
+func (s *TestSuite) TestSessionStatus(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+
+	session := newSessionV2()
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{"source...", "target"}
+	dataFP := session.NewDataWriter()
+	for _, name := range []string{"object1", "object2", "object3"} {
+		jsonData, e := json.Marshal(copyURLs{
+			SourceContent: &client.Content{Name: "source/" + name, Size: 10},
+			TargetContent: &client.Content{Name: "target/" + name},
+		})
+		c.Assert(e, IsNil)
+		fmt.Fprintln(dataFP, string(jsonData))
+	}
+	session.Header.TotalObjects = 3
+	session.Header.TotalBytes = 30
+	c.Assert(session.Save(), IsNil)
+
+	c.Assert(session.SetObjectStatus(0, "source/object1", 10, nil), IsNil)
+	c.Assert(session.SetObjectStatus(2, "source/object3", 10, probe.NewError(errors.New("connection reset"))), IsNil)
+	c.Assert(session.Save(), IsNil)
+	failures, perr := session.Failures()
+	c.Assert(perr, IsNil)
+	c.Assert(failures, Equals, 1)
+
+	// sessions with failures are kept for a retry
+	c.Assert(session.Finish(), IsNil)
+	c.Assert(isSession(session.SessionID), Equals, true)
+
+	info, perr := sessionInfo(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(info.DoneObjects, Equals, 1)
+	c.Assert(info.DoneBytes, Equals, int64(10))
+	c.Assert(info.PendingObjects, Equals, 1)
+	c.Assert(info.FailedObjects, Equals, 1)
+	c.Assert(info.Failures[0].Source, Equals, "source/object3")
+	c.Assert(info.Failures[0].Error, Equals, "connection reset")
+
+	export, perr := sessionExport(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(export.Objects, HasLen, 3)
+	c.Assert(export.Objects[1].Targets, DeepEquals, []string{"target/object2"})
+	c.Assert(export.Objects[1].Status, Equals, sessionObjectPending)
+	c.Assert(export.Header.CommandArgs, DeepEquals, []string{"source...", "target"})
+
+	session, perr = loadSessionV2(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(session.HasData(), Equals, true)
+	skip := func() (skipped []string) {
+		isCopied, perr := session.NewSkipFilter()
+		c.Assert(perr, IsNil)
+		for index, object := range export.Objects {
+			if isCopied(index, object.Source) {
+				skipped = append(skipped, object.Source)
+			}
+		}
+		return skipped
+	}
+	c.Assert(skip(), DeepEquals, []string{"source/object1"})
+	session.failedOnly = true
+	c.Assert(skip(), DeepEquals, []string{"source/object1", "source/object2"})
+
+	// a successful retry clears the failure
+	c.Assert(session.SetObjectStatus(2, "source/object3", 10, nil), IsNil)
+	failures, perr = session.Failures()
+	c.Assert(perr, IsNil)
+	c.Assert(failures, Equals, 0)
+
+	for _, command := range []string{"info", "export"} {
+		err := app.Run([]string{os.Args[0], "session", command, session.SessionID})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, false)
+	}
+
+	// only running sessions can be paused
+	err := app.Run([]string{os.Args[0], "session", "pause", session.SessionID})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+
+	c.Assert(session.Finish(), IsNil)
+	c.Assert(isSession(session.SessionID), Equals, false)
+}
+
+func (s *TestSuite) TestSessionResumeOutOfOrder(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	sourceDir, targetDir := c.MkDir(), c.MkDir()
+
+	session := newSessionV2()
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{sourceDir + "...", targetDir}
+	dataFP := session.NewDataWriter()
+	for _, name := range []string{"object1", "object2", "object3"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0600), IsNil)
+		jsonData, e := json.Marshal(copyURLs{
+			SourceContent: &client.Content{Name: filepath.Join(sourceDir, name), Size: int64(len(name))},
+			TargetContent: &client.Content{Name: filepath.Join(targetDir, name)},
+		})
+		c.Assert(e, IsNil)
+		fmt.Fprintln(dataFP, string(jsonData))
+	}
+	session.Header.TotalObjects = 3
+	session.Header.TotalBytes = 21
+	// object3 completed while object1 and object2 were still in flight when
+	// the session was interrupted, with a single marker all three were skipped.
+	session.Header.LastCopied = filepath.Join(sourceDir, "object3")
+	c.Assert(session.SetObjectStatus(2, filepath.Join(sourceDir, "object3"), 7, nil), IsNil)
+	c.Assert(session.Close(), IsNil)
+
+	session, perr := loadSessionV2(session.SessionID)
+	c.Assert(perr, IsNil)
+	_, perr = doCopySession(session)
+	c.Assert(perr, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	for _, name := range []string{"object1", "object2"} {
+		data, e := ioutil.ReadFile(filepath.Join(targetDir, name))
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, name)
+	}
+	// done objects are not copied again
+	_, e := os.Stat(filepath.Join(targetDir, "object3"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+
+	info, perr := sessionInfo(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(info.DoneObjects, Equals, 3)
+	c.Assert(session.Finish(), IsNil)
+	c.Assert(isSession(session.SessionID), Equals, false)
+}
+
+func (s *TestSuite) TestSessionResumeDuplicateSource(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+	sourceDir, targetDir := c.MkDir(), c.MkDir()
+	sourceURL := filepath.Join(sourceDir, "object")
+	c.Assert(ioutil.WriteFile(sourceURL, []byte("object"), 0600), IsNil)
+
+	// the same source is copied to two targets.
+	session := newSessionV2()
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = []string{sourceURL, targetDir}
+	dataFP := session.NewDataWriter()
+	for _, name := range []string{"target1", "target2"} {
+		jsonData, e := json.Marshal(copyURLs{
+			SourceContent: &client.Content{Name: sourceURL, Size: 6},
+			TargetContent: &client.Content{Name: filepath.Join(targetDir, name)},
+		})
+		c.Assert(e, IsNil)
+		fmt.Fprintln(dataFP, string(jsonData))
+	}
+	session.Header.TotalObjects = 2
+	session.Header.TotalBytes = 12
+	c.Assert(session.SetObjectStatus(0, sourceURL, 6, nil), IsNil)
+	c.Assert(session.Close(), IsNil)
+
+	session, perr := loadSessionV2(session.SessionID)
+	c.Assert(perr, IsNil)
+	_, perr = doCopySession(session)
+	c.Assert(perr, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	// only the first copy is done, the second one is not skipped.
+	_, e := os.Stat(filepath.Join(targetDir, "target1"))
+	c.Assert(os.IsNotExist(e), Equals, true)
+	data, e := ioutil.ReadFile(filepath.Join(targetDir, "target2"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "object")
+
+	info, perr := sessionInfo(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(info.DoneObjects, Equals, 2)
+	c.Assert(session.Finish(), IsNil)
+	c.Assert(isSession(session.SessionID), Equals, false)
+}
+
+func (s *TestSuite) TestSessionLock(c *C) {
+	c.Assert(createSessionDir(), IsNil)
+
+	session := newSessionV2()
+	c.Assert(session.Save(), IsNil)
+	c.Assert(sessionOwner(session.SessionID), Equals, os.Getpid())
+	_, perr := lockSession(session.SessionID)
+	c.Assert(perr, NotNil)
+	c.Assert(perr.ToGoError().Error(), Equals, "Session ‘"+session.SessionID+"’ in use by PID "+strconv.Itoa(os.Getpid())+".")
+
+	// running sessions can neither be resumed nor cleared
+	err := app.Run([]string{os.Args[0], "session", "resume", session.SessionID})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+	inspected, perr := loadSessionV2(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(inspected.Delete(), NotNil)
+	c.Assert(isSession(session.SessionID), Equals, true)
+	c.Assert(listSessions(), IsNil)
+
+	c.Assert(session.Close(), IsNil)
+	c.Assert(sessionOwner(session.SessionID), Equals, 0)
+
+	// a lock left behind by a process which is gone is taken over
+	lockFile, perr := getSessionLockFile(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(ioutil.WriteFile(lockFile, []byte("2147483646"), 0600), IsNil)
+	c.Assert(sessionOwner(session.SessionID), Equals, 0)
+	lock, perr := lockSession(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(lock.Unlock(), IsNil)
+
+	session, perr = loadSessionV2(session.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(session.Delete(), IsNil)
+	_, e := os.Stat(lockFile)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
+
+func (s *TestSuite) TestSessionExpiry(c *C) {
+	for age, duration := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour, "0": 0} {
+		parsed, perr := parseAge(age)
+		c.Assert(perr, IsNil)
+		c.Assert(parsed, Equals, duration)
+	}
+	for _, age := range []string{"", "d", "-1h", "7days"} {
+		_, perr := parseAge(age)
+		c.Assert(perr, NotNil)
+	}
+
+	c.Assert(createSessionDir(), IsNil)
+	abandoned := newSessionV2()
+	c.Assert(abandoned.Close(), IsNil)
+	sessionFile, perr := getSessionFile(abandoned.SessionID)
+	c.Assert(perr, IsNil)
+	weekAgo := time.Now().Add(-8 * 24 * time.Hour)
+	c.Assert(os.Chtimes(sessionFile, weekAgo, weekAgo), IsNil)
+	recent := newSessionV2()
+	c.Assert(recent.Close(), IsNil)
+	// running sessions are never expired
+	running := newSessionV2()
+	c.Assert(running.Save(), IsNil)
+	runningFile, perr := getSessionFile(running.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(os.Chtimes(runningFile, weekAgo, weekAgo), IsNil)
+
+	c.Assert(getExpiredSessionIDs(7*24*time.Hour), DeepEquals, []string{abandoned.SessionID})
+	err := app.Run([]string{os.Args[0], "session", "clear", "--older-than", "7d"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(isSession(abandoned.SessionID), Equals, false)
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	c.Assert(isSession(running.SessionID), Equals, true)
+
+	// files of crashed sessions and mirrors are swept along with expired sessions
+	sessionDir, perr := getSessionDir()
+	c.Assert(perr, IsNil)
+	orphanData := filepath.Join(sessionDir, "orphaned.data")
+	c.Assert(ioutil.WriteFile(orphanData, nil, 0600), IsNil)
+	c.Assert(createSortedListDir(), IsNil)
+	sortedListDir, perr := getSortedListDir()
+	c.Assert(perr, IsNil)
+	sortedLists := map[string]bool{
+		"2147483646-crashed.src":   false,
+		newSortedListID() + ".src": true,
+		"legacy.src":               true,
+		"legacyorphan.src":         false,
+	}
+	for name := range sortedLists {
+		c.Assert(ioutil.WriteFile(filepath.Join(sortedListDir, name), nil, 0600), IsNil)
+	}
+	c.Assert(os.Chtimes(filepath.Join(sortedListDir, "legacyorphan.src"), weekAgo, weekAgo), IsNil)
+	recentFile, perr := getSessionFile(recent.SessionID)
+	c.Assert(perr, IsNil)
+	c.Assert(os.Chtimes(recentFile, weekAgo, weekAgo), IsNil)
+
+	// sessions are only expired on request, a bad age is reported
+	c.Assert(expireSessions(""), IsNil)
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	_, e := os.Stat(orphanData)
+	c.Assert(e, IsNil)
+	c.Assert(expireSessions("7days"), NotNil)
+	c.Assert(expireSessions("0"), NotNil)
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	err = app.Run([]string{os.Args[0], "session", "list", "--session-expiry", "0"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+	c.Assert(isSession(recent.SessionID), Equals, true)
+	err = app.Run([]string{os.Args[0], "session", "list", "--session-expiry", "7d"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	c.Assert(isSession(recent.SessionID), Equals, false)
+	c.Assert(isSession(running.SessionID), Equals, true)
+	_, e = os.Stat(orphanData)
+	c.Assert(os.IsNotExist(e), Equals, true)
+	for name, kept := range sortedLists {
+		_, e := os.Stat(filepath.Join(sortedListDir, name))
+		c.Assert(e == nil, Equals, kept, Commentf("%s", name))
+		os.Remove(filepath.Join(sortedListDir, name))
+	}
+	c.Assert(running.Delete(), IsNil)
+}
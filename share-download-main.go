@@ -18,6 +18,12 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/minio/cli"
@@ -30,14 +36,18 @@ var shareDownload = cli.Command{
 	Name:   "download",
 	Usage:  "Share documents via URL.",
 	Action: mainShareDownload,
+	Flags:  []cli.Flag{contentDispositionFlag, contentTypeFlag, formatFlag},
 	CustomHelpTemplate: `NAME:
     mc share {{.Name}} - {{.Usage}}
 
  USAGE:
-    mc share {{.Name}} download TARGET [DURATION]
+    mc share {{.Name}} download [FLAGS] TARGET [DURATION]
 
     DURATION = NN[h|m|s] [DEFAULT=168h]
 
+ FLAGS:
+    {{range .Flags}}{{.}}
+    {{end}}
  EXAMPLES:
     1. Generate URL for sharing, with a default expiry of 7 days.
        $ mc share {{.Name}} https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz
@@ -51,6 +61,12 @@ var shareDownload = cli.Command{
     4. Generate URL with space characters for sharing, with an expiry of 5 seconds.
        $ mc share {{.Name}} s3/miniocloud/nothing-like-anything 5s
 
+    5. Generate URL which makes browsers download the object as ‘backup.tar.gz’ instead of displaying it.
+       $ mc share {{.Name}} --content-disposition attachment https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz
+
+    6. Generate an HTML index page linking all objects of a folder, for handing to external partners.
+       $ mc share {{.Name}} --format html https://s3.amazonaws.com/backup... 120h > index.html
+
 `,
 }
 
@@ -62,6 +78,42 @@ func checkShareDownloadSyntax(ctx *cli.Context) {
 	if len(args) > 2 {
 		cli.ShowCommandHelpAndExit(ctx, "download", 1) // last argument is exit code
 	}
+	if contentDisposition := ctx.String("content-disposition"); contentDisposition != "" {
+		if _, _, e := mime.ParseMediaType(contentDisposition); e != nil {
+			fatalIf(probe.NewError(e).Trace(contentDisposition), "Invalid Content-Disposition ‘"+contentDisposition+"’.")
+		}
+	}
+	if contentType := ctx.String("content-type"); contentType != "" {
+		if _, _, e := mime.ParseMediaType(contentType); e != nil {
+			fatalIf(probe.NewError(e).Trace(contentType), "Invalid Content-Type ‘"+contentType+"’.")
+		}
+	}
+	if format := ctx.String("format"); format != "" && !isShareIndexFormat(format) {
+		fatalIf(errInvalidArgument().Trace(format), fmt.Sprintf("Unknown index format ‘%s’, expected one of ‘%s’.", format, strings.Join(shareIndexFormats, "’, ‘")))
+	}
+}
+
+// shareDownloadOptions - response header overrides and index format of shared download URLs
+type shareDownloadOptions struct {
+	contentDisposition string // ‘attachment’ or ‘inline’ alone are completed with the object name
+	contentType        string
+	format             string // print a single csv, json or html index instead of messages
+}
+
+// reqParams - response header overrides signed into the URL shared for objectName
+func (o shareDownloadOptions) reqParams(objectName string) url.Values {
+	reqParams := make(url.Values)
+	if o.contentDisposition != "" {
+		contentDisposition := o.contentDisposition
+		if disposition, params, e := mime.ParseMediaType(contentDisposition); e == nil && len(params) == 0 {
+			contentDisposition = mime.FormatMediaType(disposition, map[string]string{"filename": path.Base(objectName)})
+		}
+		reqParams.Set("response-content-disposition", contentDisposition)
+	}
+	if o.contentType != "" {
+		reqParams.Set("response-content-type", o.contentType)
+	}
+	return reqParams
 }
 
 func mainShareDownload(ctx *cli.Context) {
@@ -83,13 +135,18 @@ func mainShareDownload(ctx *cli.Context) {
 	setSharePalette(ctx.GlobalString("colors"))
 
 	// if recursive strip off the "..."
-	err := doShareDownloadURL(stripRecursiveURL(targetURL), isURLRecursive(targetURL), expires)
+	options := shareDownloadOptions{
+		contentDisposition: ctx.String("content-disposition"),
+		contentType:        ctx.String("content-type"),
+		format:             ctx.String("format"),
+	}
+	err := doShareDownloadURL(stripRecursiveURL(targetURL), isURLRecursive(targetURL), expires, options)
 	fatalIf(err.Trace(targetURL), "Unable to generate URL for download.")
 	return
 }
 
 // doShareURL share files from target
-func doShareDownloadURL(targetURL string, recursive bool, expires time.Duration) *probe.Error {
+func doShareDownloadURL(targetURL string, recursive bool, expires time.Duration, options shareDownloadOptions) *probe.Error {
 	shareDate := time.Now().UTC()
 	sURLs, err := loadSharedURLsV3()
 	if err != nil {
@@ -106,6 +163,7 @@ func doShareDownloadURL(targetURL string, recursive bool, expires time.Duration)
 	if expires.Seconds() > 604800 {
 		return probe.NewError(errors.New("Too high expires, expiration cannot be larger than 7 days."))
 	}
+	var entries []shareIndexEntry
 	for contentCh := range clnt.List(recursive) {
 		if contentCh.Err != nil {
 			return contentCh.Err.Trace()
@@ -116,29 +174,29 @@ func doShareDownloadURL(targetURL string, recursive bool, expires time.Duration)
 			return err.Trace()
 		}
 		var sharedURL string
-		sharedURL, err = newClnt.ShareDownload(expires)
+		sharedURL, err = newClnt.ShareDownload(expires, options.reqParams(contentCh.Content.Name))
 		if err != nil {
 			return err.Trace()
 		}
-		shareMessage := ShareMessage{
+		shareMessage := addSharedURL(sURLs, shareDate, ShareMessageV3{
 			Expiry:      expires,
 			DownloadURL: sharedURL,
 			Key:         newClnt.URL().String(),
-		}
-		shareMessageV3 := ShareMessageV3{
-			Expiry:      expires,
-			DownloadURL: sharedURL,
-			Key:         newClnt.URL().String(),
-		}
-		sURLs.URLs = append(sURLs.URLs, struct {
-			Date    time.Time
-			Message ShareMessageV3
-		}{
-			Date:    shareDate,
-			Message: shareMessageV3,
 		})
-		Prints("%s\n", shareMessage)
+		if options.format != "" {
+			entries = append(entries, shareIndexEntry{
+				Name:    contentCh.Content.Name,
+				Size:    contentCh.Content.Size,
+				Expires: shareMessage.Expires,
+				URL:     sharedURL,
+			})
+			continue
+		}
+		Prints("%s\n", ShareMessage(shareMessage))
 	}
 	saveSharedURLsV3(sURLs)
+	if options.format != "" {
+		return writeShareIndex(os.Stdout, options.format, entries).Trace(options.format)
+	}
 	return nil
 }
@@ -0,0 +1,99 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio/pkg/probe"
+)
+
+/// share index - related internal functions
+
+// shareIndexFormats - formats of the index written for shared download URLs
+var shareIndexFormats = []string{"csv", "json", "html"}
+
+// shareIndexEntry - a single shared object listed in an index
+type shareIndexEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Expires time.Time `json:"expires"`
+	URL     string    `json:"url"`
+}
+
+// shareIndexHTML - a standalone page linking all shared objects
+var shareIndexHTML = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Shared files</title>
+</head>
+<body>
+<table>
+<tr><th>Name</th><th>Size</th><th>Expires</th></tr>
+{{range .}}<tr><td><a href="{{.URL}}">{{.Name}}</a></td><td>{{.Size}}</td><td>{{.Expires.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// isShareIndexFormat - verify if format is a known index format
+func isShareIndexFormat(format string) bool {
+	for _, indexFormat := range shareIndexFormats {
+		if format == indexFormat {
+			return true
+		}
+	}
+	return false
+}
+
+// writeShareIndex - write entries to w as a csv, json or html index
+func writeShareIndex(w io.Writer, format string, entries []shareIndexEntry) *probe.Error {
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if e := writer.Write([]string{"name", "size", "expires", "url"}); e != nil {
+			return probe.NewError(e)
+		}
+		for _, entry := range entries {
+			record := []string{entry.Name, strconv.FormatInt(entry.Size, 10), entry.Expires.Format(time.RFC3339), entry.URL}
+			if e := writer.Write(record); e != nil {
+				return probe.NewError(e)
+			}
+		}
+		writer.Flush()
+		return probe.NewError(writer.Error())
+	case "json":
+		// an empty index is an empty list, not null
+		if entries == nil {
+			entries = []shareIndexEntry{}
+		}
+		// keep ‘&’ in URLs readable, like share messages do
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", " ")
+		return probe.NewError(encoder.Encode(entries))
+	case "html":
+		return probe.NewError(shareIndexHTML.Execute(w, entries))
+	}
+	return errInvalidArgument().Trace(format)
+}
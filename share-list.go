@@ -30,38 +30,59 @@ var shareList = cli.Command{
 	Name:   "list",
 	Usage:  "List the shared URLs",
 	Action: mainShareList,
+	Flags:  []cli.Flag{expiredFlag, activeFlag},
 	CustomHelpTemplate: `NAME:
    mc share {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc share {{.Name}}
+   mc share {{.Name}} [FLAGS]
 
+   Expired URLs are listed for 30 days after their expiry, then they are removed.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
-   $ mc share {{.Name}}
+   1. List all shared URLs along with their IDs.
+      $ mc share {{.Name}}
+
+   2. List shared URLs which can still be used.
+      $ mc share {{.Name}} --active
+
+   3. List shared URLs which have expired.
+      $ mc share {{.Name}} --expired
 
 `,
 }
 
+func checkShareListSyntax(ctx *cli.Context) {
+	if ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "list", 1) // last argument is exit code
+	}
+	if ctx.Bool("expired") && ctx.Bool("active") {
+		fatalIf(errInvalidArgument().Trace(), "Only one of ‘--expired’ and ‘--active’ can be used.")
+	}
+}
+
 func mainShareList(ctx *cli.Context) {
 	shareDataDirSetup()
+	checkShareListSyntax(ctx)
 	setSharePalette(ctx.GlobalString("colors"))
-	err := doShareList()
+	err := doShareList(ctx.Bool("expired"), ctx.Bool("active"))
 	fatalIf(err.Trace(), "Unable to list shared URLs.")
 }
 
-// doShareList list shared url's
-func doShareList() *probe.Error {
+// doShareList list shared url's, only expired or only active ones if requested
+func doShareList(expired, active bool) *probe.Error {
 	sURLs, err := loadSharedURLsV3()
 	if err != nil {
 		return err.Trace()
 	}
-	saveList := newSharedURLsV3()
 	for _, data := range sURLs.URLs {
-		if time.Since(data.Date) > data.Message.Expiry {
+		isExpired := data.Message.isExpired()
+		if (expired && !isExpired) || (active && isExpired) {
 			continue
 		}
-		saveList.URLs = append(saveList.URLs, data)
-		expiry := data.Message.Expiry - time.Since(data.Date)
 		if !globalJSONFlag {
 			var kind string
 			if len(data.Message.DownloadURL) > 0 {
@@ -69,10 +90,17 @@ func doShareList() *probe.Error {
 			} else {
 				kind = "Upload"
 			}
-			msg := console.Colorize("Share", "Name: ")
+			msg := console.Colorize("Share", "ID: ")
+			msg += console.Colorize("ID", data.Message.ID+"\n")
+			msg += console.Colorize("Share", "Name: ")
 			msg += console.Colorize("URL", data.Message.Key+" ("+kind+")\n")
-			msg += console.Colorize("Share", "Expiry: ")
-			msg += console.Colorize("Expires", timeDurationToHumanizedTime(expiry))
+			if isExpired {
+				msg += console.Colorize("Share", "Expired: ")
+				msg += console.Colorize("Expires", timeDurationToHumanizedTime(time.Since(data.Message.Expires)).String()+" ago")
+			} else {
+				msg += console.Colorize("Share", "Expiry: ")
+				msg += console.Colorize("Expires", timeDurationToHumanizedTime(data.Message.Expires.Sub(time.Now().UTC())))
+			}
 			msg += "\n"
 			console.Println(msg)
 			continue
@@ -82,21 +110,51 @@ func doShareList() *probe.Error {
 		s := data.Message
 		if len(s.DownloadURL) > 0 {
 			shareMessageBytes, err = json.Marshal(struct {
+				ID          string        `json:"id"`
 				Expiry      humanizedTime `json:"expiry"`
+				Expires     time.Time     `json:"expires"`
+				Expired     bool          `json:"expired"`
 				DownloadURL string        `json:"downloadUrl"`
 				Key         string        `json:"keyName"`
 			}{
+				ID:          s.ID,
 				Expiry:      timeDurationToHumanizedTime(s.Expiry),
+				Expires:     s.Expires,
+				Expired:     isExpired,
 				DownloadURL: s.DownloadURL,
 				Key:         s.Key,
 			})
+		} else if len(s.UploadURL) > 0 {
+			shareMessageBytes, err = json.Marshal(struct {
+				ID          string        `json:"id"`
+				Expiry      humanizedTime `json:"expiry"`
+				Expires     time.Time     `json:"expires"`
+				Expired     bool          `json:"expired"`
+				UploadURL   string        `json:"uploadUrl"`
+				ContentType string        `json:"contentType,omitempty"`
+				Key         string        `json:"keyName"`
+			}{
+				ID:          s.ID,
+				Expiry:      timeDurationToHumanizedTime(s.Expiry),
+				Expires:     s.Expires,
+				Expired:     isExpired,
+				UploadURL:   s.UploadURL,
+				ContentType: s.ContentType,
+				Key:         s.Key,
+			})
 		} else {
 			shareMessageBytes, err = json.Marshal(struct {
+				ID         string            `json:"id"`
 				Expiry     humanizedTime     `json:"expiry"`
+				Expires    time.Time         `json:"expires"`
+				Expired    bool              `json:"expired"`
 				UploadInfo map[string]string `json:"uploadInfo"`
 				Key        string            `json:"keyName"`
 			}{
+				ID:         s.ID,
 				Expiry:     timeDurationToHumanizedTime(s.Expiry),
+				Expires:    s.Expires,
+				Expired:    isExpired,
 				UploadInfo: s.UploadInfo,
 				Key:        s.Key,
 			})
@@ -106,7 +164,8 @@ func doShareList() *probe.Error {
 		}
 		console.Println(string(shareMessageBytes))
 	}
-	if err := saveSharedURLsV3(saveList); err != nil {
+	// IDs given to old entries and pruned entries are saved
+	if err := saveSharedURLsV3(sURLs); err != nil {
 		return err.Trace()
 	}
 	return nil
@@ -38,6 +38,8 @@ var shareCmd = cli.Command{
 		shareDownload,
 		shareUpload,
 		shareList,
+		shareRevoke,
+		shareServe,
 	},
 	CustomHelpTemplate: `NAME:
   {{.Name}} - {{.Usage}}
@@ -64,20 +66,37 @@ type ShareMessageV2 ShareMessageV1
 
 // ShareMessageV3 ...
 type ShareMessageV3 struct {
+	ID          string            `json:"id,omitempty"`
 	Expiry      time.Duration     `json:"expiry"`
+	Expires     time.Time         `json:"expires"`
 	DownloadURL string            `json:"downloadUrl,omitempty"`
 	UploadInfo  map[string]string `json:"uploadInfo,omitempty"`
+	UploadURL   string            `json:"uploadUrl,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
 	Key         string            `json:"keyName"`
 }
 
 // ShareMessage ...
 type ShareMessage ShareMessageV3
 
+// putCommand - curl command uploading to a presigned PUT URL
+func (s ShareMessage) putCommand() string {
+	curlCommand := "curl -X PUT "
+	if s.ContentType != "" {
+		curlCommand = curlCommand + fmt.Sprintf("-H \"Content-Type: %s\" ", s.ContentType)
+	}
+	return curlCommand + "-T <FILE> " + fmt.Sprintf("'%s'", s.UploadURL)
+}
+
 // String - regular colorized message
 func (s ShareMessage) String() string {
 	if len(s.DownloadURL) > 0 {
 		return console.Colorize("Share", fmt.Sprintf("%s", s.DownloadURL))
 	}
+	if len(s.UploadURL) > 0 {
+		emphasize := console.Colorize("File", "<FILE>")
+		return console.Colorize("Share", strings.Replace(s.putCommand(), "<FILE>", emphasize, -1))
+	}
 	var key string
 	URL := client.NewURL(s.Key)
 	postURL := URL.Scheme + URL.SchemeSeparator + URL.Host + string(URL.Separator) + s.UploadInfo["bucket"] + " "
@@ -101,14 +120,30 @@ func (s ShareMessage) JSON() string {
 	var err error
 	if len(s.DownloadURL) > 0 {
 		shareMessageBytes, err = json.Marshal(struct {
+			ID          string        `json:"id"`
 			Expiry      humanizedTime `json:"expiry"`
 			DownloadURL string        `json:"downloadUrl"`
 			Key         string        `json:"keyName"`
 		}{
+			ID:          s.ID,
 			Expiry:      timeDurationToHumanizedTime(s.Expiry),
 			DownloadURL: s.DownloadURL,
 			Key:         s.Key,
 		})
+	} else if len(s.UploadURL) > 0 {
+		shareMessageBytes, err = json.Marshal(struct {
+			ID            string        `json:"id"`
+			Expiry        humanizedTime `json:"expiry"`
+			UploadURL     string        `json:"uploadUrl"`
+			UploadCommand string        `json:"uploadCommand"`
+			Key           string        `json:"keyName"`
+		}{
+			ID:            s.ID,
+			Expiry:        timeDurationToHumanizedTime(s.Expiry),
+			UploadURL:     s.UploadURL,
+			UploadCommand: s.putCommand(),
+			Key:           s.Key,
+		})
 	} else {
 		var key string
 		URL := client.NewURL(s.Key)
@@ -124,10 +159,12 @@ func (s ShareMessage) JSON() string {
 		curlCommand = curlCommand + fmt.Sprintf("-F key=%s ", key) + "-F file=@<FILE> "
 
 		shareMessageBytes, err = json.Marshal(struct {
+			ID            string        `json:"id"`
 			Expiry        humanizedTime `json:"expiry"`
 			UploadCommand string        `json:"uploadCommand"`
 			Key           string        `json:"keyName"`
 		}{
+			ID:            s.ID,
 			Expiry:        timeDurationToHumanizedTime(s.Expiry),
 			UploadCommand: curlCommand,
 			Key:           s.Key,
@@ -0,0 +1,112 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Revoke shared URLs.
+var shareRevoke = cli.Command{
+	Name:   "revoke",
+	Usage:  "Remove shared URLs from the list of shared URLs",
+	Action: mainShareRevoke,
+	CustomHelpTemplate: `NAME:
+   mc share {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc share {{.Name}} ID [ID...]
+
+   Links issued by ‘mc share serve’ are refused by the server as soon as they are revoked.
+   Presigned S3 URLs can not be invalidated this way: they are signed with the credentials
+   of the host and stay usable until they expire, unless those credentials are changed.
+
+EXAMPLES:
+   1. Revoke a shared URL, IDs are shown by ‘mc share list’.
+      $ mc share {{.Name}} DfOCJknW
+
+`,
+}
+
+// ShareRevokeMessage container for revoked shared URLs
+type ShareRevokeMessage struct {
+	ID  string `json:"id"`
+	Key string `json:"keyName"`
+}
+
+// String colorized revoke message
+func (s ShareRevokeMessage) String() string {
+	return console.Colorize("Share", fmt.Sprintf("Revoked shared URL ‘%s’ for ‘%s’.", s.ID, s.Key))
+}
+
+// JSON jsonified revoke message
+func (s ShareRevokeMessage) JSON() string {
+	revokeMessageBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(revokeMessageBytes)
+}
+
+func checkShareRevokeSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "revoke", 1) // last argument is exit code
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+func mainShareRevoke(ctx *cli.Context) {
+	shareDataDirSetup()
+	checkShareRevokeSyntax(ctx)
+	setSharePalette(ctx.GlobalString("colors"))
+
+	for _, ID := range ctx.Args() {
+		err := doShareRevoke(ID)
+		fatalIf(err.Trace(ID), "Unable to revoke shared URL ‘"+ID+"’.")
+	}
+}
+
+// doShareRevoke remove the shared URL with ID, links of share serve are refused from then on
+func doShareRevoke(ID string) *probe.Error {
+	sURLs, err := loadSharedURLsV3()
+	if err != nil {
+		return err.Trace()
+	}
+	i, ok := findSharedURL(sURLs, ID)
+	if !ok {
+		return errSharedURLNotFound(ID).Trace(ID)
+	}
+	revoked := sURLs.URLs[i].Message
+	if err := revokeShareServeLink(revoked); err != nil {
+		return err.Trace(ID)
+	}
+	sURLs.URLs = append(sURLs.URLs[:i], sURLs.URLs[i+1:]...)
+	if err := saveSharedURLsV3(sURLs); err != nil {
+		return err.Trace()
+	}
+	Prints("%s\n", ShareRevokeMessage{ID: revoked.ID, Key: revoked.Key})
+	return nil
+}
@@ -0,0 +1,522 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Serve local files via URL.
+var shareServe = cli.Command{
+	Name:   "serve",
+	Usage:  "Share local files and folders via an embedded HTTP server",
+	Action: mainShareServe,
+	Flags:  []cli.Flag{listenFlag},
+	CustomHelpTemplate: `NAME:
+   mc share {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc share {{.Name}} [FLAGS] PATH [DURATION]
+
+   DURATION = NN[h|m|s] [DEFAULT=168h]
+
+   Download links are issued for files, folders also get an upload link. Links are signed
+   with a key kept in the share folder and stay valid across restarts of the server until
+   they expire or are revoked with ‘mc share revoke’.
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+EXAMPLES:
+   1. Share a file for 7 days on port 8080.
+      $ mc share {{.Name}} backup/2015/backup.tar.gz
+
+   2. Share all files of a folder recursively for 10 hours on port 9000, the folder accepts uploads.
+      $ mc share {{.Name}} --listen :9000 backup/2015... 10h
+
+`,
+}
+
+// query parameters of links issued by share serve
+const (
+	shareServeExpires   = "Expires"
+	shareServePrefix    = "Prefix"
+	shareServeSignature = "Signature"
+)
+
+// shareServer serves signed links to files below root
+type shareServer struct {
+	root   string
+	secret []byte
+}
+
+// ShareServeMessage container for requests handled by share serve
+type ShareServeMessage struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Status int       `json:"status"`
+}
+
+// String colorized share serve message
+func (s ShareServeMessage) String() string {
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", s.Time.Local().Format(printDate)))
+	message += console.Colorize("Share", fmt.Sprintf("%-4s %d ", s.Method, s.Status))
+	return message + console.Colorize("URL", s.Path)
+}
+
+// JSON jsonified share serve message
+func (s ShareServeMessage) JSON() string {
+	serveMessageBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(serveMessageBytes)
+}
+
+func checkShareServeSyntax(ctx *cli.Context) {
+	args := ctx.Args()
+	if !args.Present() || args.First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "serve", 1) // last argument is exit code
+	}
+	if len(args) > 2 {
+		cli.ShowCommandHelpAndExit(ctx, "serve", 1) // last argument is exit code
+	}
+	if client.NewURL(stripRecursiveURL(args.First())).Type != client.Filesystem {
+		fatalIf(errInvalidArgument().Trace(args.First()), "Only local files and folders can be served, use ‘share download’ for cloud storage.")
+	}
+	if _, _, e := net.SplitHostPort(ctx.String("listen")); e != nil {
+		fatalIf(probe.NewError(e).Trace(ctx.String("listen")), "Invalid listen address ‘"+ctx.String("listen")+"’.")
+	}
+}
+
+func mainShareServe(ctx *cli.Context) {
+	shareDataDirSetup()
+	checkShareServeSyntax(ctx)
+	setSharePalette(ctx.GlobalString("colors"))
+
+	args := ctx.Args()
+	// default expiration is 7days
+	expires := time.Duration(604800) * time.Second
+	if len(args) == 2 {
+		var e error
+		expires, e = time.ParseDuration(args.Get(1))
+		fatalIf(probe.NewError(e), "Unable to parse time argument.")
+	}
+
+	listener, e := net.Listen("tcp", ctx.String("listen"))
+	fatalIf(probe.NewError(e).Trace(ctx.String("listen")), "Unable to listen on ‘"+ctx.String("listen")+"’.")
+
+	doneCh := make(chan struct{})
+	go func() {
+		<-signalTrap(os.Interrupt, os.Kill)
+		close(doneCh)
+	}()
+	targetPath := args.First()
+	err := doShareServe(stripRecursiveURL(targetPath), isURLRecursive(targetPath), expires, listener, doneCh)
+	fatalIf(err.Trace(targetPath), "Unable to serve ‘"+targetPath+"’.")
+}
+
+// doShareServe issues links for targetPath and serves them on listener until doneCh is closed
+func doShareServe(targetPath string, recursive bool, expires time.Duration, listener net.Listener, doneCh <-chan struct{}) *probe.Error {
+	defer listener.Close()
+	if expires.Seconds() < 1 {
+		return probe.NewError(errors.New("Too low expires, expiration cannot be less than 1 second."))
+	}
+	if expires.Seconds() > 604800 {
+		return probe.NewError(errors.New("Too high expires, expiration cannot be larger than 7 days."))
+	}
+	targetPath, e := filepath.Abs(targetPath)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	st, e := os.Stat(targetPath)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	root := targetPath
+	if !st.IsDir() {
+		root = filepath.Dir(targetPath)
+	}
+	server, err := newShareServer(root)
+	if err != nil {
+		return err.Trace(root)
+	}
+	baseURL, err := shareServeBaseURL(listener.Addr())
+	if err != nil {
+		return err.Trace()
+	}
+	if err := server.issueLinks(baseURL, targetPath, recursive, expires); err != nil {
+		return err.Trace(targetPath)
+	}
+
+	httpServer := &http.Server{Handler: server}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+	select {
+	case <-doneCh:
+		httpServer.Close()
+		return nil
+	case e := <-errCh:
+		return probe.NewError(e)
+	}
+}
+
+// shareServeBaseURL - URL links are issued for, hosts listening on all interfaces are named by the hostname
+func shareServeBaseURL(addr net.Addr) (string, *probe.Error) {
+	host, port, e := net.SplitHostPort(addr.String())
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		if host, e = os.Hostname(); e != nil {
+			return "", probe.NewError(e)
+		}
+	}
+	return "http://" + net.JoinHostPort(host, port), nil
+}
+
+// getShareServeKeyFile - file holding the key links are signed with
+func getShareServeKeyFile() (string, *probe.Error) {
+	shareDir, err := getSharedURLsDataDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(shareDir, "serve.key"), nil
+}
+
+// getShareServeRevokedFile - file holding the signatures of revoked links along with their expiry
+func getShareServeRevokedFile() (string, *probe.Error) {
+	shareDir, err := getSharedURLsDataDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(shareDir, "serve-revoked.json"), nil
+}
+
+// loadShareServeRevoked - signatures of revoked links, mapped to the time their links expire
+func loadShareServeRevoked() (map[string]time.Time, *probe.Error) {
+	revokedFile, err := getShareServeRevokedFile()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	revoked := make(map[string]time.Time)
+	data, e := ioutil.ReadFile(revokedFile)
+	if os.IsNotExist(e) {
+		return revoked, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, &revoked); e != nil {
+		return nil, probe.NewError(e).Trace(revokedFile)
+	}
+	return revoked, nil
+}
+
+// revokeShareServeLink - refuse the link from now on, shared URLs which are
+// not links of share serve are left alone. Revoked links which expired are
+// dropped, they are refused anyway.
+func revokeShareServeLink(sURL ShareMessageV3) *probe.Error {
+	if client.NewURL(strings.TrimSuffix(sURL.Key, string(os.PathSeparator))).Type != client.Filesystem {
+		return nil
+	}
+	link := sURL.DownloadURL
+	if link == "" {
+		link = sURL.UploadURL
+	}
+	var query string
+	if i := strings.Index(link, "?"); i >= 0 {
+		query = link[i+1:]
+	}
+	values, e := url.ParseQuery(query)
+	if e != nil || values.Get(shareServeSignature) == "" {
+		return nil
+	}
+	revoked, err := loadShareServeRevoked()
+	if err != nil {
+		return err.Trace()
+	}
+	for signature, expires := range revoked {
+		if time.Now().UTC().After(expires) {
+			delete(revoked, signature)
+		}
+	}
+	revoked[values.Get(shareServeSignature)] = sURL.Expires
+	data, e := json.Marshal(revoked)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	revokedFile, err := getShareServeRevokedFile()
+	if err != nil {
+		return err.Trace()
+	}
+	if e = ioutil.WriteFile(revokedFile, data, 0600); e != nil {
+		return probe.NewError(e).Trace(revokedFile)
+	}
+	return nil
+}
+
+// newShareServer - server for files below root, the signing key is created on first use
+func newShareServer(root string) (*shareServer, *probe.Error) {
+	keyFile, err := getShareServeKeyFile()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	keyHex, e := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(e) {
+		secret := make([]byte, 32)
+		if _, e = rand.Read(secret); e != nil {
+			return nil, probe.NewError(e)
+		}
+		keyHex = []byte(hex.EncodeToString(secret))
+		e = ioutil.WriteFile(keyFile, keyHex, 0600)
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	secret, e := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if e != nil || len(secret) == 0 {
+		return nil, probe.NewError(errors.New("Invalid share serve key in ‘" + keyFile + "’."))
+	}
+	return &shareServer{root: root, secret: secret}, nil
+}
+
+// signature - links are bound to the served root, the method, the path or upload prefix and the expiry
+func (s *shareServer) signature(method, urlPath string, expires int64) string {
+	hash := hmac.New(sha256.New, s.secret)
+	hash.Write([]byte(method + "\n" + s.root + "\n" + urlPath + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// downloadURL - signed link to download the file at urlPath
+func (s *shareServer) downloadURL(baseURL, urlPath string, expires time.Time) string {
+	query := make(url.Values)
+	query.Set(shareServeExpires, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(shareServeSignature, s.signature("GET", urlPath, expires.Unix()))
+	return baseURL + (&url.URL{Path: urlPath}).EscapedPath() + "?" + query.Encode()
+}
+
+// uploadURL - signed link to upload files below prefix, <FILE> is to be replaced by the file name
+func (s *shareServer) uploadURL(baseURL, prefix string, expires time.Time) string {
+	query := make(url.Values)
+	query.Set(shareServeExpires, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(shareServePrefix, prefix)
+	query.Set(shareServeSignature, s.signature("PUT", prefix, expires.Unix()))
+	return baseURL + (&url.URL{Path: prefix}).EscapedPath() + "<FILE>?" + query.Encode()
+}
+
+// urlPath - path of a file below root in links
+func (s *shareServer) urlPath(filePath string) (string, *probe.Error) {
+	relPath, e := filepath.Rel(s.root, filePath)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	if relPath == "." {
+		return "/", nil
+	}
+	urlPath := "/" + filepath.ToSlash(relPath)
+	if st, e := os.Stat(filePath); e == nil && st.IsDir() {
+		urlPath += "/"
+	}
+	return urlPath, nil
+}
+
+// issueLinks - print and record download links for targetPath, folders also get an upload link
+func (s *shareServer) issueLinks(baseURL, targetPath string, recursive bool, expires time.Duration) *probe.Error {
+	shareDate := time.Now().UTC()
+	sURLs, err := loadSharedURLsV3()
+	if err != nil {
+		return err.Trace()
+	}
+	st, e := os.Stat(targetPath)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	var filePaths []string
+	e = filepath.Walk(targetPath, func(filePath string, fi os.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+		if fi.IsDir() && filePath != targetPath && !recursive {
+			return filepath.SkipDir
+		}
+		if fi.Mode().IsRegular() {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, filePath := range filePaths {
+		urlPath, err := s.urlPath(filePath)
+		if err != nil {
+			return err.Trace(filePath)
+		}
+		shareMessage := addSharedURL(sURLs, shareDate, ShareMessageV3{
+			Expiry:      expires,
+			DownloadURL: s.downloadURL(baseURL, urlPath, shareDate.Add(expires)),
+			Key:         filePath,
+		})
+		Prints("%s\n", ShareMessage(shareMessage))
+	}
+	if st.IsDir() {
+		prefix, err := s.urlPath(targetPath)
+		if err != nil {
+			return err.Trace(targetPath)
+		}
+		shareMessage := addSharedURL(sURLs, shareDate, ShareMessageV3{
+			Expiry:    expires,
+			UploadURL: s.uploadURL(baseURL, prefix, shareDate.Add(expires)),
+			Key:       targetPath + string(os.PathSeparator),
+		})
+		Prints("%s\n", ShareMessage(shareMessage))
+	}
+	return saveSharedURLsV3(sURLs).Trace()
+}
+
+// verify - check the signature and expiry of a request and that its link was not revoked,
+// uploads are allowed anywhere below the signed prefix
+func (s *shareServer) verify(r *http.Request) int {
+	query := r.URL.Query()
+	expires, e := strconv.ParseInt(query.Get(shareServeExpires), 10, 64)
+	if e != nil {
+		return http.StatusForbidden
+	}
+	method, signedPath := r.Method, r.URL.Path
+	switch r.Method {
+	case "GET", "HEAD":
+		method = "GET"
+	case "PUT":
+		signedPath = query.Get(shareServePrefix)
+		if !strings.HasPrefix(signedPath, "/") || !strings.HasSuffix(signedPath, "/") || !strings.HasPrefix(r.URL.Path, signedPath) {
+			return http.StatusForbidden
+		}
+	default:
+		return http.StatusMethodNotAllowed
+	}
+	expected := s.signature(method, signedPath, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get(shareServeSignature))) {
+		return http.StatusForbidden
+	}
+	if time.Now().UTC().Unix() > expires {
+		return http.StatusForbidden
+	}
+	// links are refused as well when the list of revoked links can not be read
+	revoked, err := loadShareServeRevoked()
+	if err != nil {
+		return http.StatusForbidden
+	}
+	if _, ok := revoked[query.Get(shareServeSignature)]; ok {
+		return http.StatusForbidden
+	}
+	return http.StatusOK
+}
+
+// ServeHTTP serves files for signed links, requests are logged as messages
+func (s *shareServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := s.serve(w, r)
+	Prints("%s\n", ShareServeMessage{
+		Time:   time.Now().UTC(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Status: status,
+	})
+}
+
+func (s *shareServer) serve(w http.ResponseWriter, r *http.Request) int {
+	status := s.verify(r)
+	// paths escaping the root are refused, links are never issued for them
+	if status == http.StatusOK && (r.URL.Path != path.Clean(r.URL.Path) || strings.HasSuffix(r.URL.Path, "/")) {
+		status = http.StatusForbidden
+	}
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return status
+	}
+	filePath := filepath.Join(s.root, filepath.FromSlash(r.URL.Path))
+	if r.Method == "PUT" {
+		return s.put(w, r, filePath)
+	}
+	file, e := os.Open(filePath)
+	if e != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return http.StatusNotFound
+	}
+	defer file.Close()
+	st, e := file.Stat()
+	if e != nil || !st.Mode().IsRegular() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return http.StatusNotFound
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+strings.Replace(st.Name(), "\"", "", -1)+"\"")
+	http.ServeContent(w, r, st.Name(), st.ModTime(), file)
+	return http.StatusOK
+}
+
+// put - write the request body next to filePath first, so that readers never see partial files
+func (s *shareServer) put(w http.ResponseWriter, r *http.Request, filePath string) int {
+	if e := os.MkdirAll(filepath.Dir(filePath), 0700); e != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+	tmpFile, e := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath)+".")
+	if e != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+	_, e = io.Copy(tmpFile, r.Body)
+	if e == nil {
+		e = tmpFile.Close()
+	} else {
+		tmpFile.Close()
+	}
+	if e == nil {
+		e = os.Rename(tmpFile.Name(), filePath)
+	}
+	if e != nil {
+		os.Remove(tmpFile.Name())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+	w.WriteHeader(http.StatusOK)
+	return http.StatusOK
+}
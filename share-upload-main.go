@@ -30,14 +30,18 @@ var shareUpload = cli.Command{
 	Name:   "upload",
 	Usage:  "Share link that can be used to upload files to private bucket",
 	Action: mainShareUpload,
+	Flags:  []cli.Flag{methodFlag},
 	CustomHelpTemplate: `NAME:
    mc share {{.Name}} - {{.Usage}}
 
 USAGE:
-   mc share {{.Name}} TARGET [DURATION] [Content-Type]
+   mc share {{.Name}} [FLAGS] TARGET [DURATION] [Content-Type]
 
    DURATION = NN[h|m|s] [DEFAULT=168h]
 
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
 EXAMPLES:
    1. Generate Curl upload command, with a default expiry of 7 days.
       $ mc share {{.Name}} https://s3.amazonaws.com/backup/2006-Mar-1/backup.tar.gz
@@ -48,6 +52,9 @@ EXAMPLES:
    3. Generate Curl upload command to upload with expiry of 2 hours with content-type image/png
       $ mc share {{.Name}} https://s3.amazonaws.com/backup/2007-Mar-2/... 2h image/png
 
+   4. Generate a presigned PUT URL for tools which can only do a plain HTTP PUT, with expiry of 12 hours
+      $ mc share {{.Name}} --method put https://s3.amazonaws.com/backup/2007-Mar-2/backup.tar.gz 12h
+
 `,
 }
 
@@ -66,6 +73,16 @@ func checkShareUploadSyntax(ctx *cli.Context) {
 	if strings.HasSuffix(strings.TrimSpace(args.Get(0)), "/") {
 		fatalIf(errDummy().Trace(), fmt.Sprintf("Upload location cannot end with ‘/’. Did you mean ‘%s’.", url+recursiveSeparator))
 	}
+	switch ctx.String("method") {
+	case "post":
+	case "put":
+		// a presigned PUT URL is valid for a single object only
+		if isURLRecursive(strings.TrimSpace(args.Get(0))) {
+			fatalIf(errInvalidArgument().Trace(args.Get(0)), "Upload method ‘put’ needs an object, not a folder ‘"+args.Get(0)+"’.")
+		}
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("method")), "Unknown upload method ‘"+ctx.String("method")+"’, expected ‘post’ or ‘put’.")
+	}
 }
 
 func mainShareUpload(ctx *cli.Context) {
@@ -88,6 +105,11 @@ func mainShareUpload(ctx *cli.Context) {
 	contentType := strings.TrimSpace(args.Get(2))
 	targetURL := getAliasURL(strings.TrimSpace(args.Get(0)), config.Aliases)
 
+	if ctx.String("method") == "put" {
+		e := doSharePutURL(targetURL, expires, contentType)
+		fatalIf(e.Trace(targetURL), "Unable to generate URL for upload.")
+		return
+	}
 	e := doShareUploadURL(stripRecursiveURL(targetURL), isURLRecursive(targetURL), expires, contentType)
 	fatalIf(e.Trace(targetURL), "Unable to generate URL for upload.")
 }
@@ -113,24 +135,38 @@ func doShareUploadURL(targetURL string, recursive bool, expires time.Duration, c
 		Key = Key + recursiveSeparator
 		m["key"] = m["key"] + "<FILE>"
 	}
-	shareMessage := ShareMessage{
+	shareMessage := addSharedURL(sURLs, shareDate, ShareMessageV3{
 		Expiry:     expires,
 		UploadInfo: m,
 		Key:        Key,
-	}
-	shareMessageV3 := ShareMessageV3{
-		Expiry:     expires,
-		UploadInfo: m,
-		Key:        Key,
-	}
-	sURLs.URLs = append(sURLs.URLs, struct {
-		Date    time.Time
-		Message ShareMessageV3
-	}{
-		Date:    shareDate,
-		Message: shareMessageV3,
 	})
-	Prints("%s\n", shareMessage)
+	Prints("%s\n", ShareMessage(shareMessage))
 	saveSharedURLsV3(sURLs)
 	return nil
 }
+
+// doSharePutURL share a presigned PUT URL for a single object
+func doSharePutURL(targetURL string, expires time.Duration, contentType string) *probe.Error {
+	shareDate := time.Now().UTC()
+	sURLs, err := loadSharedURLsV3()
+	if err != nil {
+		return err.Trace()
+	}
+
+	clnt, err := url2Client(targetURL)
+	if err != nil {
+		return err.Trace()
+	}
+	uploadURL, err := clnt.ShareUploadURL(expires, contentType)
+	if err != nil {
+		return err.Trace()
+	}
+	shareMessage := addSharedURL(sURLs, shareDate, ShareMessageV3{
+		Expiry:      expires,
+		UploadURL:   uploadURL,
+		ContentType: contentType,
+		Key:         targetURL,
+	})
+	Prints("%s\n", ShareMessage(shareMessage))
+	return saveSharedURLsV3(sURLs).Trace()
+}
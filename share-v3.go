@@ -50,6 +50,16 @@ func loadSharedURLsV3() (*sharedURLsV3, *probe.Error) {
 		return nil, err.Trace(sharedURLsDataFile)
 	}
 	s := qs.Data().(*sharedURLsV3)
+	// entries saved before IDs and expiry timestamps were recorded get them on load
+	for i, sURL := range s.URLs {
+		if sURL.Message.Expires.IsZero() {
+			s.URLs[i].Message.Expires = sURL.Date.Add(sURL.Message.Expiry)
+		}
+		if sURL.Message.ID == "" {
+			s.URLs[i].Message.ID = newSharedURLID(s)
+		}
+	}
+	pruneSharedURLs(s)
 	return s, nil
 }
 
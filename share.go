@@ -192,6 +192,60 @@ func createSharedURLsDataFile() *probe.Error {
 	return nil
 }
 
+// shareExpiredRetention - expired shared URLs are kept this long for ‘share list --expired’
+const shareExpiredRetention = 30 * 24 * time.Hour
+
+// newSharedURLID - random ID not used by any shared URL yet
+func newSharedURLID(sURLs *sharedURLsV3) string {
+	for {
+		ID := newRandomID(8)
+		if _, ok := findSharedURL(sURLs, ID); !ok {
+			return ID
+		}
+	}
+}
+
+// addSharedURL - add a shared URL created at shareDate with a new unique ID, the stored message is returned
+func addSharedURL(sURLs *sharedURLsV3, shareDate time.Time, msg ShareMessageV3) ShareMessageV3 {
+	msg.ID = newSharedURLID(sURLs)
+	msg.Expires = shareDate.Add(msg.Expiry)
+	sURLs.URLs = append(sURLs.URLs, struct {
+		Date    time.Time
+		Message ShareMessageV3
+	}{
+		Date:    shareDate,
+		Message: msg,
+	})
+	return msg
+}
+
+// findSharedURL - index of the shared URL with ID
+func findSharedURL(sURLs *sharedURLsV3, ID string) (int, bool) {
+	for i, sURL := range sURLs.URLs {
+		if sURL.Message.ID == ID {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// isExpired - shared URL can no longer be used
+func (s ShareMessageV3) isExpired() bool {
+	return time.Now().UTC().After(s.Expires)
+}
+
+// pruneSharedURLs - drop shared URLs which expired longer than shareExpiredRetention ago
+func pruneSharedURLs(sURLs *sharedURLsV3) {
+	URLs := sURLs.URLs[:0]
+	for _, sURL := range sURLs.URLs {
+		if time.Since(sURL.Message.Expires) > shareExpiredRetention {
+			continue
+		}
+		URLs = append(URLs, sURL)
+	}
+	sURLs.URLs = URLs
+}
+
 func getNewTargetURL(targetParser *client.URL, name string) string {
 	match, _ := filepath.Match("*.s3*.amazonaws.com", targetParser.Host)
 	if match {
@@ -246,6 +300,8 @@ func isObjectKeyPresent(url string) bool {
 func setSharePalette(style string) {
 	console.SetCustomPalette(map[string]*color.Color{
 		"Share":   color.New(color.FgGreen, color.Bold),
+		"ID":      color.New(color.FgYellow, color.Bold),
+		"Time":    color.New(color.FgGreen),
 		"Expires": color.New(color.FgRed, color.Bold),
 		"URL":     color.New(color.FgCyan, color.Bold),
 		"File":    color.New(color.FgRed, color.Bold),
@@ -253,6 +309,8 @@ func setSharePalette(style string) {
 	if style == "light" {
 		console.SetCustomPalette(map[string]*color.Color{
 			"Share":   color.New(color.FgWhite, color.Bold),
+			"ID":      color.New(color.FgWhite, color.Bold),
+			"Time":    color.New(color.FgWhite),
 			"Expires": color.New(color.FgWhite, color.Bold),
 			"URL":     color.New(color.FgWhite, color.Bold),
 			"File":    color.New(color.FgWhite, color.Bold),
@@ -0,0 +1,354 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client/s3test"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestShareServer(c *C) {
+	shareDataDirSetup()
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		s3Server.MakeBucket("share-bucket", "private")
+		c.Assert(s3Server.PutObject("share-bucket", "object", []byte("Hello, World")), Equals, true)
+
+		perr := doShareDownloadURL(s3Server.URL+"/share-bucket/object", false, time.Hour, shareDownloadOptions{})
+		c.Assert(perr, IsNil)
+		perr = doShareUploadURL(s3Server.URL+"/share-bucket/uploads/", true, time.Hour, "")
+		c.Assert(perr, IsNil)
+
+		sURLs, perr := loadSharedURLsV3()
+		c.Assert(perr, IsNil)
+		var downloadURL string
+		var uploadInfo map[string]string
+		for _, sURL := range sURLs.URLs {
+			switch sURL.Message.Key {
+			case s3Server.URL + "/share-bucket/object":
+				downloadURL = sURL.Message.DownloadURL
+			case s3Server.URL + "/share-bucket/uploads/" + recursiveSeparator:
+				uploadInfo = sURL.Message.UploadInfo
+			}
+		}
+
+		// anonymous access is refused, the shared url works
+		resp, e := http.Get(s3Server.URL + "/share-bucket/object")
+		c.Assert(e, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusForbidden)
+
+		resp, e = http.Get(downloadURL)
+		c.Assert(e, IsNil)
+		data, e := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.Assert(e, IsNil)
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		c.Assert(string(data), Equals, "Hello, World")
+
+		// upload the way the generated curl command does
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for k, v := range uploadInfo {
+			if k == "key" {
+				v = strings.Replace(v, "<FILE>", "file", -1)
+			}
+			c.Assert(writer.WriteField(k, v), IsNil)
+		}
+		file, e := writer.CreateFormFile("file", "file")
+		c.Assert(e, IsNil)
+		file.Write([]byte("uploaded"))
+		c.Assert(writer.Close(), IsNil)
+		resp, e = http.Post(s3Server.URL+"/share-bucket", writer.FormDataContentType(), &body)
+		c.Assert(e, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusNoContent)
+
+		data, ok := s3Server.GetObject("share-bucket", "uploads/file")
+		c.Assert(ok, Equals, true)
+		c.Assert(string(data), Equals, "uploaded")
+	}
+}
+
+func (s *TestSuite) TestSharePut(c *C) {
+	shareDataDirSetup()
+	for _, s3Server := range []*s3test.Server{s3v4Server, s3v2Server} {
+		s3Server.MakeBucket("share-put", "private")
+
+		perr := doSharePutURL(s3Server.URL+"/share-put/uploads/file", time.Hour, "text/plain")
+		c.Assert(perr, IsNil)
+
+		// the presigned URL is stored along with the other shared URLs
+		sURLs, perr := loadSharedURLsV3()
+		c.Assert(perr, IsNil)
+		var shared ShareMessageV3
+		for _, sURL := range sURLs.URLs {
+			if sURL.Message.Key == s3Server.URL+"/share-put/uploads/file" {
+				shared = sURL.Message
+			}
+		}
+		c.Assert(shared.UploadURL, Not(Equals), "")
+		c.Assert(shared.ContentType, Equals, "text/plain")
+		c.Assert(ShareMessage(shared).JSON(), Matches, `.*"uploadCommand":"curl -X PUT -H \\"Content-Type: text/plain\\" -T <FILE> '.*'".*`)
+
+		req, e := http.NewRequest("PUT", shared.UploadURL, bytes.NewReader([]byte("uploaded")))
+		c.Assert(e, IsNil)
+		req.Header.Set("Content-Type", shared.ContentType)
+		resp, e := http.DefaultClient.Do(req)
+		c.Assert(e, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+
+		data, ok := s3Server.GetObject("share-put", "uploads/file")
+		c.Assert(ok, Equals, true)
+		c.Assert(string(data), Equals, "uploaded")
+	}
+
+	// shared URLs can not be generated for the filesystem
+	perr := doSharePutURL(filepath.Join(c.MkDir(), "file"), time.Hour, "")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestShareDownloadOptions(c *C) {
+	shareDataDirSetup()
+	s3v4Server.MakeBucket("share-options", "private")
+	for _, object := range []string{"docs/report.pdf", "docs/本語.txt"} {
+		c.Assert(s3v4Server.PutObject("share-options", object, []byte(object)), Equals, true)
+	}
+
+	options := shareDownloadOptions{contentDisposition: "attachment", contentType: "application/octet-stream", format: "json"}
+	perr := doShareDownloadURL(s3v4Server.URL+"/share-options/docs", true, time.Hour, options)
+	c.Assert(perr, IsNil)
+
+	sURLs, perr := loadSharedURLsV3()
+	c.Assert(perr, IsNil)
+	dispositions := make(map[string]string)
+	for _, sURL := range sURLs.URLs {
+		if !strings.HasPrefix(sURL.Message.Key, s3v4Server.URL+"/share-options/") {
+			continue
+		}
+		resp, e := http.Get(sURL.Message.DownloadURL)
+		c.Assert(e, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		c.Assert(resp.Header.Get("Content-Type"), Equals, "application/octet-stream")
+		dispositions[sURL.Message.Key] = resp.Header.Get("Content-Disposition")
+	}
+	c.Assert(dispositions, DeepEquals, map[string]string{
+		s3v4Server.URL + "/share-options/docs/report.pdf": `attachment; filename=report.pdf`,
+		s3v4Server.URL + "/share-options/docs/本語.txt":     `attachment; filename*=utf-8''%E6%9C%AC%E8%AA%9E.txt`,
+	})
+
+	// complete values are used as is
+	reqParams := shareDownloadOptions{contentDisposition: `inline; filename="a.txt"`}.reqParams("docs/b.txt")
+	c.Assert(reqParams.Get("response-content-disposition"), Equals, `inline; filename="a.txt"`)
+	c.Assert(reqParams.Get("response-content-type"), Equals, "")
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "share", "download", "--format", "csv", "--content-type", "text/plain", s3v4Server.URL + "/share-options/docs..."})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "share", "download", "--format", "yaml", s3v4Server.URL + "/share-options/docs..."})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+	console.IsExited = false
+}
+
+func (s *TestSuite) TestShareIndex(c *C) {
+	expires := time.Date(2015, time.October, 9, 12, 0, 0, 0, time.UTC)
+	entries := []shareIndexEntry{
+		{Name: "a.txt", Size: 5, Expires: expires, URL: "https://example.com/bucket/a.txt?X-Amz-Expires=3600&X-Amz-Signature=abc"},
+		{Name: "<b>, c.txt", Size: 7, Expires: expires, URL: "https://example.com/bucket/b.txt?X-Amz-Signature=def"},
+	}
+
+	var buf bytes.Buffer
+	c.Assert(writeShareIndex(&buf, "csv", entries), IsNil)
+	c.Assert(buf.String(), Equals, "name,size,expires,url\n"+
+		"a.txt,5,2015-10-09T12:00:00Z,https://example.com/bucket/a.txt?X-Amz-Expires=3600&X-Amz-Signature=abc\n"+
+		"\"<b>, c.txt\",7,2015-10-09T12:00:00Z,https://example.com/bucket/b.txt?X-Amz-Signature=def\n")
+
+	buf.Reset()
+	c.Assert(writeShareIndex(&buf, "json", entries), IsNil)
+	c.Assert(strings.Contains(buf.String(), "X-Amz-Expires=3600&X-Amz-Signature=abc"), Equals, true)
+	var decoded []shareIndexEntry
+	c.Assert(json.Unmarshal(buf.Bytes(), &decoded), IsNil)
+	c.Assert(decoded, HasLen, 2)
+	c.Assert(decoded[1].Name, Equals, "<b>, c.txt")
+
+	buf.Reset()
+	c.Assert(writeShareIndex(&buf, "json", nil), IsNil)
+	c.Assert(buf.String(), Equals, "[]\n")
+
+	buf.Reset()
+	c.Assert(writeShareIndex(&buf, "html", entries), IsNil)
+	c.Assert(strings.Contains(buf.String(), `<a href="https://example.com/bucket/a.txt?X-Amz-Expires=3600&amp;X-Amz-Signature=abc">a.txt</a>`), Equals, true)
+	c.Assert(strings.Contains(buf.String(), "&lt;b&gt;, c.txt"), Equals, true)
+
+	c.Assert(writeShareIndex(&buf, "xml", entries), Not(IsNil))
+}
+
+func (s *TestSuite) TestShareExpiry(c *C) {
+	shareDataDirSetup()
+	sURLs, perr := loadSharedURLsV3()
+	c.Assert(perr, IsNil)
+
+	now := time.Now().UTC()
+	active := addSharedURL(sURLs, now, ShareMessageV3{Expiry: time.Hour, DownloadURL: "http://example.com/active", Key: "active"})
+	c.Assert(active.ID, HasLen, 8)
+	c.Assert(active.Expires, Equals, now.Add(time.Hour))
+	expired := addSharedURL(sURLs, now.Add(-2*time.Hour), ShareMessageV3{Expiry: time.Hour, DownloadURL: "http://example.com/expired", Key: "expired"})
+	addSharedURL(sURLs, now.Add(-shareExpiredRetention-2*time.Hour), ShareMessageV3{Expiry: time.Hour, DownloadURL: "http://example.com/old", Key: "old"})
+	// entries saved by older versions have neither ID nor expiry timestamp
+	sURLs.URLs = append(sURLs.URLs, struct {
+		Date    time.Time
+		Message ShareMessageV3
+	}{
+		Date:    now,
+		Message: ShareMessageV3{Expiry: time.Minute, DownloadURL: "http://example.com/legacy", Key: "legacy"},
+	})
+	c.Assert(saveSharedURLsV3(sURLs), IsNil)
+
+	sURLs, perr = loadSharedURLsV3()
+	c.Assert(perr, IsNil)
+	messages := make(map[string]ShareMessageV3)
+	for _, sURL := range sURLs.URLs {
+		messages[sURL.Message.Key] = sURL.Message
+	}
+	c.Assert(messages["active"].isExpired(), Equals, false)
+	c.Assert(messages["expired"].isExpired(), Equals, true)
+	_, ok := messages["old"]
+	c.Assert(ok, Equals, false)
+	c.Assert(messages["legacy"].ID, HasLen, 8)
+	c.Assert(messages["legacy"].Expires, Equals, now.Add(time.Minute))
+
+	c.Assert(doShareList(true, false), IsNil)
+	c.Assert(doShareList(false, true), IsNil)
+
+	c.Assert(doShareRevoke(expired.ID), IsNil)
+	sURLs, perr = loadSharedURLsV3()
+	c.Assert(perr, IsNil)
+	_, ok = findSharedURL(sURLs, expired.ID)
+	c.Assert(ok, Equals, false)
+	_, ok = findSharedURL(sURLs, active.ID)
+	c.Assert(ok, Equals, true)
+
+	c.Assert(doShareRevoke(expired.ID), Not(IsNil))
+}
+
+func (s *TestSuite) TestShareServe(c *C) {
+	shareDataDirSetup()
+	root := c.MkDir()
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "object1"), []byte("hello world"), 0600), IsNil)
+
+	listener, e := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(e, IsNil)
+	doneCh := make(chan struct{})
+	errCh := make(chan *probe.Error, 1)
+	go func() {
+		errCh <- doShareServe(root, true, time.Hour, listener, doneCh)
+	}()
+	defer func() {
+		close(doneCh)
+		c.Assert(<-errCh, IsNil)
+	}()
+
+	// links are recorded before serving starts
+	var downloadURL, uploadURL, downloadID string
+	for i := 0; i < 100 && (downloadURL == "" || uploadURL == ""); i++ {
+		time.Sleep(10 * time.Millisecond)
+		sURLs, perr := loadSharedURLsV3()
+		c.Assert(perr, IsNil)
+		for _, sURL := range sURLs.URLs {
+			switch sURL.Message.Key {
+			case filepath.Join(root, "object1"):
+				downloadURL, downloadID = sURL.Message.DownloadURL, sURL.Message.ID
+			case root + string(os.PathSeparator):
+				uploadURL = sURL.Message.UploadURL
+			}
+		}
+	}
+	c.Assert(downloadURL, Not(Equals), "")
+	c.Assert(uploadURL, Not(Equals), "")
+	// listener is bound to 127.0.0.1, links name it
+	c.Assert(strings.HasPrefix(downloadURL, "http://"+listener.Addr().String()+"/object1?"), Equals, true)
+
+	get := func(link string) (int, string) {
+		res, e := http.Get(link)
+		c.Assert(e, IsNil)
+		defer res.Body.Close()
+		body, e := ioutil.ReadAll(res.Body)
+		c.Assert(e, IsNil)
+		return res.StatusCode, string(body)
+	}
+	put := func(link, data string) int {
+		req, e := http.NewRequest("PUT", link, strings.NewReader(data))
+		c.Assert(e, IsNil)
+		res, e := http.DefaultClient.Do(req)
+		c.Assert(e, IsNil)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	status, body := get(downloadURL)
+	c.Assert(status, Equals, http.StatusOK)
+	c.Assert(body, Equals, "hello world")
+	status, _ = get(strings.Replace(downloadURL, "object1", "object2", 1))
+	c.Assert(status, Equals, http.StatusForbidden)
+	// change the last digit of the signature
+	tampered := downloadURL[:len(downloadURL)-1] + "0"
+	if strings.HasSuffix(downloadURL, "0") {
+		tampered = downloadURL[:len(downloadURL)-1] + "1"
+	}
+	status, _ = get(tampered)
+	c.Assert(status, Equals, http.StatusForbidden)
+
+	c.Assert(put(strings.Replace(uploadURL, "<FILE>", "dir/object2", 1), "uploaded"), Equals, http.StatusOK)
+	data, e := ioutil.ReadFile(filepath.Join(root, "dir", "object2"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "uploaded")
+	c.Assert(put(strings.Replace(uploadURL, "<FILE>", "dir/../../object3", 1), "escaped"), Equals, http.StatusForbidden)
+	// download links do not allow uploads
+	c.Assert(put(downloadURL, "overwritten"), Equals, http.StatusForbidden)
+
+	// expired links are refused even with a valid signature
+	server, perr := newShareServer(root)
+	c.Assert(perr, IsNil)
+	baseURL := "http://" + listener.Addr().String()
+	status, _ = get(server.downloadURL(baseURL, "/object1", time.Now().Add(-time.Minute)))
+	c.Assert(status, Equals, http.StatusForbidden)
+	status, _ = get(server.downloadURL(baseURL, "/object1", time.Now().Add(time.Minute)))
+	c.Assert(status, Equals, http.StatusOK)
+
+	// revoked links are refused, other links keep working
+	c.Assert(doShareRevoke(downloadID), IsNil)
+	status, _ = get(downloadURL)
+	c.Assert(status, Equals, http.StatusForbidden)
+	c.Assert(put(strings.Replace(uploadURL, "<FILE>", "object4", 1), "uploaded"), Equals, http.StatusOK)
+}
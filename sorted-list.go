@@ -162,6 +162,16 @@ func (sl *sortedList) Match(source *client.Content) (bool, *probe.Error) {
 	}
 }
 
+// Rewind restart listing the entries from the beginning of the sorted file
+func (sl *sortedList) Rewind() *probe.Error {
+	if _, err := sl.file.Seek(0, os.SEEK_SET); err != nil {
+		return probe.NewError(err)
+	}
+	sl.dec = gob.NewDecoder(sl.file)
+	sl.current = client.Content{}
+	return nil
+}
+
 // Delete close and delete the ondisk file
 func (sl sortedList) Delete() *probe.Error {
 	if err := sl.file.Close(); err != nil {
@@ -0,0 +1,184 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Display information about files and objects.
+var statCmd = cli.Command{
+	Name:   "stat",
+	Usage:  "Display information about files and objects.",
+	Action: mainStat,
+	Flags:  []cli.Flag{versionIDFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET [TARGET ...]
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+EXAMPLES:
+   1. Display information about an object on Amazon S3 cloud storage.
+      $ mc {{.Name}} https://s3.amazonaws.com/backup/2015-May.tar.gz
+
+   2. Display information about a file and a folder on local filesystem.
+      $ mc {{.Name}} Music/Klingon/Qapla.ogg Music/Klingon
+
+   3. Display information about a previous version of an object on Amazon S3 cloud storage.
+      $ mc {{.Name}} --version-id 3HL4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nrjfkd s3/andoria/config.json
+`,
+}
+
+// StatMessage container for stat message structure.
+type StatMessage struct {
+	Name      string    `json:"name"`
+	Time      time.Time `json:"lastModified"`
+	Size      int64     `json:"size"`
+	Filetype  string    `json:"type"`
+	VersionID string    `json:"versionId,omitempty"`
+
+	StorageClass string            `json:"storageClass,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+// String colorized stat message
+func (s StatMessage) String() string {
+	message := console.Colorize("Name", fmt.Sprintf("%-10s: %s\n", "Name", s.Name))
+	message += console.Colorize("Time", fmt.Sprintf("%-10s: %s\n", "Date", s.Time.Format(printDate)))
+	message += console.Colorize("Size", fmt.Sprintf("%-10s: %s\n", "Size", humanize.IBytes(uint64(s.Size))))
+	message += console.Colorize("Type", fmt.Sprintf("%-10s: %s", "Type", s.Filetype))
+	if s.VersionID != "" {
+		message += console.Colorize("Version", fmt.Sprintf("\n%-10s: %s", "Version", s.VersionID))
+	}
+	if s.StorageClass != "" {
+		message += console.Colorize("Class", fmt.Sprintf("\n%-10s: %s", "Class", s.StorageClass))
+	}
+	if len(s.Tags) > 0 {
+		message += console.Colorize("Tags", fmt.Sprintf("\n%-10s: %s", "Tags", tagsString(s.Tags)))
+	}
+	return message
+}
+
+// JSON jsonified stat message
+func (s StatMessage) JSON() string {
+	statJSONBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(statJSONBytes)
+}
+
+func checkStatSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "stat", 1) // last argument is exit code
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+	URLs, err := args2URLs(ctx.Args())
+	fatalIf(err.Trace(ctx.Args()...), "One or more unknown URL types passed.")
+	checkVersionIDSyntax(URLs, ctx.String("version-id"))
+}
+
+func setStatPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Name":    color.New(color.FgWhite, color.Bold),
+		"Time":    color.New(color.FgGreen),
+		"Size":    color.New(color.FgYellow),
+		"Type":    color.New(color.FgCyan),
+		"Version": color.New(color.FgMagenta),
+		"Class":   color.New(color.FgCyan),
+		"Tags":    color.New(color.FgBlue),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Name":    color.New(color.FgWhite, color.Bold),
+			"Time":    color.New(color.FgWhite, color.Bold),
+			"Size":    color.New(color.FgWhite, color.Bold),
+			"Type":    color.New(color.FgWhite, color.Bold),
+			"Version": color.New(color.FgWhite, color.Bold),
+			"Class":   color.New(color.FgWhite, color.Bold),
+			"Tags":    color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	/// Add more styles here
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+// mainStat - is a handler for mc stat command
+func mainStat(ctx *cli.Context) {
+	checkStatSyntax(ctx)
+
+	setStatPalette(ctx.GlobalString("colors"))
+
+	targetURLs, err := args2URLs(ctx.Args())
+	fatalIf(err.Trace(ctx.Args()...), "One or more unknown URL types passed.")
+
+	for _, targetURL := range targetURLs {
+		if versionID := ctx.String("version-id"); versionID != "" {
+			targetURL = versionURL(targetURL, versionID)
+		}
+		msg, err := doStat(targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to stat target ‘"+targetURL+"’.")
+		Prints("%s\n", msg)
+	}
+}
+
+// doStat - stat a single URL, a version is selected with a ‘?versionId=’ suffix
+func doStat(targetURL string) (StatMessage, *probe.Error) {
+	clnt, content, err := url2Stat(targetURL)
+	if err != nil {
+		return StatMessage{}, err.Trace(targetURL)
+	}
+	msg := StatMessage{
+		Name:         strings.SplitN(targetURL, "?", 2)[0],
+		Time:         content.Time.Local(),
+		Size:         content.Size,
+		Filetype:     "file",
+		VersionID:    content.VersionID,
+		StorageClass: content.StorageClass,
+	}
+	if content.Type.IsDir() {
+		msg.Filetype = "folder"
+	}
+	// tags are informational, objects whose tags can not be read are displayed without them
+	if clnt.URL().Type == client.Object && content.Type.IsRegular() {
+		if tags, err := clnt.GetObjectTags(); err == nil && len(tags) > 0 {
+			msg.Tags = tags
+		}
+	}
+	return msg, nil
+}
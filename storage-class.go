@@ -0,0 +1,59 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+/// storage class - related internal functions
+
+// storageClasses accepted by S3 for uploaded objects
+var storageClasses = []string{"STANDARD", "REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA"}
+
+// parseStorageClass - storage classes are case insensitive on the command line
+func parseStorageClass(storageClassStr string) (string, *probe.Error) {
+	storageClass := strings.ToUpper(storageClassStr)
+	for _, class := range storageClasses {
+		if storageClass == class {
+			return storageClass, nil
+		}
+	}
+	return "", errInvalidStorageClass(storageClassStr).Trace(storageClassStr)
+}
+
+// checkStorageClassSyntax - storage classes need signature V4, filesystem targets ignore them
+func checkStorageClassSyntax(storageClassStr string, targetURLs ...string) {
+	if storageClassStr == "" {
+		return
+	}
+	_, err := parseStorageClass(storageClassStr)
+	fatalIf(err.Trace(storageClassStr), "Unable to parse storage class ‘"+storageClassStr+"’.")
+	for _, targetURL := range targetURLs {
+		if client.NewURL(targetURL).Type != client.Object {
+			continue
+		}
+		hostCfg, err := getHostConfig(targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to read host configuration for ‘"+targetURL+"’.")
+		if hostCfg.API == "S3v2" {
+			fatalIf(errInvalidArgument().Trace(targetURL), "Storage classes are not supported by S3v2 hosts.")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseStorageClass(c *C) {
+	storageClass, perr := parseStorageClass("reduced_redundancy")
+	c.Assert(perr, IsNil)
+	c.Assert(storageClass, Equals, "REDUCED_REDUNDANCY")
+
+	for _, invalid := range []string{"", "GLACIER", "standard-ia"} {
+		_, perr = parseStorageClass(invalid)
+		c.Assert(perr, Not(IsNil), Commentf("%s", invalid))
+	}
+}
+
+func (s *TestSuite) TestStorageClassCopy(c *C) {
+	root := c.MkDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		perr := putTarget(filepath.Join(root, name), "", 0, bytes.NewReader([]byte(name)))
+		c.Assert(perr, IsNil)
+	}
+	s3v4Server.MakeBucket("class-copy", "private")
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "cp", "--storage-class", "standard_ia", root + string(os.PathSeparator) + "...", s3v4Server.URL + "/class-copy/"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		storageClass, ok := s3v4Server.ObjectStorageClass("class-copy", name)
+		c.Assert(ok, Equals, true)
+		c.Assert(storageClass, Equals, "STANDARD_IA")
+	}
+
+	// storage classes show up in stat and listings
+	msg, perr := doStat(s3v4Server.URL + "/class-copy/a.txt")
+	c.Assert(perr, IsNil)
+	c.Assert(msg.StorageClass, Equals, "STANDARD_IA")
+
+	clnt, perr := url2ListClient(s3v4Server.URL + "/class-copy/")
+	c.Assert(perr, IsNil)
+	for content := range clnt.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(parseContent(content.Content).StorageClass, Equals, "STANDARD_IA")
+	}
+
+	// filesystem targets ignore storage classes
+	perr = putTarget(filepath.Join(root, "c.txt"), "STANDARD_IA", 0, bytes.NewReader([]byte("c.txt")))
+	c.Assert(perr, IsNil)
+}
+
+func (s *TestSuite) TestStorageClassContext(c *C) {
+	root := c.MkDir()
+	perr := putTarget(filepath.Join(root, "a.txt"), "", 0, bytes.NewReader([]byte("a.txt")))
+	c.Assert(perr, IsNil)
+	s3v2Server.MakeBucket("class-context", "private")
+
+	for _, storageClass := range []string{"GLACIER", "STANDARD"} {
+		console.IsExited = false
+		err := app.Run([]string{os.Args[0], "cp", "--storage-class", storageClass, filepath.Join(root, "a.txt"), s3v2Server.URL + "/class-context/a.txt"})
+		c.Assert(err, IsNil)
+		c.Assert(console.IsExited, Equals, true)
+	}
+	console.IsExited = false
+}
@@ -0,0 +1,213 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Manage object tags.
+var tagCmd = cli.Command{
+	Name:   "tag",
+	Usage:  "Set, get or remove tags of objects.",
+	Action: mainTag,
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} set TARGET TAGS
+   mc {{.Name}} get TARGET [TARGET ...]
+   mc {{.Name}} rm TARGET [TARGET ...]
+
+   TAGS are ‘key=value’ pairs separated by ‘&’, setting tags replaces all tags of an object. A TARGET
+   ending with ‘...’ applies to every object below its prefix.
+
+EXAMPLES:
+   1. Tag an object on Amazon S3 cloud storage.
+      $ mc {{.Name}} set https://s3.amazonaws.com/backup/2015-May.tar.gz "team=storage&retention=30d"
+
+   2. Tag every object below a prefix on Minio cloud storage.
+      $ mc {{.Name}} set https://play.minio.io:9000/mybucket/logs/... "team=ops"
+
+   3. Display tags of all objects in a bucket.
+      $ mc {{.Name}} get s3/backup...
+
+   4. Remove tags of an object.
+      $ mc {{.Name}} rm s3/backup/2015-May.tar.gz
+`,
+}
+
+// TagMessage is container for tag command success and failure messages
+type TagMessage struct {
+	Operation string            `json:"operation"`
+	Status    string            `json:"status"`
+	URL       string            `json:"url"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// String colorized tag message
+func (t TagMessage) String() string {
+	switch {
+	case t.Status == "dry-run" && t.Operation == "set":
+		return console.Colorize("Tag", "Tags would be set for ‘"+t.URL+"’ (dry run)")
+	case t.Status == "dry-run" && t.Operation == "rm":
+		return console.Colorize("Tag", "Tags would be removed for ‘"+t.URL+"’ (dry run)")
+	case t.Operation == "set":
+		return console.Colorize("Tag", "Tags set successfully for ‘"+t.URL+"’")
+	case t.Operation == "rm":
+		return console.Colorize("Tag", "Tags removed successfully for ‘"+t.URL+"’")
+	case t.Operation == "get" && len(t.Tags) == 0:
+		return console.Colorize("Tag", "No tags for ‘"+t.URL+"’")
+	case t.Operation == "get":
+		return console.Colorize("Tag", t.URL+" => "+tagsString(t.Tags))
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified tag message
+func (t TagMessage) JSON() string {
+	tagJSONBytes, err := json.Marshal(t)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(tagJSONBytes)
+}
+
+func checkTagSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) < 2 || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code
+	}
+	targets := ctx.Args().Tail()
+	switch ctx.Args().First() {
+	case "set":
+		if len(targets) != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code
+		}
+		_, err := parseTags(targets.Get(1))
+		fatalIf(err.Trace(targets.Get(1)), "Unable to parse tags ‘"+targets.Get(1)+"’.")
+		targets = targets[:1]
+	case "get", "rm":
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "tag", 1) // last argument is exit code
+	}
+	for _, arg := range targets {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
+func setTagPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Tag": color.New(color.FgGreen, color.Bold),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Tag": color.New(color.FgWhite, color.Bold),
+		})
+		return
+	}
+	/// Add more styles here
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainTag(ctx *cli.Context) {
+	checkTagSyntax(ctx)
+
+	setTagPalette(ctx.GlobalString("colors"))
+
+	config := mustGetMcConfig()
+
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	operation := ctx.Args().First()
+	args := ctx.Args().Tail()
+	if operation == "set" {
+		args = args[:1]
+	}
+	for _, arg := range args {
+		targetURL := getAliasURL(arg, config.Aliases)
+		if client.NewURL(targetURL).Type != client.Object {
+			fatalIf(errInvalidArgument().Trace(targetURL), "Tags are only supported for objects on cloud storage.")
+		}
+		switch operation {
+		case "set":
+			tags, _ := parseTags(ctx.Args().Get(2))
+			err := doTagURL(targetURL, func(objectURL string) *probe.Error {
+				if err := setTags(objectURL, tags); err != nil {
+					return err.Trace(objectURL)
+				}
+				Prints("%s\n", TagMessage{
+					Operation: operation,
+					Status:    status,
+					URL:       objectURL,
+					Tags:      tags,
+				})
+				return nil
+			})
+			fatalIf(err.Trace(targetURL), "Unable to set tags for ‘"+targetURL+"’.")
+		case "rm":
+			err := doTagURL(targetURL, func(objectURL string) *probe.Error {
+				if err := setTags(objectURL, nil); err != nil {
+					return err.Trace(objectURL)
+				}
+				Prints("%s\n", TagMessage{
+					Operation: operation,
+					Status:    status,
+					URL:       objectURL,
+				})
+				return nil
+			})
+			fatalIf(err.Trace(targetURL), "Unable to remove tags for ‘"+targetURL+"’.")
+		case "get":
+			err := doTagURL(targetURL, func(objectURL string) *probe.Error {
+				tags, err := getTags(objectURL)
+				if err != nil {
+					return err.Trace(objectURL)
+				}
+				Prints("%s\n", TagMessage{
+					Operation: operation,
+					Status:    "success",
+					URL:       objectURL,
+					Tags:      tags,
+				})
+				return nil
+			})
+			fatalIf(err.Trace(targetURL), "Unable to get tags for ‘"+targetURL+"’.")
+		}
+	}
+}
+
+// doTagURL - call tagFunc for a single object, or for every object below a recursive URL
+func doTagURL(targetURL string, tagFunc func(objectURL string) *probe.Error) *probe.Error {
+	if !isURLRecursive(targetURL) {
+		return tagFunc(targetURL).Trace(targetURL)
+	}
+	return listRecursive(targetURL, false, tagFunc).Trace(targetURL)
+}
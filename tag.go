@@ -0,0 +1,107 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+/// tag - related internal functions
+
+// limits on object tags enforced by S3
+const (
+	maxObjectTags     = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// parseTags - parse tags of the form ‘key=value&key2=value2’, keys have to be unique
+func parseTags(tagsStr string) (map[string]string, *probe.Error) {
+	values, e := url.ParseQuery(tagsStr)
+	if e != nil {
+		return nil, errInvalidTags(tagsStr).Trace(tagsStr)
+	}
+	if len(values) == 0 || len(values) > maxObjectTags {
+		return nil, errInvalidTags(tagsStr).Trace(tagsStr)
+	}
+	tags := make(map[string]string)
+	for key, value := range values {
+		if key == "" || len(value) != 1 {
+			return nil, errInvalidTags(tagsStr).Trace(tagsStr)
+		}
+		if utf8.RuneCountInString(key) > maxTagKeyLength || utf8.RuneCountInString(value[0]) > maxTagValueLength {
+			return nil, errInvalidTags(tagsStr).Trace(tagsStr)
+		}
+		tags[key] = value[0]
+	}
+	return tags, nil
+}
+
+// checkTagsSyntax - objects can only be tagged on cloud storage
+func checkTagsSyntax(tagsStr string, targetURLs ...string) {
+	if tagsStr == "" {
+		return
+	}
+	_, err := parseTags(tagsStr)
+	fatalIf(err.Trace(tagsStr), "Unable to parse tags ‘"+tagsStr+"’.")
+	for _, targetURL := range targetURLs {
+		if client.NewURL(targetURL).Type != client.Object {
+			fatalIf(errInvalidArgument().Trace(targetURL), "Tags are only supported for objects on cloud storage.")
+		}
+	}
+}
+
+// tagsString - format tags as ‘key=value&key2=value2’, sorted by key
+func tagsString(tags map[string]string) string {
+	values := make(url.Values)
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+// setTags - replace the tags of an object, no tags removes them
+func setTags(targetURL string, tags map[string]string) *probe.Error {
+	if globalDryRunFlag {
+		return nil
+	}
+	clnt, err := url2Client(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	if len(tags) == 0 {
+		return clnt.RemoveObjectTags().Trace(targetURL)
+	}
+	return clnt.SetObjectTags(tags).Trace(targetURL)
+}
+
+// getTags - get the tags of an object
+func getTags(targetURL string) (map[string]string, *probe.Error) {
+	clnt, err := url2Client(targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	tags, err := clnt.GetObjectTags()
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+	return tags, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestParseTags(c *C) {
+	tags, perr := parseTags("team=storage&retention=30d")
+	c.Assert(perr, IsNil)
+	c.Assert(tags, DeepEquals, map[string]string{"team": "storage", "retention": "30d"})
+	c.Assert(tagsString(tags), Equals, "retention=30d&team=storage")
+
+	tags, perr = parseTags("owner=Worf%20son%20of%20Mogh&empty=")
+	c.Assert(perr, IsNil)
+	c.Assert(tags["owner"], Equals, "Worf son of Mogh")
+	c.Assert(tags["empty"], Equals, "")
+
+	var tooMany []string
+	for i := 0; i < 11; i++ {
+		tooMany = append(tooMany, "key"+strconv.Itoa(i)+"=value")
+	}
+	for _, invalid := range []string{"", "a=1&a=2", "=value", "%zz=1", strings.Join(tooMany, "&"),
+		"key=" + strings.Repeat("v", 257), strings.Repeat("k", 129) + "=value"} {
+		_, perr = parseTags(invalid)
+		c.Assert(perr, Not(IsNil), Commentf("%s", invalid))
+	}
+}
+
+func (s *TestSuite) TestTagServer(c *C) {
+	s3v4Server.MakeBucket("tag-bucket", "private")
+	for _, object := range []string{"logs/a.log", "logs/b.log", "data/c.dat"} {
+		c.Assert(s3v4Server.PutObject("tag-bucket", object, []byte(object)), Equals, true)
+	}
+
+	tags, _ := parseTags("team=ops")
+	var tagged []string
+	perr := doTagURL(s3v4Server.URL+"/tag-bucket/logs/...", func(objectURL string) *probe.Error {
+		tagged = append(tagged, objectURL)
+		return setTags(objectURL, tags)
+	})
+	c.Assert(perr, IsNil)
+	c.Assert(tagged, DeepEquals, []string{s3v4Server.URL + "/tag-bucket/logs/a.log", s3v4Server.URL + "/tag-bucket/logs/b.log"})
+	serverTags, ok := s3v4Server.ObjectTags("tag-bucket", "logs/b.log")
+	c.Assert(ok, Equals, true)
+	c.Assert(serverTags, DeepEquals, tags)
+	serverTags, _ = s3v4Server.ObjectTags("tag-bucket", "data/c.dat")
+	c.Assert(serverTags, HasLen, 0)
+
+	// tags show up in stat and JSON listings
+	msg, perr := doStat(s3v4Server.URL + "/tag-bucket/logs/a.log")
+	c.Assert(perr, IsNil)
+	c.Assert(msg.Tags, DeepEquals, tags)
+
+	globalJSONFlag = true
+	clnt, perr := url2ListClient(s3v4Server.URL + "/tag-bucket/logs/")
+	globalJSONFlag = false
+	c.Assert(perr, IsNil)
+	for content := range clnt.List(true) {
+		c.Assert(content.Err, IsNil)
+		c.Assert(parseContent(content.Content).Tags, DeepEquals, tags)
+	}
+
+	perr = setTags(s3v4Server.URL+"/tag-bucket/logs/a.log", nil)
+	c.Assert(perr, IsNil)
+	tags, perr = getTags(s3v4Server.URL + "/tag-bucket/logs/a.log")
+	c.Assert(perr, IsNil)
+	c.Assert(tags, HasLen, 0)
+
+	// filesystem does not support tags
+	_, perr = getTags(filepath.Join(c.MkDir(), "file"))
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestTagCopy(c *C) {
+	root := c.MkDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		perr := putTarget(filepath.Join(root, name), "", 0, bytes.NewReader([]byte(name)))
+		c.Assert(perr, IsNil)
+	}
+	s3v4Server.MakeBucket("tag-copy", "private")
+
+	console.IsExited = false
+	err := app.Run([]string{os.Args[0], "cp", "--tags", "team=storage&retention=30d", root + string(os.PathSeparator) + "...", s3v4Server.URL + "/tag-copy/"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		serverTags, ok := s3v4Server.ObjectTags("tag-copy", name)
+		c.Assert(ok, Equals, true)
+		c.Assert(serverTags, DeepEquals, map[string]string{"team": "storage", "retention": "30d"})
+	}
+}
+
+func (s *TestSuite) TestTagContext(c *C) {
+	console.IsExited = false
+
+	s3v4Server.MakeBucket("tag-context", "private")
+	c.Assert(s3v4Server.PutObject("tag-context", "object", []byte("object")), Equals, true)
+
+	err := app.Run([]string{os.Args[0], "tag", "set", s3v4Server.URL + "/tag-context/object", "team=ops"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "tag", "get", s3v4Server.URL + "/tag-context..."})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "tag", "rm", s3v4Server.URL + "/tag-context/object"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	serverTags, _ := s3v4Server.ObjectTags("tag-context", "object")
+	c.Assert(serverTags, HasLen, 0)
+
+	err = app.Run([]string{os.Args[0], "tag", "set", s3v4Server.URL + "/tag-context/object", "a=1&a=2"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}
@@ -18,6 +18,8 @@ package main
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/minio/minio/pkg/probe"
 )
@@ -65,4 +67,28 @@ var (
 	errNotAnObject = func(URL string) *probe.Error {
 		return probe.NewError(errors.New("‘" + URL + "’ is not an object.")).Untrace()
 	}
+
+	errNotABucket = func(URL string) *probe.Error {
+		return probe.NewError(errors.New("‘" + URL + "’ is not a bucket.")).Untrace()
+	}
+
+	errInvalidARN = func(arn string) *probe.Error {
+		return probe.NewError(errors.New("Invalid ARN ‘" + arn + "’, expected ‘arn:partition:sqs|sns|lambda:region:account-id:resource’.")).Untrace()
+	}
+
+	errInvalidTags = func(tags string) *probe.Error {
+		return probe.NewError(errors.New("Invalid tags ‘" + tags + "’, expected up to 10 unique ‘key=value’ pairs separated by ‘&’.")).Untrace()
+	}
+
+	errSharedURLNotFound = func(ID string) *probe.Error {
+		return probe.NewError(errors.New("Shared URL ‘" + ID + "’ not found, IDs are shown by ‘mc share list’.")).Untrace()
+	}
+
+	errSessionInUse = func(sid string, pid int) *probe.Error {
+		return probe.NewError(errors.New("Session ‘" + sid + "’ in use by PID " + strconv.Itoa(pid) + ".")).Untrace()
+	}
+
+	errInvalidStorageClass = func(storageClass string) *probe.Error {
+		return probe.NewError(errors.New("Invalid storage class ‘" + storageClass + "’, expected one of ‘" + strings.Join(storageClasses, "’, ‘") + "’.")).Untrace()
+	}
 )
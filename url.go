@@ -17,6 +17,7 @@
 package main
 
 import (
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -58,6 +59,24 @@ func stripRecursiveURL(urlStr string) string {
 	return urlStr
 }
 
+// versionURL - select a version of an object with a ‘?versionId=’ suffix on its URL
+func versionURL(urlStr, versionID string) string {
+	return urlStr + "?versionId=" + url.QueryEscape(versionID)
+}
+
+// checkVersionIDSyntax - a version can only be selected for a single object on cloud storage
+func checkVersionIDSyntax(sourceURLs []string, versionID string) {
+	if versionID == "" {
+		return
+	}
+	if len(sourceURLs) != 1 {
+		fatalIf(errInvalidArgument().Trace(sourceURLs...), "Option ‘--version-id’ selects the version of a single source object.")
+	}
+	if isURLRecursive(sourceURLs[0]) || client.NewURL(sourceURLs[0]).Type != client.Object {
+		fatalIf(errInvalidArgument().Trace(sourceURLs...), "Option ‘--version-id’ is only supported for objects on cloud storage.")
+	}
+}
+
 // args2URLs extracts source and target URLs from command-line args.
 func args2URLs(args []string) ([]string, *probe.Error) {
 	config, err := getMcConfig()
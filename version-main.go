@@ -19,34 +19,110 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/minio/pkg/probe"
 )
 
-// Print version.
+// Print version, manage bucket versioning.
 var versionCmd = cli.Command{
 	Name:   "version",
-	Usage:  "Print version.",
+	Usage:  "Print version, enable, suspend or display versioning of buckets.",
 	Action: mainVersion,
 	CustomHelpTemplate: `NAME:
    mc {{.Name}} - {{.Usage}}
 
 USAGE:
    mc {{.Name}}
+   mc {{.Name}} enable TARGET [TARGET ...]
+   mc {{.Name}} suspend TARGET [TARGET ...]
+   mc {{.Name}} info TARGET [TARGET ...]
 
+   Without arguments the version of mc is printed. Once enabled, versioning of a bucket can only be
+   suspended. Previous versions of an object are listed with ‘mc ls --versions’ and selected with
+   ‘--version-id’ on cat, cp and stat.
+
+EXAMPLES:
+   1. Print version of mc.
+      $ mc {{.Name}}
+
+   2. Enable versioning on a bucket on Amazon S3 cloud storage.
+      $ mc {{.Name}} enable https://s3.amazonaws.com/backup
+
+   3. Suspend versioning on a bucket on Minio cloud storage.
+      $ mc {{.Name}} suspend https://play.minio.io:9000/mybucket
+
+   4. Display versioning status of buckets on Amazon S3 cloud storage.
+      $ mc {{.Name}} info s3/backup s3/andoria
 `,
 }
 
+// VersioningMessage is container for bucket versioning success and failure messages
+type VersioningMessage struct {
+	Operation  string `json:"operation"`
+	Status     string `json:"status"`
+	URL        string `json:"url"`
+	Versioning string `json:"versioning"`
+}
+
+// String colorized versioning message
+func (v VersioningMessage) String() string {
+	switch {
+	case v.Status == "dry-run" && v.Operation == "enable":
+		return console.Colorize("Versioning", "Versioning would be enabled for ‘"+v.URL+"’ (dry run)")
+	case v.Status == "dry-run" && v.Operation == "suspend":
+		return console.Colorize("Versioning", "Versioning would be suspended for ‘"+v.URL+"’ (dry run)")
+	case v.Operation == "enable":
+		return console.Colorize("Versioning", "Versioning enabled successfully for ‘"+v.URL+"’")
+	case v.Operation == "suspend":
+		return console.Colorize("Versioning", "Versioning suspended successfully for ‘"+v.URL+"’")
+	case v.Operation == "info":
+		return console.Colorize("Versioning", v.URL+" => "+strings.ToLower(v.Versioning))
+	}
+	// nothing to print
+	return ""
+}
+
+// JSON jsonified versioning message
+func (v VersioningMessage) JSON() string {
+	versioningJSONBytes, err := json.Marshal(v)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(versioningJSONBytes)
+}
+
+func checkVersionSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		return
+	}
+	switch ctx.Args().First() {
+	case "enable", "suspend", "info":
+		if len(ctx.Args()) < 2 {
+			cli.ShowCommandHelpAndExit(ctx, "version", 1) // last argument is exit code
+		}
+	default:
+		cli.ShowCommandHelpAndExit(ctx, "version", 1) // last argument is exit code
+	}
+	for _, arg := range ctx.Args().Tail() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+		}
+	}
+}
+
 func setVersionPalette(style string) {
 	console.SetCustomPalette(map[string]*color.Color{
-		"Version": color.New(color.FgGreen, color.Bold),
+		"Version":    color.New(color.FgGreen, color.Bold),
+		"Versioning": color.New(color.FgGreen, color.Bold),
 	})
 	if style == "light" {
 		console.SetCustomPalette(map[string]*color.Color{
-			"Version": color.New(color.FgWhite, color.Bold),
+			"Version":    color.New(color.FgWhite, color.Bold),
+			"Versioning": color.New(color.FgWhite, color.Bold),
 		})
 		return
 	}
@@ -58,12 +134,15 @@ func setVersionPalette(style string) {
 }
 
 func mainVersion(ctx *cli.Context) {
-	if ctx.Args().First() == "help" {
-		cli.ShowCommandHelpAndExit(ctx, "version", 1) // last argument is exit code
-	}
+	checkVersionSyntax(ctx)
 
 	setVersionPalette(ctx.GlobalString("colors"))
 
+	if ctx.Args().Present() {
+		mainVersioning(ctx)
+		return
+	}
+
 	if globalJSONFlag {
 		tB, e := json.Marshal(
 			struct {
@@ -89,3 +168,90 @@ func mainVersion(ctx *cli.Context) {
 	msg += console.Colorize("Version", fmt.Sprintf("Release-Tag: %s", mcReleaseTag))
 	console.Println(msg)
 }
+
+// mainVersioning - enable, suspend or display versioning of buckets
+func mainVersioning(ctx *cli.Context) {
+	config := mustGetMcConfig()
+
+	status := "success"
+	if globalDryRunFlag {
+		status = "dry-run"
+	}
+	operation := ctx.Args().First()
+	for _, arg := range ctx.Args().Tail() {
+		targetURL := getAliasURL(arg, config.Aliases)
+		if client.NewURL(targetURL).Type != client.Object {
+			fatalIf(errInvalidArgument().Trace(targetURL), "Versioning is only supported for buckets on cloud storage.")
+		}
+		switch operation {
+		case "enable", "suspend":
+			fatalIf(doSetVersioning(targetURL, operation).Trace(targetURL), "Unable to "+operation+" versioning for ‘"+targetURL+"’.")
+
+			Prints("%s\n", VersioningMessage{
+				Operation: operation,
+				Status:    status,
+				URL:       targetURL,
+			})
+		case "info":
+			versioning, err := doGetVersioning(targetURL)
+			fatalIf(err.Trace(targetURL), "Unable to get versioning for ‘"+targetURL+"’.")
+
+			Prints("%s\n", VersioningMessage{
+				Operation:  operation,
+				Status:     "success",
+				URL:        targetURL,
+				Versioning: versioning,
+			})
+		}
+	}
+}
+
+// versioningBucketURL - versioning applies to whole buckets, targets below a bucket are refused
+func versioningBucketURL(targetURL string) (string, *probe.Error) {
+	bucketURL, _, prefix := url2BucketAndPrefix(targetURL)
+	if prefix != "" {
+		return "", errNotABucket(targetURL).Trace(targetURL)
+	}
+	return bucketURL, nil
+}
+
+// doSetVersioning - enable or suspend versioning on bucket targetURL
+func doSetVersioning(targetURL, operation string) *probe.Error {
+	bucketURL, err := versioningBucketURL(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return err.Trace(bucketURL)
+	}
+	if globalDryRunFlag {
+		return nil
+	}
+	status := "Enabled"
+	if operation == "suspend" {
+		status = "Suspended"
+	}
+	return clnt.SetBucketVersioning(status).Trace(bucketURL)
+}
+
+// doGetVersioning - versioning status of bucket targetURL, buckets which never had
+// versioning enabled report "Unversioned"
+func doGetVersioning(targetURL string) (string, *probe.Error) {
+	bucketURL, err := versioningBucketURL(targetURL)
+	if err != nil {
+		return "", err.Trace(targetURL)
+	}
+	clnt, err := url2Client(bucketURL)
+	if err != nil {
+		return "", err.Trace(bucketURL)
+	}
+	status, err := clnt.GetBucketVersioning()
+	if err != nil {
+		return "", err.Trace(bucketURL)
+	}
+	if status == "" {
+		return "Unversioned", nil
+	}
+	return status, nil
+}
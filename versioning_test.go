@@ -0,0 +1,146 @@
+/*
+ * Minio Client (C) 2014, 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestSuite) TestVersioningServer(c *C) {
+	s3v4Server.MakeBucket("versioning-bucket", "private")
+
+	status, perr := doGetVersioning(s3v4Server.URL + "/versioning-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(status, Equals, "Unversioned")
+
+	globalDryRunFlag = true
+	perr = doSetVersioning(s3v4Server.URL+"/versioning-bucket", "enable")
+	globalDryRunFlag = false
+	c.Assert(perr, IsNil)
+	status, perr = doGetVersioning(s3v4Server.URL + "/versioning-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(status, Equals, "Unversioned")
+
+	// versioning is never enabled for more than the given target
+	perr = doSetVersioning(s3v4Server.URL+"/versioning-bucket/some/prefix", "enable")
+	c.Assert(perr, Not(IsNil))
+	status, perr = doGetVersioning(s3v4Server.URL + "/versioning-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(status, Equals, "Unversioned")
+	_, perr = doGetVersioning(s3v4Server.URL + "/versioning-bucket/some/prefix")
+	c.Assert(perr, Not(IsNil))
+
+	perr = doSetVersioning(s3v4Server.URL+"/versioning-bucket/", "enable")
+	c.Assert(perr, IsNil)
+	status, perr = doGetVersioning(s3v4Server.URL + "/versioning-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(status, Equals, "Enabled")
+
+	c.Assert(s3v4Server.PutObject("versioning-bucket", "config.json", []byte("version one")), Equals, true)
+	c.Assert(s3v4Server.PutObject("versioning-bucket", "config.json", []byte("version two")), Equals, true)
+	c.Assert(s3v4Server.VersionCount("versioning-bucket", "config.json"), Equals, 2)
+
+	clnt, perr := url2Client(s3v4Server.URL + "/versioning-bucket/")
+	c.Assert(perr, IsNil)
+	var versions []ContentMessage
+	for contentCh := range clnt.ListVersions(true) {
+		c.Assert(contentCh.Err, IsNil)
+		versions = append(versions, parseContent(contentCh.Content))
+	}
+	c.Assert(versions, HasLen, 2)
+	c.Assert(versions[0].IsLatest, Equals, true)
+	c.Assert(versions[1].IsLatest, Equals, false)
+	c.Assert(versions[1].VersionID, Not(Equals), "")
+
+	// select the previous version
+	msg, perr := doStat(versionURL(s3v4Server.URL+"/versioning-bucket/config.json", versions[1].VersionID))
+	c.Assert(perr, IsNil)
+	c.Assert(msg.Size, Equals, int64(len("version one")))
+	c.Assert(msg.VersionID, Equals, versions[1].VersionID)
+	c.Assert(msg.Name, Equals, s3v4Server.URL+"/versioning-bucket/config.json")
+
+	perr = doSetVersioning(s3v4Server.URL+"/versioning-bucket", "suspend")
+	c.Assert(perr, IsNil)
+	status, perr = doGetVersioning(s3v4Server.URL + "/versioning-bucket")
+	c.Assert(perr, IsNil)
+	c.Assert(status, Equals, "Suspended")
+
+	// signature V2 and filesystem targets do not support versioning
+	s3v2Server.MakeBucket("versioning-bucket", "private")
+	perr = doSetVersioning(s3v2Server.URL+"/versioning-bucket", "enable")
+	c.Assert(perr, Not(IsNil))
+	_, perr = doGetVersioning(c.MkDir())
+	c.Assert(perr, Not(IsNil))
+	_, perr = url2Client(s3v2Server.URL + "/versioning-bucket/config.json?versionId=1")
+	c.Assert(perr, Not(IsNil))
+}
+
+func (s *TestSuite) TestVersioningContext(c *C) {
+	console.IsExited = false
+
+	s3v4Server.MakeBucket("versioning-context", "private")
+	err := app.Run([]string{os.Args[0], "version", "enable", s3v4Server.URL + "/versioning-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "version", "info", s3v4Server.URL + "/versioning-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	c.Assert(s3v4Server.PutObject("versioning-context", "object", []byte("old contents")), Equals, true)
+	c.Assert(s3v4Server.PutObject("versioning-context", "object", []byte("new contents")), Equals, true)
+
+	err = app.Run([]string{os.Args[0], "ls", "--versions", s3v4Server.URL + "/versioning-context"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	clnt, perr := url2Client(s3v4Server.URL + "/versioning-context/")
+	c.Assert(perr, IsNil)
+	var versionID string
+	for contentCh := range clnt.ListVersions(false) {
+		c.Assert(contentCh.Err, IsNil)
+		if !contentCh.Content.IsLatest {
+			versionID = contentCh.Content.VersionID
+		}
+	}
+	c.Assert(versionID, Not(Equals), "")
+
+	// restore the previous version into a local folder
+	root := c.MkDir()
+	err = app.Run([]string{os.Args[0], "cp", "--version-id", versionID, s3v4Server.URL + "/versioning-context/object", filepath.Join(root, "object")})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+	data, e := ioutil.ReadFile(filepath.Join(root, "object"))
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "old contents")
+
+	err = app.Run([]string{os.Args[0], "stat", s3v4Server.URL + "/versioning-context/object", "--version-id", versionID})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, false)
+
+	err = app.Run([]string{os.Args[0], "version", "enable", root})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}
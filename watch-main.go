@@ -0,0 +1,154 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// Watch for object changes.
+var watchCmd = cli.Command{
+	Name:   "watch",
+	Usage:  "Watch for objects created, removed or modified below a folder or bucket.",
+	Action: mainWatch,
+	Flags:  []cli.Flag{recursiveFlag},
+	CustomHelpTemplate: `NAME:
+   mc {{.Name}} - {{.Usage}}
+
+USAGE:
+   mc {{.Name}} [FLAGS] TARGET [SUFFIX]
+
+   Prints an event for every object created, removed or modified below TARGET until interrupted, use
+   --json for one JSON document per line. Only objects whose names end in SUFFIX are reported. The last
+   element of TARGET is a name prefix unless TARGET ends in a separator or is a bucket or a local folder.
+
+   Subfolders are only watched with --recursive or a trailing ‘...’. Local folders are watched through
+   inotify on Linux. Cloud storage and local folders on other platforms are listed every few seconds,
+   objects changed and changed back in between are not reported. {{if .Description}}
+
+DESCRIPTION:
+   {{.Description}}{{end}}
+
+FLAGS:
+   {{range .Flags}}{{.}}
+   {{end}}
+EXAMPLES:
+   1. Watch for new ".jpg" photos in all folders of a bucket on Amazon S3 cloud storage.
+      $ mc --json {{.Name}} https://s3.amazonaws.com/backup/photos/... .jpg
+
+   2. Watch objects with names starting with "2015-" in a bucket.
+      $ mc {{.Name}} https://play.minio.io:9000/mybucket/2015-
+
+   3. Watch a local folder and all its subfolders.
+      $ mc {{.Name}} --recursive /var/spool/incoming
+`,
+}
+
+// WatchMessage container for object change events
+type WatchMessage struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Size  int64     `json:"size"`
+	URL   string    `json:"url"`
+}
+
+// String colorized object change event
+func (w WatchMessage) String() string {
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", w.Time.Format(printDate)))
+	message = message + console.Colorize("Event", fmt.Sprintf("%-8s ", w.Event))
+	message = message + console.Colorize("Size", fmt.Sprintf("%6s ", humanize.IBytes(uint64(w.Size))))
+	return message + console.Colorize("File", w.URL)
+}
+
+// JSON jsonified object change event
+func (w WatchMessage) JSON() string {
+	watchJSONBytes, err := json.Marshal(w)
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+
+	return string(watchJSONBytes)
+}
+
+func checkWatchSyntax(ctx *cli.Context) {
+	if !ctx.Args().Present() || ctx.Args().First() == "help" {
+		cli.ShowCommandHelpAndExit(ctx, "watch", 1) // last argument is exit code
+	}
+	if len(ctx.Args()) > 2 {
+		cli.ShowCommandHelpAndExit(ctx, "watch", 1) // last argument is exit code
+	}
+	if strings.TrimSpace(stripRecursiveURL(ctx.Args().First())) == "" {
+		fatalIf(errInvalidArgument().Trace(), "Unable to validate empty argument.")
+	}
+}
+
+func setWatchPalette(style string) {
+	console.SetCustomPalette(map[string]*color.Color{
+		"Time":  color.New(color.FgGreen),
+		"Event": color.New(color.FgCyan, color.Bold),
+		"Size":  color.New(color.FgYellow),
+		"File":  color.New(color.FgWhite),
+	})
+	if style == "light" {
+		console.SetCustomPalette(map[string]*color.Color{
+			"Time":  color.New(color.FgWhite),
+			"Event": color.New(color.FgWhite, color.Bold),
+			"Size":  color.New(color.FgWhite, color.Bold),
+			"File":  color.New(color.FgWhite),
+		})
+		return
+	}
+	if style == "nocolor" {
+		// All coloring options exhausted, setting nocolor safely
+		console.SetNoColor()
+	}
+}
+
+func mainWatch(ctx *cli.Context) {
+	checkWatchSyntax(ctx)
+
+	setWatchPalette(ctx.GlobalString("colors"))
+
+	config := mustGetMcConfig()
+	targetURL := getAliasURL(ctx.Args().First(), config.Aliases)
+	recursive := ctx.Bool("recursive") || isURLRecursive(targetURL)
+	target := newWatchTarget(stripRecursiveURL(targetURL), ctx.Args().Get(1), recursive)
+
+	doneCh := make(chan struct{})
+	go func() {
+		<-signalTrap(os.Interrupt, os.Kill)
+		close(doneCh)
+	}()
+	err := target.doWatch(doneCh, func(event *client.Event) {
+		Prints("%s\n", WatchMessage{
+			Event: string(event.Type),
+			Time:  event.Time,
+			Size:  event.Size,
+			URL:   target.folderURL + event.Name,
+		})
+	})
+	fatalIf(err.Trace(targetURL), "Unable to watch ‘"+targetURL+"’.")
+}
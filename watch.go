@@ -0,0 +1,209 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/minio/pkg/probe"
+)
+
+// watchPollInterval - time between two listings of targets without a change stream
+var watchPollInterval = 5 * time.Second
+
+// watchTarget - folder or bucket to watch, names relative to it are filtered by prefix and suffix
+type watchTarget struct {
+	folderURL string
+	prefix    string
+	suffix    string
+	recursive bool
+	separator string
+	urlType   client.URLType
+}
+
+// newWatchTarget - a target not ending in a separator, other than a bucket or a local folder,
+// watches its parent with the last element as prefix
+func newWatchTarget(targetURL, suffix string, recursive bool) watchTarget {
+	url := client.NewURL(targetURL)
+	target := watchTarget{
+		folderURL: targetURL,
+		suffix:    suffix,
+		recursive: recursive,
+		separator: string(url.Separator),
+		urlType:   url.Type,
+	}
+	if strings.HasSuffix(targetURL, target.separator) {
+		return target
+	}
+	isFolder := false
+	if url.Type == client.Filesystem {
+		_, content, err := url2Stat(targetURL)
+		isFolder = err == nil && content.Type.IsDir()
+	} else {
+		isFolder = strings.Count(strings.Trim(url.Path, target.separator), target.separator) == 0
+	}
+	if isFolder {
+		target.folderURL = targetURL + target.separator
+		return target
+	}
+	i := strings.LastIndex(targetURL, target.separator)
+	target.folderURL, target.prefix = targetURL[:i+1], targetURL[i+1:]
+	return target
+}
+
+// matches - name relative to the watched folder passes the filters
+func (w watchTarget) matches(name string) bool {
+	if !strings.HasPrefix(name, w.prefix) || !strings.HasSuffix(name, w.suffix) {
+		return false
+	}
+	return w.recursive || !strings.Contains(name, w.separator)
+}
+
+// compareNames - order of names in listings, folders on the filesystem list their entire contents
+// before their siblings
+func (w watchTarget) compareNames(a, b string) int {
+	if w.urlType == client.Filesystem {
+		a = strings.Replace(a, w.separator, "\x00", -1)
+		b = strings.Replace(b, w.separator, "\x00", -1)
+	}
+	return strings.Compare(a, b)
+}
+
+// nextListedObject - next object of a listing, folders are skipped
+func nextListedObject(contentCh <-chan client.ContentOnChannel) (*client.Content, *probe.Error) {
+	for content := range contentCh {
+		if content.Err != nil {
+			return nil, content.Err.Trace()
+		}
+		if !content.Content.Type.IsDir() {
+			return content.Content, nil
+		}
+	}
+	return nil, nil
+}
+
+// diffSortedLists - compare two listings of the watched folder, eventFn is called for every object
+// created, removed or modified in between
+func (w watchTarget) diffSortedLists(previous, current *sortedList, eventFn func(*client.Event)) *probe.Error {
+	previousCh, currentCh := previous.List(true), current.List(true)
+	defer func() {
+		// drain both listings
+		for range previousCh {
+		}
+		for range currentCh {
+		}
+	}()
+	p, err := nextListedObject(previousCh)
+	if err != nil {
+		return err.Trace()
+	}
+	c, err := nextListedObject(currentCh)
+	if err != nil {
+		return err.Trace()
+	}
+	for p != nil || c != nil {
+		switch {
+		case c == nil || (p != nil && w.compareNames(p.Name, c.Name) < 0):
+			eventFn(&client.Event{Type: client.EventRemove, Time: time.Now().UTC(), Name: p.Name})
+			p, err = nextListedObject(previousCh)
+		case p == nil || w.compareNames(c.Name, p.Name) < 0:
+			eventFn(&client.Event{Type: client.EventCreate, Time: c.Time, Name: c.Name, Size: c.Size})
+			c, err = nextListedObject(currentCh)
+		default:
+			if p.Size != c.Size || !p.Time.Equal(c.Time) {
+				eventFn(&client.Event{Type: client.EventModify, Time: c.Time, Name: c.Name, Size: c.Size})
+			}
+			if p, err = nextListedObject(previousCh); err == nil {
+				c, err = nextListedObject(currentCh)
+			}
+		}
+		if err != nil {
+			return err.Trace()
+		}
+	}
+	return nil
+}
+
+// watchListings - report changes by comparing listings of the watched folder every watchPollInterval,
+// for targets without a change stream
+func (w watchTarget) watchListings(clnt client.Client, doneCh <-chan struct{}, eventFn func(*client.Event)) *probe.Error {
+	id := "watch-" + newRandomID(8)
+	previous := &sortedList{}
+	if err := previous.Create(clnt, id+".0"); err != nil {
+		if previous.file != nil {
+			previous.Delete()
+		}
+		return err.Trace(w.folderURL)
+	}
+	defer func() {
+		previous.Delete()
+	}()
+	for i := 1; ; i++ {
+		select {
+		case <-doneCh:
+			return nil
+		case <-time.After(watchPollInterval):
+		}
+		current := &sortedList{}
+		if err := current.Create(clnt, id+"."+strconv.Itoa(i)); err != nil {
+			if current.file != nil {
+				current.Delete()
+			}
+			return err.Trace(w.folderURL)
+		}
+		err := w.diffSortedLists(previous, current, eventFn)
+		previous.Delete()
+		previous = current
+		if err != nil {
+			return err.Trace(w.folderURL)
+		}
+		if err = previous.Rewind(); err != nil {
+			return err.Trace(w.folderURL)
+		}
+	}
+}
+
+// doWatch - report changes below the target until doneCh is closed, through inotify on linux
+// folders and by comparing listings everywhere else
+func (w watchTarget) doWatch(doneCh <-chan struct{}, eventFn func(*client.Event)) *probe.Error {
+	clnt, err := url2Client(w.folderURL)
+	if err != nil {
+		return err.Trace(w.folderURL)
+	}
+	filteredFn := func(event *client.Event) {
+		if w.matches(event.Name) {
+			eventFn(event)
+		}
+	}
+	eventCh, err := clnt.Watch(w.recursive, doneCh)
+	if err != nil {
+		if _, ok := err.ToGoError().(client.APINotImplemented); ok {
+			return w.watchListings(clnt, doneCh, filteredFn).Trace(w.folderURL)
+		}
+		return err.Trace(w.folderURL)
+	}
+	for event := range eventCh {
+		if event.Err != nil {
+			return event.Err.Trace(w.folderURL)
+		}
+		filteredFn(event.Event)
+	}
+	return nil
+}
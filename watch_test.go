@@ -0,0 +1,161 @@
+/*
+ * Minio Client (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minio/mc/pkg/client"
+	"github.com/minio/mc/pkg/console"
+	. "gopkg.in/check.v1"
+)
+
+// collectWatchEvents - run watch on target in the background, events are sent on the returned channel
+func collectWatchEvents(c *C, target watchTarget, doneCh <-chan struct{}) <-chan *client.Event {
+	eventCh := make(chan *client.Event, 100)
+	go func() {
+		defer close(eventCh)
+		err := target.doWatch(doneCh, func(event *client.Event) {
+			eventCh <- event
+		})
+		c.Check(err, IsNil)
+	}()
+	return eventCh
+}
+
+func nextWatchEvent(c *C, eventCh <-chan *client.Event) *client.Event {
+	select {
+	case event := <-eventCh:
+		c.Assert(event, NotNil)
+		return event
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for event")
+	}
+	return nil
+}
+
+func (s *TestSuite) TestWatchTarget(c *C) {
+	root := c.MkDir()
+	target := newWatchTarget(root, "", false)
+	c.Assert(target.folderURL, Equals, root+string(os.PathSeparator))
+	c.Assert(target.prefix, Equals, "")
+	target = newWatchTarget(filepath.Join(root, "2015-"), ".log", false)
+	c.Assert(target.folderURL, Equals, root+string(os.PathSeparator))
+	c.Assert(target.prefix, Equals, "2015-")
+	c.Assert(target.matches("2015-01.log"), Equals, true)
+	c.Assert(target.matches("2015-01.txt"), Equals, false)
+	c.Assert(target.matches("2016-01.log"), Equals, false)
+	c.Assert(target.matches(filepath.Join("2015-01", "a.log")), Equals, false)
+	target.recursive = true
+	c.Assert(target.matches(filepath.Join("2015-01", "a.log")), Equals, true)
+
+	target = newWatchTarget(s3v4Server.URL+"/bucket", "", false)
+	c.Assert(target.folderURL, Equals, s3v4Server.URL+"/bucket/")
+	target = newWatchTarget(s3v4Server.URL+"/bucket/photos", "", true)
+	c.Assert(target.folderURL, Equals, s3v4Server.URL+"/bucket/")
+	c.Assert(target.prefix, Equals, "photos")
+	target = newWatchTarget(s3v4Server.URL+"/bucket/photos/", "", true)
+	c.Assert(target.folderURL, Equals, s3v4Server.URL+"/bucket/photos/")
+	c.Assert(target.prefix, Equals, "")
+
+	// folders list their contents before their siblings on the filesystem only
+	c.Assert(newWatchTarget(root, "", true).compareNames(filepath.Join("a", "b"), "a.txt") < 0, Equals, true)
+	c.Assert(target.compareNames("a/b", "a.txt") > 0, Equals, true)
+}
+
+func (s *TestSuite) TestWatchServer(c *C) {
+	interval := watchPollInterval
+	watchPollInterval = 50 * time.Millisecond
+	defer func() { watchPollInterval = interval }()
+
+	s3v4Server.MakeBucket("watch-bucket", "private")
+	c.Assert(s3v4Server.PutObject("watch-bucket", "photos/old.jpg", []byte("hello")), Equals, true)
+
+	doneCh := make(chan struct{})
+	eventCh := collectWatchEvents(c, newWatchTarget(s3v4Server.URL+"/watch-bucket/photos/", ".jpg", true), doneCh)
+	time.Sleep(2 * watchPollInterval)
+
+	c.Assert(s3v4Server.PutObject("watch-bucket", "photos/2015/new.jpg", []byte("hello")), Equals, true)
+	c.Assert(s3v4Server.PutObject("watch-bucket", "photos/new.txt", []byte("hello")), Equals, true)
+	event := nextWatchEvent(c, eventCh)
+	c.Assert(event.Type, Equals, client.EventCreate)
+	c.Assert(event.Name, Equals, "2015/new.jpg")
+	c.Assert(event.Size, Equals, int64(5))
+
+	c.Assert(s3v4Server.PutObject("watch-bucket", "photos/old.jpg", []byte("hello world")), Equals, true)
+	event = nextWatchEvent(c, eventCh)
+	c.Assert(event.Type, Equals, client.EventModify)
+	c.Assert(event.Name, Equals, "old.jpg")
+	c.Assert(event.Size, Equals, int64(11))
+
+	clnt, perr := url2Client(s3v4Server.URL + "/watch-bucket/photos/old.jpg")
+	c.Assert(perr, IsNil)
+	c.Assert(clnt.Remove(), IsNil)
+	event = nextWatchEvent(c, eventCh)
+	c.Assert(event.Type, Equals, client.EventRemove)
+	c.Assert(event.Name, Equals, "old.jpg")
+
+	close(doneCh)
+	for range eventCh {
+	}
+}
+
+func (s *TestSuite) TestWatchFS(c *C) {
+	interval := watchPollInterval
+	watchPollInterval = 50 * time.Millisecond
+	defer func() { watchPollInterval = interval }()
+
+	root := c.MkDir()
+	doneCh := make(chan struct{})
+	eventCh := collectWatchEvents(c, newWatchTarget(filepath.Join(root, "new"), "", false), doneCh)
+	time.Sleep(2 * watchPollInterval)
+
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "old"), []byte("hello"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(root, "new"), []byte("hello"), 0600), IsNil)
+	event := nextWatchEvent(c, eventCh)
+	c.Assert(event.Type, Equals, client.EventCreate)
+	c.Assert(event.Name, Equals, "new")
+
+	c.Assert(os.Remove(filepath.Join(root, "new")), IsNil)
+	event = nextWatchEvent(c, eventCh)
+	c.Assert(event.Type, Equals, client.EventRemove)
+	c.Assert(event.Name, Equals, "new")
+
+	close(doneCh)
+	for range eventCh {
+	}
+}
+
+func (s *TestSuite) TestWatchContext(c *C) {
+	console.IsExited = false
+
+	err := app.Run([]string{os.Args[0], "watch", filepath.Join(c.MkDir(), "missing") + string(os.PathSeparator)})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+	err = app.Run([]string{os.Args[0], "watch", s3v4Server.URL + "/watch-missing/", ".jpg"})
+	c.Assert(err, IsNil)
+	c.Assert(console.IsExited, Equals, true)
+
+	// reset back
+	console.IsExited = false
+}